@@ -20,16 +20,24 @@ import (
 type ImageTagTransformerPlugin struct {
 	ImageTag   types.Image       `json:"imageTag,omitempty" yaml:"imageTag,omitempty"`
 	FieldSpecs []types.FieldSpec `json:"fieldSpecs,omitempty" yaml:"fieldSpecs,omitempty"`
+
+	// Strict makes an image that
+	// matches no image fields an error.
+	Strict bool `json:"strict,omitempty" yaml:"strict,omitempty"`
+
+	matched bool
 }
 
 func (p *ImageTagTransformerPlugin) Config(
 	_ *resmap.PluginHelpers, c []byte) (err error) {
 	p.ImageTag = types.Image{}
 	p.FieldSpecs = nil
+	p.Strict = false
 	return yaml.Unmarshal(c, p)
 }
 
 func (p *ImageTagTransformerPlugin) Transform(m resmap.ResMap) error {
+	p.matched = false
 	for _, r := range m.Resources() {
 		for _, path := range p.FieldSpecs {
 			if !r.OrgId().IsSelected(&path.Gvk) {
@@ -46,6 +54,9 @@ func (p *ImageTagTransformerPlugin) Transform(m resmap.ResMap) error {
 			return err
 		}
 	}
+	if p.Strict && !p.matched {
+		return fmt.Errorf("image %s matched no image fields", p.ImageTag.Name)
+	}
 	return nil
 }
 
@@ -58,6 +69,7 @@ func (p *ImageTagTransformerPlugin) mutateImage(in interface{}) (interface{}, er
 	if !isImageMatched(original, p.ImageTag.Name) {
 		return original, nil
 	}
+	p.matched = true
 	name, tag := split(original)
 	if p.ImageTag.NewName != "" {
 		name = p.ImageTag.NewName
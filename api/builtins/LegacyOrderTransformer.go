@@ -9,6 +9,8 @@ import (
 	"github.com/pkg/errors"
 	"sigs.k8s.io/kustomize/api/resmap"
 	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
 )
 
 // Sort the resources using an ordering defined in the Gvk class.
@@ -16,18 +18,24 @@ import (
 // dependencies (like Namespace, StorageClass, etc.)
 // first, and resources with a high number of dependencies
 // (like ValidatingWebhookConfiguration) last.
-type LegacyOrderTransformerPlugin struct{}
+// The kinds to put first and last may be configured
+// instead, with the fields of a types.KindOrder.
+type LegacyOrderTransformerPlugin struct {
+	types.KindOrder
+}
 
-// Nothing needed for configuration.
 func (p *LegacyOrderTransformerPlugin) Config(
-	_ *resmap.PluginHelpers, _ []byte) (err error) {
-	return nil
+	_ *resmap.PluginHelpers, c []byte) (err error) {
+	p.KindOrder = types.KindOrder{}
+	return yaml.Unmarshal(c, p)
 }
 
 func (p *LegacyOrderTransformerPlugin) Transform(m resmap.ResMap) (err error) {
 	resources := make([]*resource.Resource, m.Size())
 	ids := m.AllIds()
-	sort.Sort(resmap.IdSlice(ids))
+	sort.SliceStable(ids, func(i, j int) bool {
+		return p.KindOrder.IsLessThan(ids[i], ids[j])
+	})
 	for i, id := range ids {
 		resources[i], err = m.GetByCurrentId(id)
 		if err != nil {
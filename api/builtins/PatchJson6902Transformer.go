@@ -12,6 +12,7 @@ import (
 	"sigs.k8s.io/kustomize/api/ifc"
 	"sigs.k8s.io/kustomize/api/resid"
 	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kyaml/filtersutil"
 	"sigs.k8s.io/yaml"
@@ -20,9 +21,13 @@ import (
 type PatchJson6902TransformerPlugin struct {
 	ldr          ifc.Loader
 	decodedPatch jsonpatch.Patch
-	Target       types.PatchTarget `json:"target,omitempty" yaml:"target,omitempty"`
-	Path         string            `json:"path,omitempty" yaml:"path,omitempty"`
-	JsonOp       string            `json:"jsonOp,omitempty" yaml:"jsonOp,omitempty"`
+	// The origins of the patch file and of its
+	// operations, for errors; nil for inline patches.
+	origin    *resource.Origin
+	opOrigins []*resource.Origin
+	Target    types.PatchTarget `json:"target,omitempty" yaml:"target,omitempty"`
+	Path      string            `json:"path,omitempty" yaml:"path,omitempty"`
+	JsonOp    string            `json:"jsonOp,omitempty" yaml:"jsonOp,omitempty"`
 
 	YAMLSupport bool `json:"yamlSupport,omitempty" yaml:"yamlSupport,omitempty"`
 }
@@ -48,6 +53,8 @@ func (p *PatchJson6902TransformerPlugin) Config(
 		if err != nil {
 			return err
 		}
+		p.origin = &resource.Origin{Path: p.Path, Root: p.ldr.Root()}
+		p.opOrigins = resource.ListItemOrigins(rawOp, p.Path, p.ldr.Root())
 		p.JsonOp = string(rawOp)
 		if p.JsonOp == "" {
 			return fmt.Errorf("patch file '%s' empty seems to be empty", p.Path)
@@ -85,24 +92,37 @@ func (p *PatchJson6902TransformerPlugin) Transform(m resmap.ResMap) error {
 	)
 	obj, err := m.GetById(id)
 	if err != nil {
-		return err
+		return p.origin.WrapError(err)
 	}
 	if !p.YAMLSupport {
 		rawObj, err := obj.MarshalJSON()
 		if err != nil {
 			return err
 		}
-		modifiedObj, err := p.decodedPatch.Apply(rawObj)
-		if err != nil {
-			return errors.Wrapf(
-				err, "failed to apply json patch '%s'", p.JsonOp)
+		// The operations are applied one by one,
+		// so that a failure has the line of its own.
+		for i, op := range p.decodedPatch {
+			rawObj, err = jsonpatch.Patch{op}.Apply(rawObj)
+			if err != nil {
+				return p.opOrigin(i).WrapError(errors.Wrapf(
+					err, "failed to apply json patch '%s'", p.JsonOp))
+			}
 		}
-		return obj.UnmarshalJSON(modifiedObj)
+		return obj.UnmarshalJSON(rawObj)
 	} else {
-		return filtersutil.ApplyToJSON(patchjson6902.Filter{
+		return p.origin.WrapError(filtersutil.ApplyToJSON(patchjson6902.Filter{
 			Patch: p.JsonOp,
-		}, obj.Kunstructured)
+		}, obj.Kunstructured))
+	}
+}
+
+// opOrigin returns the origin of the
+// operation of the patch at the given index.
+func (p *PatchJson6902TransformerPlugin) opOrigin(i int) *resource.Origin {
+	if i < len(p.opOrigins) {
+		return p.opOrigins[i]
 	}
+	return p.origin
 }
 
 func NewPatchJson6902TransformerPlugin() resmap.TransformerPlugin {
@@ -19,17 +19,25 @@ import (
 type ReplicaCountTransformerPlugin struct {
 	Replica    types.Replica     `json:"replica,omitempty" yaml:"replica,omitempty"`
 	FieldSpecs []types.FieldSpec `json:"fieldSpecs,omitempty" yaml:"fieldSpecs,omitempty"`
+
+	// Strict makes a replica that
+	// sets no field an error.
+	Strict bool `json:"strict,omitempty" yaml:"strict,omitempty"`
+
+	setField bool
 }
 
 func (p *ReplicaCountTransformerPlugin) Config(
 	_ *resmap.PluginHelpers, c []byte) (err error) {
 	p.Replica = types.Replica{}
 	p.FieldSpecs = nil
+	p.Strict = false
 	return yaml.Unmarshal(c, p)
 }
 
 func (p *ReplicaCountTransformerPlugin) Transform(m resmap.ResMap) error {
 	found := false
+	p.setField = false
 	for i, replicaSpec := range p.FieldSpecs {
 		matcher := p.createMatcher(i)
 		matchOriginal := m.GetMatchingResourcesByOriginalId(matcher)
@@ -54,6 +62,10 @@ func (p *ReplicaCountTransformerPlugin) Transform(m resmap.ResMap) error {
 		return fmt.Errorf("resource with name %s does not match a config with the following GVK %v",
 			p.Replica.Name, gvks)
 	}
+	if p.Strict && !p.setField {
+		return fmt.Errorf(
+			"replicas of resource with name %s matched no fields", p.Replica.Name)
+	}
 
 	return nil
 }
@@ -80,6 +92,7 @@ func (p *ReplicaCountTransformerPlugin) addReplicas(in interface{}) (interface{}
 	default:
 		return nil, fmt.Errorf("%#v is expected to be %T", in, m)
 	}
+	p.setField = true
 	return p.Replica.Count, nil
 }
 
@@ -0,0 +1,183 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package explain holds a record of the steps taken
+// during a build, and of how each step changed
+// the resources being built.
+package explain
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Action says what a step did to a resource.
+type Action string
+
+const (
+	Added   Action = "added"
+	Changed Action = "changed"
+	Removed Action = "removed"
+)
+
+// Trace is the ordered list of steps taken during a build.
+type Trace struct {
+	Steps []Step `json:"steps"`
+}
+
+// Step is one generator or transformer run, and its effect.
+type Step struct {
+	// Name names the generator or transformer,
+	// e.g. PrefixSuffixTransformer.
+	Name string `json:"name"`
+
+	// Root is the root of the kustomization that
+	// configured the step.
+	Root string `json:"root,omitempty"`
+
+	// Resources lists the resources the step
+	// added, changed or removed.
+	Resources []ResourceChange `json:"resources,omitempty"`
+}
+
+// ResourceChange is the effect of a step on one resource.
+type ResourceChange struct {
+	// Id identifies the resource as it was after the step,
+	// or before it if the resource was removed.
+	Id string `json:"id"`
+
+	Action Action `json:"action"`
+
+	// Fields lists the changed fields of a changed resource.
+	Fields []FieldChange `json:"fields,omitempty"`
+}
+
+// FieldChange is a change to the value of a field.
+// A missing Before means the field was added, a missing
+// After means it was removed.
+type FieldChange struct {
+	// Path is the path to the field, e.g.
+	// spec.template.spec.containers[0].image
+	Path   string      `json:"path"`
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// Diff returns the changes that take before to after, both
+// being resources in map form.  Maps are compared key by key,
+// and lists element by element.  Changes are ordered by path.
+func Diff(before, after map[string]interface{}) []FieldChange {
+	var result []FieldChange
+	diffValues("", before, after, &result)
+	return result
+}
+
+func diffValues(path string, before, after interface{}, result *[]FieldChange) {
+	if reflect.DeepEqual(before, after) {
+		return
+	}
+	bm, bIsMap := before.(map[string]interface{})
+	am, aIsMap := after.(map[string]interface{})
+	if bIsMap && aIsMap {
+		for _, k := range unionOfKeys(bm, am) {
+			diffValues(join(path, k), bm[k], am[k], result)
+		}
+		return
+	}
+	bl, bIsList := before.([]interface{})
+	al, aIsList := after.([]interface{})
+	if bIsList && aIsList {
+		for i := 0; i < len(bl) || i < len(al); i++ {
+			var b, a interface{}
+			if i < len(bl) {
+				b = bl[i]
+			}
+			if i < len(al) {
+				a = al[i]
+			}
+			diffValues(fmt.Sprintf("%s[%d]", path, i), b, a, result)
+		}
+		return
+	}
+	*result = append(*result, FieldChange{
+		Path: path, Before: before, After: after})
+}
+
+func unionOfKeys(a, b map[string]interface{}) []string {
+	var keys []string
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// WriteJSON writes the trace to w as indented JSON.
+func (t *Trace) WriteJSON(w io.Writer) error {
+	b, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+// WriteText writes the trace to w in a form meant
+// for people, e.g.
+//
+//	step 2: PrefixSuffixTransformer (root: ../base)
+//	  changed apps_v1_Deployment|~X|b-storefront
+//	    metadata.name: "storefront" -> "b-storefront"
+//
+// Field values are written as JSON.
+// Steps that changed nothing are written too,
+// so the step numbers match those of WriteJSON.
+func (t *Trace) WriteText(w io.Writer) error {
+	var b strings.Builder
+	for i, s := range t.Steps {
+		fmt.Fprintf(&b, "step %d: %s", i+1, s.Name)
+		if s.Root != "" {
+			fmt.Fprintf(&b, " (root: %s)", s.Root)
+		}
+		b.WriteString("\n")
+		if len(s.Resources) == 0 {
+			b.WriteString("  no changes\n")
+		}
+		for _, r := range s.Resources {
+			fmt.Fprintf(&b, "  %s %s\n", r.Action, r.Id)
+			for _, f := range r.Fields {
+				fmt.Fprintf(&b, "    %s: %s -> %s\n",
+					f.Path, textValue(f.Before), textValue(f.After))
+			}
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func textValue(v interface{}) string {
+	if v == nil {
+		return "<none>"
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
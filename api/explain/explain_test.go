@@ -0,0 +1,113 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package explain_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	. "sigs.k8s.io/kustomize/api/explain"
+)
+
+func TestDiff(t *testing.T) {
+	testCases := map[string]struct {
+		before   string
+		after    string
+		expected []FieldChange
+	}{
+		"same": {
+			before: `{"a": {"b": [1, 2]}}`,
+			after:  `{"a": {"b": [1, 2]}}`,
+		},
+		"changedNestedField": {
+			before: `{"a": {"b": "x", "c": "y"}}`,
+			after:  `{"a": {"b": "x", "c": "z"}}`,
+			expected: []FieldChange{
+				{Path: "a.c", Before: "y", After: "z"},
+			},
+		},
+		"addedAndRemovedFields": {
+			before: `{"a": 1, "b": 2}`,
+			after:  `{"b": 2, "c": {"d": 3}}`,
+			expected: []FieldChange{
+				{Path: "a", Before: float64(1)},
+				{Path: "c", After: map[string]interface{}{"d": float64(3)}},
+			},
+		},
+		"listElements": {
+			before: `{"l": [{"n": "x"}, {"n": "y"}]}`,
+			after:  `{"l": [{"n": "x"}, {"n": "z"}, {"n": "w"}]}`,
+			expected: []FieldChange{
+				{Path: "l[1].n", Before: "y", After: "z"},
+				{Path: "l[2]", After: map[string]interface{}{"n": "w"}},
+			},
+		},
+		"changedType": {
+			before: `{"a": [1]}`,
+			after:  `{"a": "1"}`,
+			expected: []FieldChange{
+				{Path: "a", Before: []interface{}{float64(1)}, After: "1"},
+			},
+		},
+	}
+	for n, tc := range testCases {
+		var before, after map[string]interface{}
+		if err := json.Unmarshal([]byte(tc.before), &before); err != nil {
+			t.Fatalf("%s: %v", n, err)
+		}
+		if err := json.Unmarshal([]byte(tc.after), &after); err != nil {
+			t.Fatalf("%s: %v", n, err)
+		}
+		actual := Diff(before, after)
+		if !reflect.DeepEqual(actual, tc.expected) {
+			t.Errorf("%s: expected %v, got %v", n, tc.expected, actual)
+		}
+	}
+}
+
+func TestWriteTextAndJSON(t *testing.T) {
+	trace := &Trace{Steps: []Step{
+		{
+			Name: "PrefixSuffixTransformer",
+			Root: "base",
+			Resources: []ResourceChange{{
+				Id:     "~G_v1_Service|~X|p-svc",
+				Action: Changed,
+				Fields: []FieldChange{
+					{Path: "metadata.name", Before: "svc", After: "p-svc"},
+					{Path: "spec.replicas", Before: float64(0)},
+				},
+			}},
+		},
+		{Name: "LabelTransformer"},
+	}}
+	var b bytes.Buffer
+	if err := trace.WriteText(&b); err != nil {
+		t.Fatal(err)
+	}
+	expected := `step 1: PrefixSuffixTransformer (root: base)
+  changed ~G_v1_Service|~X|p-svc
+    metadata.name: "svc" -> "p-svc"
+    spec.replicas: 0 -> <none>
+step 2: LabelTransformer
+  no changes
+`
+	if b.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, b.String())
+	}
+
+	b.Reset()
+	if err := trace.WriteJSON(&b); err != nil {
+		t.Fatal(err)
+	}
+	var actual Trace
+	if err := json.Unmarshal(b.Bytes(), &actual); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&actual, trace) {
+		t.Fatalf("expected %v, got %v", trace, &actual)
+	}
+}
@@ -0,0 +1,422 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package gitcache holds clones of remote git repos,
+// keyed by repo URL and commit, so that builds using
+// remote bases needn't fetch them again, and can
+// be done offline.
+//
+// The cache directory holds a directory per repo URL,
+// named by a hash of the URL, holding
+//   - a directory per commit, named by the commit's
+//     SHA, holding a clone of the repo at the commit,
+//   - a file per commit, named by the commit's SHA and
+//     ending in .json, describing the clone,
+//   - a refs.json file mapping the refs last fetched,
+//     e.g. branches and tags, to their commits.
+//
+// Changes to the directory of a repo are made holding a lock
+// file beside it, so that builds can share the cache, and
+// files are written whole, by renaming a temporary file.
+package gitcache
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/konfig"
+)
+
+const (
+	entrySuffix = ".json"
+	refsFile    = "refs.json"
+	tmpPrefix   = ".tmp-"
+	lockSuffix  = ".lock"
+)
+
+const (
+	// lockPoll is how often a lock held by
+	// another build is checked for release.
+	lockPoll = 10 * time.Millisecond
+
+	// staleLock is how old a lock must be to be taken as
+	// left by a build that died.  Locks are held only to
+	// update a few small files.
+	staleLock = time.Minute
+
+	// touchInterval is how stale the LastUsed time of an
+	// entry must be for Touch to update it, so that builds
+	// don't rewrite entries every time they use them.
+	touchInterval = time.Hour
+)
+
+// Entry describes a clone of a repo at a commit.
+type Entry struct {
+	// URL is the URL the repo was cloned from.
+	URL string `json:"url"`
+
+	// Commit is the SHA of the commit
+	// the clone is checked out at.
+	Commit string `json:"commit"`
+
+	// LastUsed is when a build last used the clone.
+	LastUsed time.Time `json:"lastUsed"`
+
+	// Dir holds the clone.
+	Dir string `json:"-"`
+}
+
+// Cache is a cache of git repo clones in a directory.
+type Cache struct {
+	dir string
+}
+
+// New returns a Cache in the given directory,
+// which is created as need be.
+func New(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+// DefaultDir returns the default directory of the
+// cache, in the XDG cache home directory.
+func DefaultDir() string {
+	home := os.Getenv(konfig.XdgCacheHomeEnv)
+	if home == "" {
+		home = filepath.Join(konfig.HomeDir(), konfig.XdgCacheHomeEnvDefault)
+	}
+	return filepath.Join(home, konfig.ProgramName, "git")
+}
+
+// Dir returns the directory holding the cache.
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// repoDir returns the directory holding
+// the clones of the repo at the given URL.
+func (c *Cache) repoDir(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(c.dir, fmt.Sprintf("%x", sum[:8]))
+}
+
+// Lookup returns the clone of the repo at the
+// given URL and commit, if the cache holds it.
+func (c *Cache) Lookup(url, commit string) (*Entry, bool) {
+	e, err := readEntry(filepath.Join(c.repoDir(url), commit+entrySuffix))
+	if err != nil || e.URL != url {
+		return nil, false
+	}
+	if fi, err := os.Stat(e.Dir); err != nil || !fi.IsDir() {
+		return nil, false
+	}
+	return e, true
+}
+
+// lock creates the directory of a repo, as need be, and
+// waits to take the lock on it, returning the func that
+// releases the lock.  The lock is a file beside the
+// directory, so that removing the directory keeps it.
+func (c *Cache) lock(repoDir string) (func(), error) {
+	err := os.MkdirAll(repoDir, 0700)
+	if err != nil {
+		return nil, err
+	}
+	path := repoDir + lockSuffix
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, errors.Wrapf(err, "locking %s", repoDir)
+		}
+		if fi, err := os.Stat(path); err == nil &&
+			time.Since(fi.ModTime()) > staleLock {
+			os.Remove(path)
+			continue
+		}
+		time.Sleep(lockPoll)
+	}
+}
+
+// TempDir returns a new directory in the cache, into
+// which to clone a repo before adding it to the cache.
+func (c *Cache) TempDir() (string, error) {
+	err := os.MkdirAll(c.dir, 0700)
+	if err != nil {
+		return "", err
+	}
+	return ioutil.TempDir(c.dir, tmpPrefix)
+}
+
+// Add moves the clone in the given directory,
+// made by TempDir, into the cache, as the clone
+// of the repo at the given URL and commit.  If
+// the cache holds the clone already, e.g. because
+// another build got there first, the given one is
+// removed, and the cached one is returned.
+func (c *Cache) Add(url, commit, cloneDir string) (*Entry, error) {
+	repoDir := c.repoDir(url)
+	unlock, err := c.lock(repoDir)
+	if err != nil {
+		os.RemoveAll(cloneDir)
+		return nil, err
+	}
+	defer unlock()
+	e := &Entry{
+		URL:      url,
+		Commit:   commit,
+		LastUsed: time.Now().UTC(),
+		Dir:      filepath.Join(repoDir, commit),
+	}
+	if existing, ok := c.Lookup(url, commit); ok {
+		os.RemoveAll(cloneDir)
+		return existing, nil
+	}
+	if _, err := os.Stat(e.Dir); err == nil {
+		// Clones are moved into place whole, so this one
+		// is complete; the build adding it died before
+		// writing its entry.  Builds may be using it.
+		os.RemoveAll(cloneDir)
+		return e, writeEntry(e)
+	}
+	err = os.Rename(cloneDir, e.Dir)
+	if err != nil {
+		os.RemoveAll(cloneDir)
+		return nil, errors.Wrapf(err, "adding %s to the git cache", url)
+	}
+	return e, writeEntry(e)
+}
+
+// Touch records that the clone was just used.  The
+// record is only updated if it's more than an hour old.
+func (c *Cache) Touch(e *Entry) error {
+	now := time.Now().UTC()
+	if now.Sub(e.LastUsed) < touchInterval {
+		return nil
+	}
+	unlock, err := c.lock(filepath.Dir(e.Dir))
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if _, err := os.Stat(e.Dir + entrySuffix); err != nil {
+		// Pruned meanwhile.
+		return nil
+	}
+	e.LastUsed = now
+	return writeEntry(e)
+}
+
+// SetRef records the commit that the given ref,
+// e.g. a branch or tag, of the repo is at.
+func (c *Cache) SetRef(url, ref, commit string) error {
+	repoDir := c.repoDir(url)
+	unlock, err := c.lock(repoDir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	refs, err := readRefs(repoDir)
+	if err != nil {
+		return err
+	}
+	refs[ref] = commit
+	return writeRefs(repoDir, refs)
+}
+
+// ResolveRef returns the commit that the given ref of
+// the repo was at when it was last fetched, if it was.
+func (c *Cache) ResolveRef(url, ref string) (string, bool) {
+	refs, err := readRefs(c.repoDir(url))
+	if err != nil {
+		return "", false
+	}
+	commit, ok := refs[ref]
+	return commit, ok
+}
+
+// List returns the clones in the cache,
+// sorted by URL, then by when they were last used.
+func (c *Cache) List() ([]Entry, error) {
+	paths, err := filepath.Glob(filepath.Join(c.dir, "*", "*"+entrySuffix))
+	if err != nil {
+		return nil, err
+	}
+	var result []Entry
+	for _, p := range paths {
+		if filepath.Base(p) == refsFile {
+			continue
+		}
+		e, err := readEntry(p)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *e)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].URL != result[j].URL {
+			return result[i].URL < result[j].URL
+		}
+		return result[i].LastUsed.Before(result[j].LastUsed)
+	})
+	return result, nil
+}
+
+// Prune removes the clones last used before the given
+// time, returning them, along with any leftovers of
+// interrupted clones.  A zero time removes all clones.
+func (c *Cache) Prune(before time.Time) ([]Entry, error) {
+	entries, err := c.List()
+	if err != nil {
+		return nil, err
+	}
+	var removed []Entry
+	for _, e := range entries {
+		if !before.IsZero() && !e.LastUsed.Before(before) {
+			continue
+		}
+		err = c.remove(e)
+		if err != nil {
+			return removed, err
+		}
+		removed = append(removed, e)
+	}
+	tmps, err := filepath.Glob(filepath.Join(c.dir, tmpPrefix+"*"))
+	if err != nil {
+		return removed, err
+	}
+	// Files being written by other builds are left be.
+	files, err := filepath.Glob(filepath.Join(c.dir, "*", tmpPrefix+"*"))
+	if err != nil {
+		return removed, err
+	}
+	for _, f := range files {
+		if fi, err := os.Stat(f); err == nil &&
+			time.Since(fi.ModTime()) > staleLock {
+			tmps = append(tmps, f)
+		}
+	}
+	for _, t := range tmps {
+		err = os.RemoveAll(t)
+		if err != nil {
+			return removed, err
+		}
+	}
+	return removed, nil
+}
+
+// remove removes the clone, forgetting the refs
+// at its commit, and then the directory of the
+// repo, if the clone was the last one in it.
+func (c *Cache) remove(e Entry) error {
+	repoDir := filepath.Dir(e.Dir)
+	unlock, err := c.lock(repoDir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	// The entry goes first, so that no build finds
+	// the clone while it's being removed.
+	err = os.Remove(e.Dir + entrySuffix)
+	if err != nil {
+		return err
+	}
+	err = os.RemoveAll(e.Dir)
+	if err != nil {
+		return err
+	}
+	refs, err := readRefs(repoDir)
+	if err != nil {
+		return err
+	}
+	for ref, commit := range refs {
+		if commit == e.Commit {
+			delete(refs, ref)
+		}
+	}
+	left, err := filepath.Glob(filepath.Join(repoDir, "*"+entrySuffix))
+	if err != nil {
+		return err
+	}
+	if len(left) == 0 || (len(left) == 1 && filepath.Base(left[0]) == refsFile) {
+		return os.RemoveAll(repoDir)
+	}
+	return writeRefs(repoDir, refs)
+}
+
+func readEntry(path string) (*Entry, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var e Entry
+	err = json.Unmarshal(data, &e)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading git cache entry %s", path)
+	}
+	e.Dir = strings.TrimSuffix(path, entrySuffix)
+	return &e, nil
+}
+
+func writeEntry(e *Entry) error {
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(e.Dir+entrySuffix, data)
+}
+
+func readRefs(repoDir string) (map[string]string, error) {
+	refs := make(map[string]string)
+	data, err := ioutil.ReadFile(filepath.Join(repoDir, refsFile))
+	if os.IsNotExist(err) {
+		return refs, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(data, &refs)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading git cache refs in %s", repoDir)
+	}
+	return refs, nil
+}
+
+func writeRefs(repoDir string, refs map[string]string) error {
+	data, err := json.MarshalIndent(refs, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(filepath.Join(repoDir, refsFile), data)
+}
+
+// writeFile writes the file by renaming a temporary
+// file beside it, so that readers never see a part
+// of the data.
+func writeFile(path string, data []byte) error {
+	f, err := ioutil.TempFile(filepath.Dir(path), tmpPrefix)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if errC := f.Close(); err == nil {
+		err = errC
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
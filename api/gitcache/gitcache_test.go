@@ -0,0 +1,282 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package gitcache_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	. "sigs.k8s.io/kustomize/api/gitcache"
+)
+
+const (
+	commitA = "1111111111111111111111111111111111111111"
+	commitB = "2222222222222222222222222222222222222222"
+	urlX    = "https://github.com/example/x.git"
+	urlY    = "https://github.com/example/y.git"
+)
+
+func makeCache(t *testing.T) (*Cache, func()) {
+	dir, err := ioutil.TempDir("", "kustomize-gitcache-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return New(filepath.Join(dir, "cache")), func() { os.RemoveAll(dir) }
+}
+
+// addClone adds a fake clone of the repo to the cache.
+func addClone(t *testing.T, c *Cache, url, commit string) *Entry {
+	dir, err := c.TempDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, "data"), []byte(commit), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := c.Add(url, commit, dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return e
+}
+
+func TestAddAndLookup(t *testing.T) {
+	c, cleanup := makeCache(t)
+	defer cleanup()
+	if _, ok := c.Lookup(urlX, commitA); ok {
+		t.Fatalf("expected an empty cache")
+	}
+	added := addClone(t, c, urlX, commitA)
+	e, ok := c.Lookup(urlX, commitA)
+	if !ok {
+		t.Fatalf("expected to find the clone")
+	}
+	if e.Dir != added.Dir || e.URL != urlX || e.Commit != commitA {
+		t.Fatalf("unexpected entry: %v", e)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(e.Dir, "data"))
+	if err != nil || string(data) != commitA {
+		t.Fatalf("unexpected clone content: %s, %v", data, err)
+	}
+	if _, ok := c.Lookup(urlX, commitB); ok {
+		t.Fatalf("unexpected clone of %s", commitB)
+	}
+	if _, ok := c.Lookup(urlY, commitA); ok {
+		t.Fatalf("unexpected clone of %s", urlY)
+	}
+
+	// Adding the same clone again keeps the first.
+	again := addClone(t, c, urlX, commitA)
+	if again.Dir != added.Dir {
+		t.Fatalf("expected %s, got %s", added.Dir, again.Dir)
+	}
+}
+
+func TestConcurrentAdds(t *testing.T) {
+	c, cleanup := makeCache(t)
+	defer cleanup()
+	const n = 8
+	dirs := make([]string, n)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			dir, err := c.TempDir()
+			if err == nil {
+				err = ioutil.WriteFile(
+					filepath.Join(dir, "data"), []byte(commitA), 0600)
+			}
+			if err == nil {
+				err = c.SetRef(urlX, fmt.Sprintf("v%d", i), commitA)
+			}
+			if err == nil {
+				var e *Entry
+				e, err = c.Add(urlX, commitA, dir)
+				if err == nil {
+					dirs[i] = e.Dir
+				}
+			}
+			errs[i] = err
+		}(i)
+	}
+	wg.Wait()
+	for i := 0; i < n; i++ {
+		if errs[i] != nil {
+			t.Fatalf("unexpected error: %v", errs[i])
+		}
+		if dirs[i] != dirs[0] {
+			t.Fatalf("expected %s, got %s", dirs[0], dirs[i])
+		}
+		if commit, _ := c.ResolveRef(urlX, fmt.Sprintf("v%d", i)); commit != commitA {
+			t.Fatalf("expected ref v%d at %s, got '%s'", i, commitA, commit)
+		}
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dirs[0], "data"))
+	if err != nil || string(data) != commitA {
+		t.Fatalf("unexpected clone content: %s, %v", data, err)
+	}
+	files, err := filepath.Glob(filepath.Join(c.Dir(), "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0] != filepath.Dir(dirs[0]) {
+		t.Fatalf("expected only the repo directory, got %v", files)
+	}
+}
+
+func TestAddKeepsEntrylessClone(t *testing.T) {
+	c, cleanup := makeCache(t)
+	defer cleanup()
+	added := addClone(t, c, urlX, commitA)
+	if err := os.Remove(added.Dir + ".json"); err != nil {
+		t.Fatal(err)
+	}
+	again := addClone(t, c, urlX, commitA)
+	if again.Dir != added.Dir {
+		t.Fatalf("expected %s, got %s", added.Dir, again.Dir)
+	}
+	if _, ok := c.Lookup(urlX, commitA); !ok {
+		t.Fatalf("expected to find the clone")
+	}
+	files, err := ioutil.ReadDir(added.Dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("expected the clone to be kept, got %v", files)
+	}
+}
+
+func TestTouch(t *testing.T) {
+	c, cleanup := makeCache(t)
+	defer cleanup()
+	e := addClone(t, c, urlX, commitA)
+	recent := e.LastUsed
+	if err := c.Touch(e); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !e.LastUsed.Equal(recent) {
+		t.Fatalf("expected a recent use to be kept")
+	}
+	e.LastUsed = time.Now().Add(-48 * time.Hour)
+	if err := c.Touch(e); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	found, _ := c.Lookup(urlX, commitA)
+	if time.Since(found.LastUsed) > time.Minute {
+		t.Fatalf("expected the use to be recorded, got %v", found.LastUsed)
+	}
+}
+
+func TestRefs(t *testing.T) {
+	c, cleanup := makeCache(t)
+	defer cleanup()
+	if _, ok := c.ResolveRef(urlX, "main"); ok {
+		t.Fatalf("expected no ref")
+	}
+	for _, ref := range []struct{ name, commit string }{
+		{"main", commitA},
+		{"v1", commitA},
+		{"main", commitB},
+	} {
+		if err := c.SetRef(urlX, ref.name, ref.commit); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if commit, _ := c.ResolveRef(urlX, "main"); commit != commitB {
+		t.Fatalf("expected %s, got %s", commitB, commit)
+	}
+	if commit, _ := c.ResolveRef(urlX, "v1"); commit != commitA {
+		t.Fatalf("expected %s, got %s", commitA, commit)
+	}
+	if _, ok := c.ResolveRef(urlY, "main"); ok {
+		t.Fatalf("unexpected ref of %s", urlY)
+	}
+}
+
+func TestListAndPrune(t *testing.T) {
+	c, cleanup := makeCache(t)
+	defer cleanup()
+	addClone(t, c, urlY, commitA)
+	old := addClone(t, c, urlX, commitA)
+	recent := addClone(t, c, urlX, commitB)
+	if err := c.SetRef(urlX, "v1", commitA); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SetRef(urlX, "main", commitB); err != nil {
+		t.Fatal(err)
+	}
+	old.LastUsed = time.Now().Add(-48 * time.Hour)
+	recent.LastUsed = time.Now().Add(-time.Hour)
+	for _, e := range []*Entry{old, recent} {
+		data := `{"url": "` + e.URL + `", "commit": "` + e.Commit +
+			`", "lastUsed": "` + e.LastUsed.Format(time.RFC3339) + `"}`
+		err := ioutil.WriteFile(e.Dir+".json", []byte(data), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	leftover, err := c.TempDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := c.List()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %v", entries)
+	}
+	for i, expected := range []struct{ url, commit string }{
+		{urlX, commitA}, {urlX, commitB}, {urlY, commitA},
+	} {
+		if entries[i].URL != expected.url || entries[i].Commit != expected.commit {
+			t.Fatalf("unexpected entry %d: %v", i, entries[i])
+		}
+	}
+
+	removed, err := c.Prune(time.Now().Add(-24 * time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(removed) != 1 || removed[0].Commit != commitA || removed[0].URL != urlX {
+		t.Fatalf("unexpected removals: %v", removed)
+	}
+	if _, ok := c.Lookup(urlX, commitA); ok {
+		t.Fatalf("expected %s to be pruned", commitA)
+	}
+	if _, ok := c.ResolveRef(urlX, "v1"); ok {
+		t.Fatalf("expected the ref at %s to be forgotten", commitA)
+	}
+	if commit, _ := c.ResolveRef(urlX, "main"); commit != commitB {
+		t.Fatalf("expected to keep the ref at %s", commitB)
+	}
+	if _, err := os.Stat(leftover); !os.IsNotExist(err) {
+		t.Fatalf("expected the leftover %s to be pruned", leftover)
+	}
+
+	removed, err = c.Prune(time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(removed) != 2 {
+		t.Fatalf("unexpected removals: %v", removed)
+	}
+	dirs, err := ioutil.ReadDir(c.Dir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dirs) != 0 {
+		t.Fatalf("expected an empty cache, got %v", dirs)
+	}
+}
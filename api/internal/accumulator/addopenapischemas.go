@@ -0,0 +1,107 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package accumulator
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sync"
+
+	"github.com/go-openapi/spec"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+	"sigs.k8s.io/yaml"
+)
+
+// OpenAPISchema is an OpenAPI document read from a file.
+type OpenAPISchema struct {
+	// Path is the path of the file.
+	Path string
+	// Document is the document, as JSON.
+	Document []byte
+}
+
+// LoadOpenAPISchemas reads, with the given loader, the
+// OpenAPI documents in the files at the given paths.  A file
+// is an OpenAPI document, or holds one in its openAPI field.
+func LoadOpenAPISchemas(
+	ldr ifc.Loader, paths []string) ([]OpenAPISchema, error) {
+	var result []OpenAPISchema
+	for _, path := range paths {
+		content, err := ldr.Load(path)
+		if err != nil {
+			return nil, err
+		}
+		j, err := openAPIDocument(content)
+		if err != nil {
+			return nil, errors.Wrapf(
+				err, "unable to parse OpenAPI schema from '%s'", path)
+		}
+		result = append(result, OpenAPISchema{
+			Path: filepath.Join(ldr.Root(), path), Document: j})
+	}
+	return result, nil
+}
+
+// openAPIDocument returns, as JSON, the OpenAPI document
+// in content, or under the openAPI field of content.
+func openAPIDocument(content []byte) ([]byte, error) {
+	var m map[string]interface{}
+	err := yaml.Unmarshal(content, &m)
+	if err != nil {
+		return nil, err
+	}
+	if d, ok := m[openapi.SupplementaryOpenAPIFieldName]; ok {
+		return json.Marshal(d)
+	}
+	return json.Marshal(m)
+}
+
+// openAPILock guards the definitions of kyaml/openapi, which
+// are process globals.  Builds adding definitions hold it
+// exclusively, and other builds hold it shared, so that no
+// build sees the definitions of another.
+var openAPILock sync.RWMutex
+
+// UseOpenAPISchemas adds the definitions of the given schemas
+// to those of kyaml/openapi, as openapi.AddSchema does.  A
+// definition having an x-kubernetes-group-version-kind
+// extension is the schema of resources of that kind; strategic
+// merge patches of those resources honor its
+// x-kubernetes-patch-merge-key and x-kubernetes-patch-strategy
+// extensions.  The definitions stay until the returned func is
+// called, which restores those there were before.  Till then,
+// other calls wait, unless neither adds any definitions.
+//
+// The definitions are read concurrently by the builds using
+// them, so they must be added before the builds start.
+func UseOpenAPISchemas(schemas []OpenAPISchema) (func(), error) {
+	if len(schemas) == 0 {
+		openAPILock.RLock()
+		return openAPILock.RUnlock, nil
+	}
+	openAPILock.Lock()
+	saved := make(spec.Definitions)
+	for k, d := range openapi.Schema().Definitions {
+		saved[k] = d
+	}
+	release := func() {
+		openapi.ResetOpenAPI()
+		// The saved definitions include the builtin
+		// ones, if they were in use.
+		openapi.SuppressBuiltInSchemaUse()
+		openapi.AddDefinitions(saved)
+		openAPILock.Unlock()
+	}
+	for _, s := range schemas {
+		_, err := openapi.AddSchema(s.Document)
+		if err != nil {
+			release()
+			return nil, errors.Wrapf(
+				err, "unable to add OpenAPI schema from '%s'", s.Path)
+		}
+	}
+	return release, nil
+}
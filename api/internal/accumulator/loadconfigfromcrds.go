@@ -13,6 +13,7 @@ import (
 	"sigs.k8s.io/kustomize/api/filesys"
 	"sigs.k8s.io/kustomize/api/ifc"
 	"sigs.k8s.io/kustomize/api/internal/plugins/builtinconfig"
+	"sigs.k8s.io/kustomize/api/internal/validate"
 	"sigs.k8s.io/kustomize/api/resid"
 	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/yaml"
@@ -46,6 +47,27 @@ func LoadConfigFromCRDs(
 	return tc, nil
 }
 
+// LoadDefinitionsFromCRDs returns the OpenAPI
+// definitions in the CRD files at the given paths.
+func LoadDefinitionsFromCRDs(
+	ldr ifc.Loader, paths []string) (validate.Definitions, error) {
+	result := validate.Definitions{}
+	for _, path := range paths {
+		content, err := ldr.Load(path)
+		if err != nil {
+			return nil, err
+		}
+		m, err := makeNameToApiMap(content)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to parse open API definition from '%s'", path)
+		}
+		for name, api := range m {
+			result[name] = api.Schema
+		}
+	}
+	return result, nil
+}
+
 func makeNameToApiMap(content []byte) (result nameToApiMap, err error) {
 	if content[0] == '{' {
 		err = json.Unmarshal(content, &result)
@@ -6,11 +6,18 @@ package accumulator
 import (
 	"fmt"
 	"log"
+	"reflect"
+	"sort"
 	"strings"
 
+	"sigs.k8s.io/kustomize/api/explain"
+	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/internal/kusterr"
 	"sigs.k8s.io/kustomize/api/internal/plugins/builtinconfig"
+	"sigs.k8s.io/kustomize/api/internal/validate"
 	"sigs.k8s.io/kustomize/api/resid"
 	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
 )
 
@@ -21,6 +28,20 @@ type ResAccumulator struct {
 	resMap  resmap.ResMap
 	tConfig *builtinconfig.TransformerConfig
 	varSet  types.VarSet
+	// When true, TrackChanges notes transformations
+	// on the resources they change.
+	trackTransformations bool
+	// When not nil, a record of the changes made
+	// by TrackChanges and TraceChanges.
+	trace *explain.Trace
+	// Where vars were declared, by name, if known.
+	varPositions map[string]kusterr.Position
+	// OpenAPI definitions read from CRD files, used to
+	// validate resources.  Like tConfig, never mutated.
+	crdDefinitions validate.Definitions
+	// When true, ResolveVars fails on
+	// vars that were never replaced.
+	strict bool
 }
 
 func MakeEmptyAccumulator() *ResAccumulator {
@@ -36,6 +57,27 @@ func (ra *ResAccumulator) ResMap() resmap.ResMap {
 	return ra.resMap.ShallowCopy()
 }
 
+// DeepCopy returns a copy of the accumulator whose resources
+// can be transformed without affecting the original.
+// The transformer config is shared, since merging configs
+// always yields a new config rather than mutating one.
+func (ra *ResAccumulator) DeepCopy() *ResAccumulator {
+	c := &ResAccumulator{
+		resMap:               ra.resMap.DeepCopy(),
+		tConfig:              ra.tConfig,
+		varSet:               ra.varSet.Copy(),
+		trackTransformations: ra.trackTransformations,
+		crdDefinitions:       ra.crdDefinitions,
+		strict:               ra.strict,
+	}
+	c.mergeVarPositions(ra.varPositions)
+	if ra.trace != nil {
+		c.trace = &explain.Trace{
+			Steps: append([]explain.Step(nil), ra.trace.Steps...)}
+	}
+	return c
+}
+
 // Vars returns a copy of underlying vars.
 func (ra *ResAccumulator) Vars() []types.Var {
 	return ra.varSet.AsSlice()
@@ -57,6 +99,28 @@ func (ra *ResAccumulator) MergeConfig(
 	return err
 }
 
+// MergeCrdDefinitions adds OpenAPI definitions read
+// from CRD files to those used by ValidateSchemas.
+func (ra *ResAccumulator) MergeCrdDefinitions(d validate.Definitions) {
+	ra.crdDefinitions = ra.crdDefinitions.Merge(d)
+}
+
+// ValidateSchemas checks the accumulated resources against
+// their OpenAPI schemas, and returns the problems found.
+func (ra *ResAccumulator) ValidateSchemas() []validate.Problem {
+	return validate.Resources(ra.resMap, ra.crdDefinitions)
+}
+
+// SetConfigHash asks for the pod templates of the resources
+// accumulated so far to be annotated with the hash of the
+// ConfigMaps and Secrets they refer to, as c says, overriding
+// what the kustomizations they came from asked for.
+func (ra *ResAccumulator) SetConfigHash(c *types.ConfigHash) {
+	for _, r := range ra.resMap.Resources() {
+		r.SetConfigHash(c)
+	}
+}
+
 func (ra *ResAccumulator) GetTransformerConfig() *builtinconfig.TransformerConfig {
 	return ra.tConfig
 }
@@ -84,6 +148,22 @@ func (ra *ResAccumulator) MergeVars(incoming []types.Var) error {
 	return ra.varSet.MergeSlice(incoming)
 }
 
+// SetVarPosition notes where the named var was declared,
+// so that a failure to resolve it can say where to look.
+func (ra *ResAccumulator) SetVarPosition(name string, p kusterr.Position) {
+	if ra.varPositions == nil {
+		ra.varPositions = make(map[string]kusterr.Position)
+	}
+	ra.varPositions[name] = p
+}
+
+func (ra *ResAccumulator) mergeVarPositions(
+	positions map[string]kusterr.Position) {
+	for name, p := range positions {
+		ra.SetVarPosition(name, p)
+	}
+}
+
 func (ra *ResAccumulator) MergeAccumulator(other *ResAccumulator) (err error) {
 	err = ra.AppendAll(other.resMap)
 	if err != nil {
@@ -93,6 +173,11 @@ func (ra *ResAccumulator) MergeAccumulator(other *ResAccumulator) (err error) {
 	if err != nil {
 		return err
 	}
+	if ra.trace != nil && other.trace != nil {
+		ra.trace.Steps = append(ra.trace.Steps, other.trace.Steps...)
+	}
+	ra.mergeVarPositions(other.varPositions)
+	ra.MergeCrdDefinitions(other.crdDefinitions)
 	return ra.varSet.MergeSet(other.varSet)
 }
 
@@ -125,6 +210,9 @@ func (ra *ResAccumulator) makeVarReplacementMap() (map[string]interface{}, error
 	for _, v := range ra.Vars() {
 		s, err := ra.findVarValueFromResources(v)
 		if err != nil {
+			if p, ok := ra.varPositions[v.Name]; ok {
+				return nil, kusterr.At(p, err)
+			}
 			return nil, err
 		}
 
@@ -138,6 +226,143 @@ func (ra *ResAccumulator) Transform(t resmap.Transformer) error {
 	return t.Transform(ra.resMap)
 }
 
+// EnableTransformationTracking makes TrackChanges note the
+// transformation on each resource changed.
+func (ra *ResAccumulator) EnableTransformationTracking() {
+	ra.trackTransformations = true
+}
+
+// EnableStrictMode makes ResolveVars fail on
+// vars that were never replaced, rather than
+// logging them.
+func (ra *ResAccumulator) EnableStrictMode() {
+	ra.strict = true
+}
+
+// EnableTrace makes TrackChanges and TraceChanges
+// record the changes they see in a trace.
+func (ra *ResAccumulator) EnableTrace() {
+	if ra.trace == nil {
+		ra.trace = &explain.Trace{}
+	}
+}
+
+// Trace returns the steps recorded so far, including
+// those of merged accumulators, or nil if tracing is off.
+func (ra *ResAccumulator) Trace() *explain.Trace {
+	return ra.trace
+}
+
+// TrackChanges calls f, which is expected to modify the
+// accumulated resources on behalf of the given transformation.
+// If enabled, the transformation is noted on each resource
+// that f changed or introduced, and the changes are traced.
+// To find changes, a copy of every resource is held
+// until f returns, so this is a plain call to f unless
+// tracking or tracing is enabled.
+func (ra *ResAccumulator) TrackChanges(
+	t resource.Transformation, f func() error) error {
+	if !ra.trackTransformations && ra.trace == nil {
+		return f()
+	}
+	w := ra.watch()
+	err := f()
+	if err != nil {
+		return err
+	}
+	changed := w.changed(ra.resMap)
+	if ra.trackTransformations {
+		for _, r := range changed {
+			r.AppendTransformation(t)
+		}
+	}
+	ra.traceStep(t.Transformer, t.Root, w, changed)
+	return nil
+}
+
+// TraceChanges is like TrackChanges, but only records a
+// trace; it's for steps that aren't transformations,
+// e.g. generators.
+func (ra *ResAccumulator) TraceChanges(
+	name, root string, f func() error) error {
+	if ra.trace == nil {
+		return f()
+	}
+	w := ra.watch()
+	err := f()
+	if err != nil {
+		return err
+	}
+	ra.traceStep(name, root, w, w.changed(ra.resMap))
+	return nil
+}
+
+// snapshot is a resource as it was when a watch began.
+type snapshot struct {
+	id  resid.ResId
+	obj ifc.Kunstructured
+}
+
+// watcher holds copies of resources, to find changes.
+type watcher struct {
+	order    []*resource.Resource
+	previous map[*resource.Resource]snapshot
+}
+
+func (ra *ResAccumulator) watch() *watcher {
+	w := &watcher{previous: make(map[*resource.Resource]snapshot)}
+	for _, r := range ra.resMap.Resources() {
+		w.order = append(w.order, r)
+		w.previous[r] = snapshot{id: r.CurId(), obj: r.Kunstructured.Copy()}
+	}
+	return w
+}
+
+// changed returns the resources in m that are
+// new, or that differ from their snapshot.
+func (w *watcher) changed(m resmap.ResMap) []*resource.Resource {
+	var result []*resource.Resource
+	for _, r := range m.Resources() {
+		if s, ok := w.previous[r]; ok && reflect.DeepEqual(s.obj.Map(), r.Map()) {
+			continue
+		}
+		result = append(result, r)
+	}
+	return result
+}
+
+func (ra *ResAccumulator) traceStep(
+	name, root string, w *watcher, changed []*resource.Resource) {
+	if ra.trace == nil {
+		return
+	}
+	step := explain.Step{Name: name, Root: root}
+	for _, r := range changed {
+		s, ok := w.previous[r]
+		if !ok {
+			step.Resources = append(step.Resources, explain.ResourceChange{
+				Id: r.CurId().String(), Action: explain.Added})
+			continue
+		}
+		step.Resources = append(step.Resources, explain.ResourceChange{
+			Id:     r.CurId().String(),
+			Action: explain.Changed,
+			Fields: explain.Diff(s.obj.Map(), r.Map()),
+		})
+	}
+	remaining := make(map[*resource.Resource]bool)
+	for _, r := range ra.resMap.Resources() {
+		remaining[r] = true
+	}
+	for _, r := range w.order {
+		if !remaining[r] {
+			step.Resources = append(step.Resources, explain.ResourceChange{
+				Id: w.previous[r].id.String(), Action: explain.Removed})
+		}
+	}
+	ra.trace.Steps = append(ra.trace.Steps, step)
+}
+
 func (ra *ResAccumulator) ResolveVars() error {
 	replacementMap, err := ra.makeVarReplacementMap()
 	if err != nil {
@@ -149,11 +374,19 @@ func (ra *ResAccumulator) ResolveVars() error {
 	t := newRefVarTransformer(
 		replacementMap, ra.tConfig.VarReference)
 	err = ra.Transform(t)
-	if len(t.UnusedVars()) > 0 {
-		log.Printf(
-			"well-defined vars that were never replaced: %s\n",
-			strings.Join(t.UnusedVars(), ","))
+	unused := t.UnusedVars()
+	if len(unused) == 0 {
+		return err
+	}
+	sort.Strings(unused)
+	if ra.strict && err == nil {
+		return fmt.Errorf(
+			"well-defined vars that were never replaced: %s",
+			strings.Join(unused, ","))
 	}
+	log.Printf(
+		"well-defined vars that were never replaced: %s\n",
+		strings.Join(unused, ","))
 	return err
 }
 
@@ -0,0 +1,136 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package accumulator
+
+import (
+	"sort"
+
+	"sigs.k8s.io/kustomize/api/internal/plugins/builtinconfig"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// Kinds that the topological sort puts last.  A webhook
+// whose service isn't yet running would reject the
+// creation of the resources it's meant to check.
+var webhookKinds = map[string]bool{
+	"MutatingWebhookConfiguration":   true,
+	"ValidatingWebhookConfiguration": true,
+}
+
+// SortTopologically orders the resources of m so that each
+// comes after the resources it depends on.  A resource depends
+// on the resources whose names it holds in a name reference
+// field of tc, on the CustomResourceDefinition of its kind, and
+// on the Namespace it's in.  Webhook configurations depend on
+// every other resource.  Among resources free to go next, the
+// first per the kind order o goes next.  Dependency cycles are
+// broken the same way.
+func SortTopologically(
+	m resmap.ResMap, tc *builtinconfig.TransformerConfig,
+	o *types.KindOrder) error {
+	rs := m.Resources()
+	sort.SliceStable(rs, func(i, j int) bool {
+		return o.IsLessThan(rs[i].CurId(), rs[j].CurId())
+	})
+	deps := findDependencies(rs, tc)
+	done := make([]bool, len(rs))
+	var result []*resource.Resource
+	for len(result) < len(rs) {
+		next := -1
+		for i := range rs {
+			if !done[i] && allDone(deps[i], done) {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			for i := range rs {
+				if !done[i] {
+					next = i
+					break
+				}
+			}
+		}
+		done[next] = true
+		result = append(result, rs[next])
+	}
+	m.Clear()
+	for _, r := range result {
+		err := m.Append(r)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func allDone(indices []int, done []bool) bool {
+	for _, i := range indices {
+		if !done[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// findDependencies returns, for each resource of rs,
+// the indices of the resources it depends on.
+func findDependencies(
+	rs []*resource.Resource, tc *builtinconfig.TransformerConfig) [][]int {
+	deps := make([][]int, len(rs))
+	for i, r := range rs {
+		for j, other := range rs {
+			if i != j && dependsOn(r, other, tc) {
+				deps[i] = append(deps[i], j)
+			}
+		}
+	}
+	return deps
+}
+
+// dependsOn returns true if r depends on other.
+func dependsOn(
+	r, other *resource.Resource, tc *builtinconfig.TransformerConfig) bool {
+	if webhookKinds[r.GetKind()] {
+		return !webhookKinds[other.GetKind()]
+	}
+	switch other.GetKind() {
+	case "Namespace":
+		if r.GetNamespace() == other.GetName() {
+			return true
+		}
+	case "CustomResourceDefinition":
+		if definesKindOf(other, r) {
+			return true
+		}
+	}
+	return refersTo(r, other, tc)
+}
+
+// definesKindOf returns true if crd defines the kind of r.
+func definesKindOf(crd, r *resource.Resource) bool {
+	group, _ := crd.GetString("spec.group")
+	kind, _ := crd.GetString("spec.names.kind")
+	gvk := r.GetGvk()
+	return kind != "" && gvk.Kind == kind && gvk.Group == group
+}
+
+// refersTo returns true if r holds the name of
+// other in one of the name reference fields of tc.
+func refersTo(
+	r, other *resource.Resource, tc *builtinconfig.TransformerConfig) bool {
+	if tc == nil {
+		return false
+	}
+	otherGvk := other.GetGvk()
+	for _, nbr := range tc.NameReference {
+		if otherGvk.IsSelected(&nbr.Gvk) &&
+			r.RefersTo(other, nbr.FieldSpecs) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,196 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/gitcache"
+)
+
+// The ref used when a repoSpec has none,
+// i.e. the default branch of the repo.
+const defaultRef = "HEAD"
+
+var fullCommitSha = regexp.MustCompile("^[0-9a-f]{40}$")
+
+// CachingClonerUsingGitExec returns a Cloner that, like
+// ClonerUsingGitExec, uses a local git install, but takes
+// clones from the given cache, adding to the cache those
+// it lacks.  The ref of a repoSpec is resolved to a commit
+// with the remote repo, unless it's a commit already.  When
+// offline, the remote repo isn't consulted at all, so the
+// ref must have been resolved, and the commit cloned, by an
+// earlier build.
+func CachingClonerUsingGitExec(cache *gitcache.Cache, offline bool) Cloner {
+	return func(repoSpec *RepoSpec) error {
+		gitProgram, err := exec.LookPath("git")
+		if err != nil {
+			return errors.Wrap(err, "no 'git' program on path")
+		}
+		url := repoSpec.CloneSpec()
+		ref := repoSpec.Ref
+		if ref == "" {
+			ref = defaultRef
+		}
+		commit, err := resolveCommit(gitProgram, cache, url, ref, offline)
+		if err != nil {
+			return err
+		}
+		entry, ok := cache.Lookup(url, commit)
+		if !ok {
+			if offline {
+				return fmt.Errorf(
+					"offline, and commit %s of %s isn't in the git cache at %s",
+					commit, url, cache.Dir())
+			}
+			entry, err = cloneIntoCache(gitProgram, cache, url, ref, commit)
+			if err != nil {
+				return err
+			}
+		}
+		err = cache.Touch(entry)
+		if err != nil {
+			return err
+		}
+		repoSpec.Dir = filesys.ConfirmedDir(entry.Dir)
+		repoSpec.Commit = entry.Commit
+		repoSpec.cached = true
+		return nil
+	}
+}
+
+// resolveCommit returns the commit the ref of the repo is at.
+func resolveCommit(
+	gitProgram string, cache *gitcache.Cache,
+	url, ref string, offline bool) (string, error) {
+	if fullCommitSha.MatchString(ref) {
+		return ref, nil
+	}
+	if offline {
+		commit, ok := cache.ResolveRef(url, ref)
+		if !ok {
+			return "", fmt.Errorf(
+				"offline, and ref '%s' of %s isn't in the git cache at %s",
+				ref, url, cache.Dir())
+		}
+		return commit, nil
+	}
+	out, err := runGit(gitProgram, "", "ls-remote", url, ref)
+	if err != nil {
+		return "", errors.Wrapf(err, "trouble resolving ref '%s' of %s", ref, url)
+	}
+	commit := pickCommit(out, ref)
+	if commit == "" {
+		return "", fmt.Errorf("ref '%s' not found in %s", ref, url)
+	}
+	return commit, cache.SetRef(url, ref, commit)
+}
+
+// pickCommit returns the commit of the ref in the output
+// of git ls-remote, preferring an exact match, then a
+// branch, then the commit of an annotated tag, then a tag.
+func pickCommit(lsRemote, ref string) string {
+	commits := make(map[string]string)
+	for _, line := range strings.Split(lsRemote, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 {
+			commits[fields[1]] = fields[0]
+		}
+	}
+	for _, name := range []string{
+		ref, "refs/heads/" + ref,
+		"refs/tags/" + ref + "^{}", "refs/tags/" + ref} {
+		if commit, ok := commits[name]; ok {
+			return commit
+		}
+	}
+	return ""
+}
+
+// cloneIntoCache clones the ref of the repo, which is expected
+// to be at the given commit, into the cache.  The commit the
+// clone ends up at is what's cached, in case the ref moved.
+func cloneIntoCache(
+	gitProgram string, cache *gitcache.Cache,
+	url, ref, commit string) (*gitcache.Entry, error) {
+	dir, err := cache.TempDir()
+	if err != nil {
+		return nil, err
+	}
+	err = fetchInto(gitProgram, dir, url, ref, commit)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, errors.Wrapf(
+			err, "trouble cloning git repo %s in %s", url, dir)
+	}
+	out, err := runGit(gitProgram, dir, "rev-parse", "HEAD")
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	return cache.Add(url, strings.TrimSpace(out), dir)
+}
+
+// fetchInto makes a shallow clone of the ref in the given
+// directory.  Some servers won't send a commit that isn't
+// named by a ref, so a commit is fetched with all the
+// history if need be.
+func fetchInto(gitProgram, dir, url, ref, commit string) error {
+	for _, args := range [][]string{
+		{"init"},
+		{"remote", "add", "origin", url},
+	} {
+		if _, err := runGit(gitProgram, dir, args...); err != nil {
+			return err
+		}
+	}
+	_, err := runGit(gitProgram, dir, "fetch", "--depth=1", "origin", ref)
+	if err != nil {
+		if ref != commit {
+			return err
+		}
+		_, err = runGit(gitProgram, dir, "fetch", "origin")
+		if err != nil {
+			return err
+		}
+	}
+	target := "FETCH_HEAD"
+	if ref == commit {
+		target = commit
+	}
+	for _, args := range [][]string{
+		{"checkout", "--quiet", "--detach", target},
+		{"submodule", "update", "--init", "--recursive"},
+	} {
+		if _, err := runGit(gitProgram, dir, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runGit runs git with the given args in the given
+// directory, returning its output.
+func runGit(gitProgram, dir string, args ...string) (string, error) {
+	cmd := exec.Command(gitProgram, args...)
+	cmd.Dir = dir
+	var out, stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		return "", errors.Wrapf(
+			err, "git %s: %s", strings.Join(args, " "),
+			strings.TrimSpace(stderr.String()))
+	}
+	return out.String(), nil
+}
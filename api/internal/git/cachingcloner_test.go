@@ -0,0 +1,208 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package git
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/gitcache"
+)
+
+// testRepo is a bare git repo, along with a
+// working clone used to commit to it.
+type testRepo struct {
+	t    *testing.T
+	bare string
+	work string
+}
+
+func makeTestRepo(t *testing.T, dir string) *testRepo {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("no 'git' program on path")
+	}
+	r := &testRepo{
+		t:    t,
+		bare: filepath.Join(dir, "repo.git"),
+		work: filepath.Join(dir, "work"),
+	}
+	r.git(dir, "init", "--quiet", "--bare", r.bare)
+	r.git(dir, "init", "--quiet", r.work)
+	r.git(r.work, "remote", "add", "origin", r.bare)
+	return r
+}
+
+func (r *testRepo) git(dir string, args ...string) string {
+	cmd := exec.Command("git", append([]string{
+		"-c", "user.name=test", "-c", "user.email=test@example.com",
+		"-c", "protocol.file.allow=always"}, args...)...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// commit commits the given content to the given
+// file, pushes it, and returns the commit.
+func (r *testRepo) commit(file, content string) string {
+	err := ioutil.WriteFile(
+		filepath.Join(r.work, file), []byte(content), 0600)
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	r.git(r.work, "add", file)
+	r.git(r.work, "commit", "--quiet", "-m", "change "+file)
+	r.git(r.work, "push", "--quiet", "origin", "HEAD:refs/heads/main")
+	return r.git(r.work, "rev-parse", "HEAD")
+}
+
+func (r *testRepo) repoSpec(ref string) *RepoSpec {
+	return &RepoSpec{
+		Host:      "file://",
+		OrgRepo:   strings.TrimSuffix(r.bare, ".git"),
+		GitSuffix: ".git",
+		Ref:       ref,
+		Dir:       notCloned,
+	}
+}
+
+func readCloned(t *testing.T, rs *RepoSpec, file string) string {
+	data, err := ioutil.ReadFile(rs.Dir.Join(file))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return string(data)
+}
+
+func TestCachingCloner(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kustomize-git-cache-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	repo := makeTestRepo(t, dir)
+	first := repo.commit("data", "first")
+	repo.git(repo.bare, "symbolic-ref", "HEAD", "refs/heads/main")
+	repo.git(repo.work, "tag", "v1")
+	repo.git(repo.work, "push", "--quiet", "origin", "v1")
+	cache := gitcache.New(filepath.Join(dir, "cache"))
+
+	// Online, the ref is resolved and the commit is cached.
+	rs := repo.repoSpec("main")
+	err = CachingClonerUsingGitExec(cache, false)(rs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := readCloned(t, rs, "data"); got != "first" {
+		t.Fatalf("unexpected content: %s", got)
+	}
+	if !strings.HasSuffix(rs.Dir.String(), first) {
+		t.Fatalf("expected a clone keyed by %s, got %s", first, rs.Dir)
+	}
+	err = rs.Cleaner(filesys.MakeFsOnDisk())()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := cache.Lookup(rs.CloneSpec(), first); !ok {
+		t.Fatalf("expected the cleaner to leave the cache alone")
+	}
+
+	// Offline, the ref is resolved with the cache.
+	second := repo.commit("data", "second")
+	rs = repo.repoSpec("main")
+	err = CachingClonerUsingGitExec(cache, true)(rs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := readCloned(t, rs, "data"); got != "first" {
+		t.Fatalf("unexpected content: %s", got)
+	}
+
+	// Online, the ref is resolved anew, and the
+	// repo's default branch is used if there's no ref.
+	rs = repo.repoSpec("")
+	err = CachingClonerUsingGitExec(cache, false)(rs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := readCloned(t, rs, "data"); got != "second" {
+		t.Fatalf("unexpected content: %s", got)
+	}
+
+	// Tags resolve, and commits can be pinned, even offline.
+	rs = repo.repoSpec("v1")
+	err = CachingClonerUsingGitExec(cache, false)(rs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasSuffix(rs.Dir.String(), first) {
+		t.Fatalf("expected a clone keyed by %s, got %s", first, rs.Dir)
+	}
+	rs = repo.repoSpec(second)
+	err = CachingClonerUsingGitExec(cache, true)(rs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := readCloned(t, rs, "data"); got != "second" {
+		t.Fatalf("unexpected content: %s", got)
+	}
+
+	entries, err := cache.List()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 cache entries, got %v", entries)
+	}
+}
+
+func TestCachingClonerOfflineMiss(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kustomize-git-cache-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	repo := makeTestRepo(t, dir)
+	commit := repo.commit("data", "first")
+	cache := gitcache.New(filepath.Join(dir, "cache"))
+
+	err = CachingClonerUsingGitExec(cache, true)(repo.repoSpec("main"))
+	if err == nil ||
+		!strings.Contains(err.Error(), "offline, and ref 'main' of") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = CachingClonerUsingGitExec(cache, true)(repo.repoSpec(commit))
+	if err == nil ||
+		!strings.Contains(err.Error(), "offline, and commit "+commit) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestPickCommit(t *testing.T) {
+	lsRemote := `
+1111111111111111111111111111111111111111	HEAD
+2222222222222222222222222222222222222222	refs/heads/v1
+3333333333333333333333333333333333333333	refs/tags/v2
+4444444444444444444444444444444444444444	refs/tags/v2^{}
+5555555555555555555555555555555555555555	refs/tags/v3
+`
+	for ref, expected := range map[string]string{
+		"HEAD":    "1111111111111111111111111111111111111111",
+		"v1":      "2222222222222222222222222222222222222222",
+		"v2":      "4444444444444444444444444444444444444444",
+		"v3":      "5555555555555555555555555555555555555555",
+		"missing": "",
+	} {
+		if actual := pickCommit(lsRemote, ref); actual != expected {
+			t.Errorf("ref %s: expected '%s', got '%s'", ref, expected, actual)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"log"
 	"os/exec"
+	"strings"
 
 	"github.com/pkg/errors"
 	"sigs.k8s.io/kustomize/api/filesys"
@@ -65,6 +66,11 @@ func ClonerUsingGitExec(repoSpec *RepoSpec) error {
 		return errors.Wrapf(err, "trouble fetching submodules for %s", repoSpec.CloneSpec())
 	}
 
+	commit, err := runGit(gitProgram, repoSpec.Dir.String(), "rev-parse", "HEAD")
+	if err != nil {
+		return err
+	}
+	repoSpec.Commit = strings.TrimSpace(commit)
 	return nil
 }
 
@@ -0,0 +1,243 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kusterr
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// Position is a place in a file.  Line and Column
+// count from 1; zero means unknown.
+type Position struct {
+	Path   string
+	Line   int
+	Column int
+}
+
+// String returns the position as path:line:col,
+// leaving out the parts that are unknown.
+func (p Position) String() string {
+	s := p.Path
+	if p.Line > 0 {
+		s += fmt.Sprintf(":%d", p.Line)
+		if p.Column > 0 {
+			s += fmt.Sprintf(":%d", p.Column)
+		}
+	}
+	return s
+}
+
+// SourceError is an error found at a position in a file.
+type SourceError struct {
+	Position
+	Err error
+}
+
+// Error returns the error in the form path:line:col: message.
+func (e *SourceError) Error() string {
+	p := e.Position.String()
+	if p == "" {
+		return e.Err.Error()
+	}
+	return p + ": " + e.Err.Error()
+}
+
+// Cause returns the underlying error, for errors.Cause.
+func (e *SourceError) Cause() error { return e.Err }
+
+// Unwrap returns the underlying error, for the
+// standard library's errors.Is and errors.As.
+func (e *SourceError) Unwrap() error { return e.Err }
+
+// At returns err as a SourceError at position p,
+// or nil if err is nil.
+func At(p Position, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &SourceError{Position: p, Err: err}
+}
+
+// Locate returns the innermost SourceError among
+// the errors wrapped by err, or nil if there's none.
+func Locate(err error) *SourceError {
+	var result *SourceError
+	for err != nil {
+		if e, ok := err.(*SourceError); ok {
+			result = e
+		}
+		switch e := err.(type) {
+		case interface{ Cause() error }:
+			err = e.Cause()
+		case interface{ Unwrap() error }:
+			err = e.Unwrap()
+		default:
+			err = nil
+		}
+	}
+	return result
+}
+
+// Hoist returns err with the position of the innermost
+// SourceError it wraps moved to the front of its message,
+// keeping the context the outer errors add, e.g.
+//   a.yaml:3: accumulating resources from 'a.yaml': bad
+// If err wraps no SourceError, it's returned unchanged.
+func Hoist(err error) error {
+	e := Locate(err)
+	if e == nil || e.Position.String() == "" {
+		return err
+	}
+	return At(e.Position, &hoisted{
+		msg: strings.Replace(err.Error(), e.Error(), e.Err.Error(), 1),
+		err: err,
+	})
+}
+
+// hoisted is an error whose message is that of
+// err, less the position of a SourceError in it.
+type hoisted struct {
+	msg string
+	err error
+}
+
+func (e *hoisted) Error() string { return e.msg }
+
+// Cause returns the hoisted error, for errors.Cause.
+func (e *hoisted) Cause() error { return e.err }
+
+// Unwrap returns the hoisted error, for the
+// standard library's errors.Is and errors.As.
+func (e *hoisted) Unwrap() error { return e.err }
+
+var yamlLineError = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// SyntaxError returns the first YAML syntax error in content,
+// the content of the file at path, as a SourceError,
+// or nil if every document in content parses.
+func SyntaxError(path string, content []byte) error {
+	d := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var n yaml.Node
+		err := d.Decode(&n)
+		if err == io.EOF {
+			return nil
+		}
+		if err == nil {
+			continue
+		}
+		m := yamlLineError.FindStringSubmatch(err.Error())
+		if m == nil {
+			return At(Position{Path: path}, err)
+		}
+		line, _ := strconv.Atoi(m[1])
+		return At(Position{Path: path, Line: line}, errors.New(m[2]))
+	}
+}
+
+// File finds the positions of fields in a YAML file.
+type File struct {
+	path string
+	// The root of the file's first document,
+	// or nil if the file doesn't parse.
+	root *yaml.Node
+}
+
+// NewFile returns a File for content, the content of the file at path.
+func NewFile(path string, content []byte) *File {
+	f := &File{path: path}
+	var n yaml.Node
+	if yaml.Unmarshal(content, &n) == nil && len(n.Content) > 0 {
+		f.root = n.Content[0]
+	}
+	return f
+}
+
+// Path returns the path of the file.
+func (f *File) Path() string {
+	return f.path
+}
+
+// PositionOf returns the position of the field at the given
+// path, e.g. ("vars", "1", "name") for the name of the second
+// var.  A path element that isn't a number, applied to a list,
+// finds the first element of the list having that field.
+// If the field isn't found, the position is that of the
+// deepest field found along the way, or of the file itself.
+func (f *File) PositionOf(fields ...string) Position {
+	p := Position{Path: f.path}
+	n := f.root
+	for _, field := range fields {
+		key, value := child(n, field)
+		if value == nil {
+			break
+		}
+		if key != nil {
+			p.Line, p.Column = key.Line, key.Column
+		} else {
+			p.Line, p.Column = value.Line, value.Column
+		}
+		n = value
+	}
+	return p
+}
+
+// child returns the key and value of the named field of the
+// mapping node n, or the element of the sequence node n at
+// the index given by field, in which case the key is nil.
+func child(n *yaml.Node, field string) (key, value *yaml.Node) {
+	if n == nil {
+		return nil, nil
+	}
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if n.Content[i].Value == field {
+				return n.Content[i], n.Content[i+1]
+			}
+		}
+	case yaml.SequenceNode:
+		if i, err := strconv.Atoi(field); err == nil {
+			if i >= 0 && i < len(n.Content) {
+				return nil, n.Content[i]
+			}
+			return nil, nil
+		}
+		for _, e := range n.Content {
+			if key, value := child(e, field); value != nil {
+				return key, value
+			}
+		}
+	}
+	return nil, nil
+}
+
+// PositionOfKey returns the position of the first mapping
+// key named key, searching the file breadth first, or the
+// position of the file itself if there's no such key.
+func (f *File) PositionOfKey(key string) Position {
+	var queue []*yaml.Node
+	if f.root != nil {
+		queue = append(queue, f.root)
+	}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		for i, c := range n.Content {
+			if n.Kind == yaml.MappingNode && i%2 == 0 && c.Value == key {
+				return Position{Path: f.path, Line: c.Line, Column: c.Column}
+			}
+			queue = append(queue, c)
+		}
+	}
+	return Position{Path: f.path}
+}
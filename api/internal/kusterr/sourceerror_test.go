@@ -0,0 +1,124 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kusterr
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestPositionString(t *testing.T) {
+	testCases := []struct {
+		p        Position
+		expected string
+	}{
+		{Position{}, ""},
+		{Position{Path: "a.yaml"}, "a.yaml"},
+		{Position{Path: "a.yaml", Line: 3}, "a.yaml:3"},
+		{Position{Path: "a.yaml", Line: 3, Column: 5}, "a.yaml:3:5"},
+		{Position{Path: "a.yaml", Column: 5}, "a.yaml"},
+	}
+	for _, tc := range testCases {
+		if actual := tc.p.String(); actual != tc.expected {
+			t.Errorf("expected %q, got %q", tc.expected, actual)
+		}
+	}
+}
+
+func TestLocate(t *testing.T) {
+	if Locate(fmt.Errorf("plain")) != nil {
+		t.Fatalf("expected no located error")
+	}
+	inner := At(Position{Path: "b.yaml", Line: 2}, fmt.Errorf("bad"))
+	outer := At(Position{Path: "a.yaml"}, errors.Wrap(inner, "wrapped"))
+	err := fmt.Errorf("context: %w", errors.Wrap(outer, "more"))
+	e := Locate(err)
+	if e == nil || e.Error() != "b.yaml:2: bad" {
+		t.Fatalf("expected innermost error, got %v", e)
+	}
+	if errors.Cause(err) == nil || errors.Cause(inner).Error() != "bad" {
+		t.Fatalf("expected cause to be the underlying error")
+	}
+}
+
+func TestHoist(t *testing.T) {
+	plain := fmt.Errorf("plain")
+	if Hoist(plain) != plain {
+		t.Fatalf("expected an unlocated error to be unchanged")
+	}
+	inner := At(Position{Path: "b.yaml", Line: 2}, fmt.Errorf("bad"))
+	if actual := Hoist(inner).Error(); actual != "b.yaml:2: bad" {
+		t.Fatalf("unexpected error: %s", actual)
+	}
+	err := errors.Wrap(errors.Wrap(inner, "plugin X fails configuration"),
+		"accumulating resources from 'b.yaml'")
+	expected := "b.yaml:2: accumulating resources from 'b.yaml': " +
+		"plugin X fails configuration: bad"
+	if actual := Hoist(err).Error(); actual != expected {
+		t.Fatalf("expected\n  %s\ngot\n  %s", expected, actual)
+	}
+	if errors.Cause(Hoist(err)).Error() != "bad" {
+		t.Fatalf("expected cause to be the underlying error")
+	}
+}
+
+func TestSyntaxError(t *testing.T) {
+	content := []byte(`a: 1
+---
+b:
+  c: d
+ e: f
+`)
+	err := SyntaxError("x.yaml", content)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	expected := "x.yaml:4: did not find expected key"
+	if err.Error() != expected {
+		t.Fatalf("expected %q, got %q", expected, err.Error())
+	}
+	if SyntaxError("x.yaml", []byte("a: 1\n---\nb: 2\n")) != nil {
+		t.Fatalf("expected no error")
+	}
+}
+
+func TestFilePositions(t *testing.T) {
+	f := NewFile("k.yaml", []byte(`resources:
+- a.yaml
+vars:
+- name: X
+  objref:
+    kind: Service
+- name: Y
+  objref:
+    kind: Pod
+`))
+	testCases := []struct {
+		fields   []string
+		expected string
+	}{
+		{[]string{"vars"}, "k.yaml:3:1"},
+		{[]string{"vars", "1"}, "k.yaml:7:3"},
+		{[]string{"vars", "1", "objref", "kind"}, "k.yaml:9:5"},
+		{[]string{"vars", "objref", "kind"}, "k.yaml:6:5"},
+		{[]string{"vars", "7"}, "k.yaml:3:1"},
+		{[]string{"images"}, "k.yaml"},
+	}
+	for _, tc := range testCases {
+		if actual := f.PositionOf(tc.fields...).String(); actual != tc.expected {
+			t.Errorf("%v: expected %s, got %s", tc.fields, tc.expected, actual)
+		}
+	}
+	if actual := f.PositionOfKey("kind").String(); actual != "k.yaml:6:5" {
+		t.Errorf("expected k.yaml:6:5, got %s", actual)
+	}
+	if actual := f.PositionOfKey("nope").String(); actual != "k.yaml" {
+		t.Errorf("expected k.yaml, got %s", actual)
+	}
+	if actual := NewFile("k.yaml", []byte("a: [")).PositionOf("a").String(); actual != "k.yaml" {
+		t.Errorf("expected k.yaml, got %s", actual)
+	}
+}
@@ -10,24 +10,28 @@ func _() {
 	var x [1]struct{}
 	_ = x[Unknown-0]
 	_ = x[AnnotationsTransformer-1]
-	_ = x[ConfigMapGenerator-2]
-	_ = x[HashTransformer-3]
-	_ = x[ImageTagTransformer-4]
-	_ = x[InventoryTransformer-5]
-	_ = x[LabelTransformer-6]
-	_ = x[LegacyOrderTransformer-7]
-	_ = x[NamespaceTransformer-8]
-	_ = x[PatchJson6902Transformer-9]
-	_ = x[PatchStrategicMergeTransformer-10]
-	_ = x[PatchTransformer-11]
-	_ = x[PrefixSuffixTransformer-12]
-	_ = x[ReplicaCountTransformer-13]
-	_ = x[SecretGenerator-14]
+	_ = x[ConfigHashTransformer-2]
+	_ = x[ConfigMapGenerator-3]
+	_ = x[ExclusionTransformer-4]
+	_ = x[HashTransformer-5]
+	_ = x[HelmChartInflationGenerator-6]
+	_ = x[ImageTagTransformer-7]
+	_ = x[InventoryTransformer-8]
+	_ = x[LabelTransformer-9]
+	_ = x[LegacyOrderTransformer-10]
+	_ = x[NamespaceTransformer-11]
+	_ = x[PatchJson6902Transformer-12]
+	_ = x[PatchStrategicMergeTransformer-13]
+	_ = x[PatchTransformer-14]
+	_ = x[PrefixSuffixTransformer-15]
+	_ = x[ReplacementTransformer-16]
+	_ = x[ReplicaCountTransformer-17]
+	_ = x[SecretGenerator-18]
 }
 
-const _BuiltinPluginType_name = "UnknownAnnotationsTransformerConfigMapGeneratorHashTransformerImageTagTransformerInventoryTransformerLabelTransformerLegacyOrderTransformerNamespaceTransformerPatchJson6902TransformerPatchStrategicMergeTransformerPatchTransformerPrefixSuffixTransformerReplicaCountTransformerSecretGenerator"
+const _BuiltinPluginType_name = "UnknownAnnotationsTransformerConfigHashTransformerConfigMapGeneratorExclusionTransformerHashTransformerHelmChartInflationGeneratorImageTagTransformerInventoryTransformerLabelTransformerLegacyOrderTransformerNamespaceTransformerPatchJson6902TransformerPatchStrategicMergeTransformerPatchTransformerPrefixSuffixTransformerReplacementTransformerReplicaCountTransformerSecretGenerator"
 
-var _BuiltinPluginType_index = [...]uint16{0, 7, 29, 47, 62, 81, 101, 117, 139, 159, 183, 213, 229, 252, 275, 290}
+var _BuiltinPluginType_index = [...]uint16{0, 7, 29, 50, 68, 88, 103, 130, 149, 169, 185, 207, 227, 251, 281, 297, 320, 342, 365, 380}
 
 func (i BuiltinPluginType) String() string {
 	if i < 0 || i >= BuiltinPluginType(len(_BuiltinPluginType_index)-1) {
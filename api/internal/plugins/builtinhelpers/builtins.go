@@ -14,8 +14,11 @@ type BuiltinPluginType int
 const (
 	Unknown BuiltinPluginType = iota
 	AnnotationsTransformer
+	ConfigHashTransformer
 	ConfigMapGenerator
+	ExclusionTransformer
 	HashTransformer
+	HelmChartInflationGenerator
 	ImageTagTransformer
 	InventoryTransformer
 	LabelTransformer
@@ -25,6 +28,7 @@ const (
 	PatchStrategicMergeTransformer
 	PatchTransformer
 	PrefixSuffixTransformer
+	ReplacementTransformer
 	ReplicaCountTransformer
 	SecretGenerator
 )
@@ -55,12 +59,15 @@ func GetBuiltinPluginType(n string) BuiltinPluginType {
 }
 
 var GeneratorFactories = map[BuiltinPluginType]func() resmap.GeneratorPlugin{
-	ConfigMapGenerator: builtins.NewConfigMapGeneratorPlugin,
-	SecretGenerator:    builtins.NewSecretGeneratorPlugin,
+	ConfigMapGenerator:          builtins.NewConfigMapGeneratorPlugin,
+	HelmChartInflationGenerator: builtins.NewHelmChartInflationGeneratorPlugin,
+	SecretGenerator:             builtins.NewSecretGeneratorPlugin,
 }
 
 var TransformerFactories = map[BuiltinPluginType]func() resmap.TransformerPlugin{
 	AnnotationsTransformer:         builtins.NewAnnotationsTransformerPlugin,
+	ConfigHashTransformer:          builtins.NewConfigHashTransformerPlugin,
+	ExclusionTransformer:           builtins.NewExclusionTransformerPlugin,
 	HashTransformer:                builtins.NewHashTransformerPlugin,
 	ImageTagTransformer:            builtins.NewImageTagTransformerPlugin,
 	InventoryTransformer:           builtins.NewInventoryTransformerPlugin,
@@ -71,5 +78,6 @@ var TransformerFactories = map[BuiltinPluginType]func() resmap.TransformerPlugin
 	PatchStrategicMergeTransformer: builtins.NewPatchStrategicMergeTransformerPlugin,
 	PatchTransformer:               builtins.NewPatchTransformerPlugin,
 	PrefixSuffixTransformer:        builtins.NewPrefixSuffixTransformerPlugin,
+	ReplacementTransformer:         builtins.NewReplacementTransformerPlugin,
 	ReplicaCountTransformer:        builtins.NewReplicaCountTransformerPlugin,
 }
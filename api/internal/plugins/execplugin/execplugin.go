@@ -4,19 +4,19 @@
 package execplugin
 
 import (
-	"bytes"
 	"fmt"
 	"io/ioutil"
 	"os"
-	"os/exec"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/shlex"
 
 	"github.com/pkg/errors"
 	"sigs.k8s.io/kustomize/api/resid"
 	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/yaml"
 )
@@ -42,12 +42,36 @@ type ExecPlugin struct {
 	// Plugin configuration data.
 	cfg []byte
 
+	// What kustomize reads of the plugin configuration.
+	meta configMeta
+
+	// Whether the plugin speaks the ResourceList protocol.
+	resourceList bool
+
+	// How the plugin runs.
+	opts types.ExecPluginOptions
+
+	// How long the plugin may run; zero means no limit.
+	timeout time.Duration
+
 	// PluginHelpers
 	h *resmap.PluginHelpers
 }
 
 func NewExecPlugin(p string) *ExecPlugin {
-	return &ExecPlugin{path: p}
+	return NewExecPluginWithOptions(p, nil)
+}
+
+// NewExecPluginWithOptions returns a plugin running
+// the executable at the given path with the given
+// options; nil options mean the defaults.
+func NewExecPluginWithOptions(
+	p string, opts *types.ExecPluginOptions) *ExecPlugin {
+	result := &ExecPlugin{path: p}
+	if opts != nil {
+		result.opts = *opts
+	}
+	return result
 }
 
 func (p *ExecPlugin) ErrIfNotExecutable() error {
@@ -76,9 +100,40 @@ func (p *ExecPlugin) Cfg() []byte {
 func (p *ExecPlugin) Config(h *resmap.PluginHelpers, config []byte) error {
 	p.h = h
 	p.cfg = config
+	err := yaml.Unmarshal(p.cfg, &p.meta)
+	if err != nil {
+		return errors.Wrapf(err, "reading config of plugin %s", p.path)
+	}
+	err = p.processProtocol()
+	if err != nil {
+		return err
+	}
+	err = p.processTimeout()
+	if err != nil {
+		return err
+	}
 	return p.processOptionalArgsFields()
 }
 
+// configMeta is what kustomize reads of a plugin
+// configuration, beyond the optional args fields.
+type configMeta struct {
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind,omitempty"`
+	Metadata   struct {
+		Annotations map[string]string `json:"annotations,omitempty"`
+	} `json:"metadata,omitempty"`
+}
+
+// gvk returns the Gvk of the plugin configuration.
+func (p *ExecPlugin) gvk() resid.Gvk {
+	gvk := resid.Gvk{Version: p.meta.APIVersion, Kind: p.meta.Kind}
+	if i := strings.LastIndex(gvk.Version, "/"); i >= 0 {
+		gvk.Group, gvk.Version = gvk.Version[:i], gvk.Version[i+1:]
+	}
+	return gvk
+}
+
 type argsConfig struct {
 	ArgsOneLiner string `json:"argsOneLiner,omitempty" yaml:"argsOneLiner,omitempty"`
 	ArgsFromFile string `json:"argsFromFile,omitempty" yaml:"argsFromFile,omitempty"`
@@ -106,6 +161,9 @@ func (p *ExecPlugin) processOptionalArgsFields() error {
 }
 
 func (p *ExecPlugin) Generate() (resmap.ResMap, error) {
+	if p.resourceList {
+		return p.generateResourceList()
+	}
 	output, err := p.invokePlugin(nil)
 	if err != nil {
 		return nil, err
@@ -118,6 +176,10 @@ func (p *ExecPlugin) Generate() (resmap.ResMap, error) {
 }
 
 func (p *ExecPlugin) Transform(rm resmap.ResMap) error {
+	if p.resourceList {
+		return p.transformResourceList(rm)
+	}
+
 	// add ResIds as annotations to all objects so that we can add them back
 	inputRM, err := p.getResMapWithIdAnnotation(rm)
 	if err != nil {
@@ -133,7 +195,7 @@ func (p *ExecPlugin) Transform(rm resmap.ResMap) error {
 	// invoke the plugin with resources as the input
 	output, err := p.invokePlugin(resources)
 	if err != nil {
-		return fmt.Errorf("%v %s", err, string(output))
+		return err
 	}
 
 	// update the original ResMap based on the output
@@ -159,30 +221,13 @@ func (p *ExecPlugin) invokePlugin(input []byte) ([]byte, error) {
 		return nil, errors.Wrap(
 			err, "closing plugin config file "+f.Name())
 	}
-	//nolint:gosec
-	cmd := exec.Command(
-		p.path, append([]string{f.Name()}, p.args...)...)
-	cmd.Env = p.getEnv()
-	cmd.Stdin = bytes.NewReader(input)
-	cmd.Stderr = os.Stderr
-	if _, err := os.Stat(p.h.Loader().Root()); err == nil {
-		cmd.Dir = p.h.Loader().Root()
-	}
-	result, err := cmd.Output()
+	defer os.Remove(f.Name())
+	result, err := p.run(append([]string{f.Name()}, p.args...), input)
 	if err != nil {
-		return nil, errors.Wrapf(
-			err, "failure in plugin configured via %s; %v",
-			f.Name(), err.Error())
+		return result, errors.Wrapf(
+			err, "failure in plugin configured via %s", f.Name())
 	}
-	return result, os.Remove(f.Name())
-}
-
-func (p *ExecPlugin) getEnv() []string {
-	env := os.Environ()
-	env = append(env,
-		"KUSTOMIZE_PLUGIN_CONFIG_STRING="+string(p.cfg),
-		"KUSTOMIZE_PLUGIN_CONFIG_ROOT="+p.h.Loader().Root())
-	return env
+	return result, nil
 }
 
 // Returns a new copy of the given ResMap with the ResIds annotated in each Resource
@@ -244,6 +289,12 @@ func (p *ExecPlugin) updateResMapValues(output []byte, rm resmap.ResMap) error {
 // updateResourceOptions updates the generator options for each resource in the
 // given ResMap based on plugin provided annotations.
 func (p *ExecPlugin) UpdateResourceOptions(rm resmap.ResMap) (resmap.ResMap, error) {
+	return UpdateResourceOptions(rm)
+}
+
+// UpdateResourceOptions sets the generator options of the
+// resources generated by a plugin from their annotations.
+func UpdateResourceOptions(rm resmap.ResMap) (resmap.ResMap, error) {
 	for _, r := range rm.Resources() {
 		// Disable name hashing by default and require plugin to explicitly
 		// request it for each resource.
@@ -272,3 +323,31 @@ func (p *ExecPlugin) UpdateResourceOptions(rm resmap.ResMap) (resmap.ResMap, err
 	}
 	return rm, nil
 }
+
+// MergeTransformed updates the given ResMap with the resources
+// a transformer emitted, however it tells them apart.  Those it
+// kept, given by the resources of the ResMap they replace, are
+// updated in place, the resources it dropped are removed, and
+// those it added are appended.  The source names the
+// transformer in errors.
+func MergeTransformed(
+	rm resmap.ResMap, kept map[*resource.Resource]*resource.Resource,
+	added []*resource.Resource, source string) error {
+	for _, res := range rm.Resources() {
+		r, ok := kept[res]
+		if !ok {
+			if err := rm.Remove(res.CurId()); err != nil {
+				return err
+			}
+			continue
+		}
+		res.Kunstructured = r.Kunstructured
+	}
+	for _, r := range added {
+		if err := rm.Append(r); err != nil {
+			return errors.Wrapf(
+				err, "appending resource added by %s", source)
+		}
+	}
+	return nil
+}
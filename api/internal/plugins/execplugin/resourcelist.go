@@ -0,0 +1,233 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package execplugin
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/resid"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// ProtocolAnnotation, on the config of an exec plugin,
+	// names the protocol used to talk to the plugin.
+	ProtocolAnnotation = "kustomize.config.k8s.io/exec-protocol"
+
+	// ProtocolResourceList is the protocol of kyaml functions.
+	// The plugin reads a ResourceList on stdin, holding its
+	// config as the functionConfig, and the resources to
+	// transform, if any, as the items.  It writes a ResourceList
+	// on stdout, holding the resulting resources as the items,
+	// along with any results.  A transformer may add, change
+	// and remove items.
+	ProtocolResourceList = "ResourceList"
+
+	// Severities of results.
+	severityError   = "error"
+	severityWarning = "warning"
+)
+
+// resourceList is the wire format of the ResourceList protocol.
+type resourceList struct {
+	APIVersion     string                   `json:"apiVersion,omitempty"`
+	Kind           string                   `json:"kind,omitempty"`
+	FunctionConfig map[string]interface{}   `json:"functionConfig,omitempty"`
+	Items          []map[string]interface{} `json:"items"`
+	Results        []result                 `json:"results,omitempty"`
+}
+
+// result is a message from a plugin about the resources.
+type result struct {
+	Message     string             `json:"message"`
+	Severity    string             `json:"severity,omitempty"`
+	ResourceRef *resultResourceRef `json:"resourceRef,omitempty"`
+	Field       *resultPath        `json:"field,omitempty"`
+	File        *resultPath        `json:"file,omitempty"`
+}
+
+// resultResourceRef identifies the resource a result is about.
+type resultResourceRef struct {
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind,omitempty"`
+	Name       string `json:"name,omitempty"`
+	Namespace  string `json:"namespace,omitempty"`
+}
+
+// resultPath is the path of a field or file a result is about.
+type resultPath struct {
+	Path string `json:"path,omitempty"`
+}
+
+func (r result) String() string {
+	var sb strings.Builder
+	if r.ResourceRef != nil {
+		ref := r.ResourceRef
+		sb.WriteString(ref.Kind + "/" + ref.Name)
+		if ref.Namespace != "" {
+			sb.WriteString(" in namespace " + ref.Namespace)
+		}
+		sb.WriteString(": ")
+	}
+	if r.File != nil && r.File.Path != "" {
+		sb.WriteString(r.File.Path + ": ")
+	}
+	if r.Field != nil && r.Field.Path != "" {
+		sb.WriteString(r.Field.Path + ": ")
+	}
+	sb.WriteString(r.Message)
+	return sb.String()
+}
+
+// processProtocol reads the protocol from the plugin config.
+func (p *ExecPlugin) processProtocol() error {
+	switch protocol := p.meta.Metadata.Annotations[ProtocolAnnotation]; protocol {
+	case "":
+		p.resourceList = false
+	case ProtocolResourceList:
+		p.resourceList = true
+	default:
+		return fmt.Errorf(
+			"unknown %s '%s' for plugin %s; expected %s",
+			ProtocolAnnotation, protocol, p.path, ProtocolResourceList)
+	}
+	return nil
+}
+
+// generateResourceList runs a generator speaking
+// the ResourceList protocol.
+func (p *ExecPlugin) generateResourceList() (resmap.ResMap, error) {
+	items, err := p.invokeResourceListPlugin(nil)
+	if err != nil {
+		return nil, err
+	}
+	rm := resmap.New()
+	for _, item := range items {
+		err = rm.Append(p.h.ResmapFactory().RF().FromMap(item))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return p.UpdateResourceOptions(rm)
+}
+
+// transformResourceList runs a transformer speaking the
+// ResourceList protocol, merging the items it emits into
+// the ResMap, as MergeTransformed does.  The items it
+// keeps are known by their id annotation.
+func (p *ExecPlugin) transformResourceList(rm resmap.ResMap) error {
+	inputRM, err := p.getResMapWithIdAnnotation(rm)
+	if err != nil {
+		return err
+	}
+	var items []map[string]interface{}
+	for _, r := range inputRM.Resources() {
+		items = append(items, r.Map())
+	}
+	items, err = p.invokeResourceListPlugin(items)
+	if err != nil {
+		return err
+	}
+
+	kept := make(map[*resource.Resource]*resource.Resource)
+	var added []*resource.Resource
+	for _, item := range items {
+		r := p.h.ResmapFactory().RF().FromMap(item)
+		annotations := r.GetAnnotations()
+		idString, ok := annotations[idAnnotation]
+		if !ok {
+			added = append(added, r)
+			continue
+		}
+		id := resid.ResId{}
+		err = yaml.Unmarshal([]byte(idString), &id)
+		if err != nil {
+			return err
+		}
+		res, err := rm.GetByCurrentId(id)
+		if err != nil {
+			return fmt.Errorf("unable to find unique match to %s", id.String())
+		}
+		delete(annotations, idAnnotation)
+		if len(annotations) == 0 {
+			annotations = nil
+		}
+		r.SetAnnotations(annotations)
+		kept[res] = r
+	}
+	return MergeTransformed(rm, kept, added, "plugin "+p.path)
+}
+
+// invokeResourceListPlugin runs the plugin with a ResourceList
+// holding the given items, returning the items of the
+// ResourceList it emits.  The plugin fails if it exits with
+// an error, or emits results of error severity.
+func (p *ExecPlugin) invokeResourceListPlugin(
+	items []map[string]interface{}) ([]map[string]interface{}, error) {
+	input := resourceList{
+		APIVersion: kio.ResourceListAPIVersion,
+		Kind:       kio.ResourceListKind,
+		Items:      items,
+	}
+	err := yaml.Unmarshal(p.cfg, &input.FunctionConfig)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading config of plugin %s", p.path)
+	}
+	if input.Items == nil {
+		input.Items = []map[string]interface{}{}
+	}
+	in, err := yaml.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+	out, errRun := p.run(p.args, in)
+
+	var output resourceList
+	errOut := yaml.Unmarshal(out, &output)
+	if errOut == nil {
+		if err = p.checkResults(output.Results); err != nil {
+			return nil, err
+		}
+	}
+	if errRun != nil {
+		return nil, errRun
+	}
+	if errOut != nil {
+		return nil, errors.Wrapf(
+			errOut, "reading ResourceList from plugin %s", p.path)
+	}
+	if output.Kind != kio.ResourceListKind {
+		return nil, fmt.Errorf(
+			"expected a %s from plugin %s, got kind '%s'",
+			kio.ResourceListKind, p.path, output.Kind)
+	}
+	return output.Items, nil
+}
+
+// checkResults logs the results of the plugin, returning
+// an error listing those of error severity, if any.
+func (p *ExecPlugin) checkResults(results []result) error {
+	var errs []string
+	for _, r := range results {
+		switch r.Severity {
+		case severityError:
+			errs = append(errs, r.String())
+		case severityWarning:
+			log.Printf("warning: plugin %s: %s", p.path, r)
+		default:
+			log.Printf("plugin %s: %s", p.path, r)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf(
+		"plugin %s failed:\n  %s", p.path, strings.Join(errs, "\n  "))
+}
@@ -0,0 +1,238 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package execplugin_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+	. "sigs.k8s.io/kustomize/api/internal/plugins/execplugin"
+	"sigs.k8s.io/kustomize/api/k8sdeps/kunstruct"
+	fLdr "sigs.k8s.io/kustomize/api/loader"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	valtest_test "sigs.k8s.io/kustomize/api/testutils/valtest"
+)
+
+const greeterConfig = `
+apiVersion: someteam.example.com/v1
+kind: Greeter
+metadata:
+  name: greeter
+  annotations:
+    kustomize.config.k8s.io/exec-protocol: ResourceList
+greeting: hello
+`
+
+// makeResourceListPlugin writes the given script to a temporary
+// directory, and returns an ExecPlugin running it, configured
+// with the given config.
+func makeResourceListPlugin(
+	t *testing.T, script, config string) (*ExecPlugin, *resmap.Factory, func()) {
+	dir, err := ioutil.TempDir("", "kustomize-execplugin-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "plugin")
+	err = ioutil.WriteFile(path, []byte("#!/bin/bash\n"+script), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ldr, err := fLdr.NewLoader(
+		fLdr.RestrictionRootOnly, filesys.Separator, filesys.MakeFsInMemory())
+	if err != nil {
+		t.Fatal(err)
+	}
+	rf := resmap.NewFactory(
+		resource.NewFactory(
+			kunstruct.NewKunstructuredFactoryImpl()), nil)
+	p := NewExecPlugin(path)
+	err = p.Config(
+		resmap.NewPluginHelpers(ldr, valtest_test.MakeFakeValidator(), rf),
+		[]byte(config))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return p, rf, func() { os.RemoveAll(dir) }
+}
+
+func TestResourceListGenerator(t *testing.T) {
+	p, _, cleanup := makeResourceListPlugin(t, `
+greeting=$(sed -n 's/^  greeting: //p')
+cat <<EOF
+apiVersion: config.kubernetes.io/v1alpha1
+kind: ResourceList
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: greeting
+    annotations:
+      kustomize.config.k8s.io/needs-hash: "true"
+  data:
+    greeting: $greeting
+results:
+- message: greeting generated
+  severity: info
+EOF
+`, greeterConfig)
+	defer cleanup()
+	rm, err := p.Generate()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rm.Size() != 1 {
+		t.Fatalf("expected one resource, got %d", rm.Size())
+	}
+	r := rm.GetByIndex(0)
+	if !r.NeedHashSuffix() {
+		t.Fatalf("expected the resource to need a hash suffix")
+	}
+	yml, err := r.AsYAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `apiVersion: v1
+data:
+  greeting: hello
+kind: ConfigMap
+metadata:
+  name: greeting
+`
+	if string(yml) != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, yml)
+	}
+}
+
+func TestResourceListTransformer(t *testing.T) {
+	// Drops the item named 'dropped', changes the
+	// others, and adds an item named 'added'.
+	p, rf, cleanup := makeResourceListPlugin(t, `
+echo "kind: ResourceList"
+echo "items:"
+echo "- {apiVersion: v1, kind: ConfigMap, metadata: {name: added}}"
+awk '
+function flush() {
+  if (block !~ /name: dropped/) printf "%s", block
+  block = ""
+}
+/^items:/ { on = 1; next }
+/^[^ -]/ { if (on) flush(); on = 0; next }
+on && /^- / { flush() }
+on { block = block $0 "\n" }
+END { flush() }
+' | sed 's/value: old/value: new/'
+`, greeterConfig)
+	defer cleanup()
+	rm, err := rf.NewResMapFromBytes([]byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: kept
+  annotations:
+    owner: team
+data:
+  value: old
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: dropped
+data:
+  value: old
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = p.Transform(rm)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	yml, err := rm.AsYaml()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `apiVersion: v1
+data:
+  value: new
+kind: ConfigMap
+metadata:
+  annotations:
+    owner: team
+  name: kept
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: added
+`
+	if string(yml) != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, yml)
+	}
+}
+
+func TestResourceListErrorResults(t *testing.T) {
+	p, _, cleanup := makeResourceListPlugin(t, `
+cat <<EOF
+apiVersion: config.kubernetes.io/v1alpha1
+kind: ResourceList
+items: []
+results:
+- message: replicas must be at most 3
+  severity: error
+  resourceRef:
+    kind: Deployment
+    name: web
+  field:
+    path: spec.replicas
+- message: no owner
+  severity: warning
+EOF
+exit 1
+`, greeterConfig)
+	defer cleanup()
+	err := p.Transform(resmap.New())
+	if err == nil || !strings.Contains(err.Error(),
+		"failed:\n  Deployment/web: spec.replicas: replicas must be at most 3") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(err.Error(), "no owner") {
+		t.Fatalf("expected warnings to be left out of the error: %v", err)
+	}
+}
+
+func TestResourceListBadOutput(t *testing.T) {
+	p, _, cleanup := makeResourceListPlugin(t, `
+echo "kind: ConfigMap"
+`, greeterConfig)
+	defer cleanup()
+	_, err := p.Generate()
+	if err == nil || !strings.Contains(
+		err.Error(), "expected a ResourceList from plugin") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestUnknownProtocol(t *testing.T) {
+	p := NewExecPlugin("/plugin")
+	err := p.Config(nil, []byte(strings.Replace(
+		greeterConfig, "ResourceList", "Smoke", 1)))
+	if err == nil || !strings.Contains(err.Error(),
+		"unknown kustomize.config.k8s.io/exec-protocol 'Smoke'") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestMalformedConfig(t *testing.T) {
+	p := NewExecPlugin("/plugin")
+	err := p.Config(nil, []byte(greeterConfig+"metadata: [\n"))
+	if err == nil || !strings.Contains(err.Error(),
+		"reading config of plugin /plugin") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
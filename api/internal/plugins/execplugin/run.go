@@ -0,0 +1,231 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package execplugin
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"sigs.k8s.io/kustomize/api/resid"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+const (
+	// TimeoutAnnotation, on the config of an exec plugin,
+	// is how long the plugin may run, e.g. "30s".  It can't
+	// extend the timeout kustomize is run with.
+	TimeoutAnnotation = "kustomize.config.k8s.io/exec-timeout"
+
+	// stderrTailSize is how much of the end of a
+	// plugin's stderr goes into its errors.
+	stderrTailSize = 2048
+)
+
+// processTimeout reads the timeout from the plugin config.
+func (p *ExecPlugin) processTimeout() error {
+	p.timeout = p.opts.Timeout
+	s, ok := p.meta.Metadata.Annotations[TimeoutAnnotation]
+	if !ok {
+		return nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return fmt.Errorf(
+			"invalid %s '%s' for plugin %s; expected a positive duration, e.g. 30s",
+			TimeoutAnnotation, s, p.path)
+	}
+	if p.timeout == 0 || d < p.timeout {
+		p.timeout = d
+	}
+	return nil
+}
+
+// RunCommand runs the executable at the given path with the
+// given args, as the exec plugins of the build are run, i.e.
+// with their limits and environment, and in the root of the
+// plugin's loader.  It's the resmap.CommandRunner of builds.
+func RunCommand(
+	h *resmap.PluginHelpers, gvk resid.Gvk,
+	path string, args []string) ([]byte, error) {
+	apiVersion := gvk.Version
+	if gvk.Group != "" {
+		apiVersion = gvk.Group + "/" + apiVersion
+	}
+	p := NewExecPluginWithOptions(path, &h.PluginConfig().ExecpOptions)
+	err := p.Config(h, []byte(fmt.Sprintf(
+		"apiVersion: %s\nkind: %s\n", apiVersion, gvk.Kind)))
+	if err != nil {
+		return nil, err
+	}
+	return p.run(args, nil)
+}
+
+// run runs the plugin with the given args and stdin,
+// within its limits, returning what it writes to stdout.
+// If it fails, the error is an ErrExecPlugin.
+func (p *ExecPlugin) run(args []string, input []byte) ([]byte, error) {
+	cmd, err := p.command(args)
+	if err != nil {
+		return nil, err
+	}
+	var stdout bytes.Buffer
+	stderr := &tailWriter{max: stderrTailSize}
+	cmd.Env = p.getEnv()
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = io.MultiWriter(os.Stderr, stderr)
+	if _, err := os.Stat(p.h.Loader().Root()); err == nil {
+		cmd.Dir = p.h.Loader().Root()
+	}
+	startInOwnGroup(cmd)
+	if err = cmd.Start(); err != nil {
+		return nil, p.newErr(err.Error(), "")
+	}
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+	var timeout <-chan time.Time
+	if p.timeout > 0 {
+		timer := time.NewTimer(p.timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case err = <-done:
+	case <-timeout:
+		killGroup(cmd)
+		// Don't wait for the output; a child that
+		// left the group may still hold its pipes.
+		return nil, p.newErr(
+			fmt.Sprintf("timed out after %s", p.timeout), stderr.String())
+	}
+	if err != nil {
+		return stdout.Bytes(), p.newErr(p.exitReason(err), stderr.String())
+	}
+	return stdout.Bytes(), nil
+}
+
+// command returns the command running the plugin with the
+// given args.  Resource limits are set by a shell, which
+// then execs the plugin.
+func (p *ExecPlugin) command(args []string) (*exec.Cmd, error) {
+	if p.opts.MemoryLimit <= 0 && p.opts.CPULimit <= 0 {
+		//nolint:gosec
+		return exec.Command(p.path, args...), nil
+	}
+	if runtime.GOOS == "windows" {
+		return nil, fmt.Errorf(
+			"resource limits on exec plugins aren't supported on %s",
+			runtime.GOOS)
+	}
+	var limits []string
+	if p.opts.MemoryLimit > 0 {
+		// ulimit -v is in KiB.
+		limits = append(limits, fmt.Sprintf(
+			"ulimit -v %d", (p.opts.MemoryLimit+1023)/1024))
+	}
+	if p.opts.CPULimit > 0 {
+		// ulimit -t is in whole seconds.
+		limits = append(limits, fmt.Sprintf(
+			"ulimit -t %d", int64((p.opts.CPULimit+time.Second-1)/time.Second)))
+	}
+	script := strings.Join(limits, " && ") + ` && exec "$0" "$@"`
+	//nolint:gosec
+	return exec.Command(
+		"/bin/sh", append([]string{"-c", script, p.path}, args...)...), nil
+}
+
+// exitReason says why a plugin that ran failed.
+func (p *ExecPlugin) exitReason(err error) string {
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok {
+		return err.Error()
+	}
+	ws, ok := exitErr.Sys().(syscall.WaitStatus)
+	if !ok || !ws.Signaled() {
+		return err.Error()
+	}
+	reason := "killed by signal " + ws.Signal().String()
+	if p.opts.MemoryLimit > 0 || p.opts.CPULimit > 0 {
+		reason += ", perhaps having exceeded its resource limits"
+	}
+	return reason
+}
+
+func (p *ExecPlugin) newErr(reason, stderr string) error {
+	return &types.ErrExecPlugin{
+		Gvk:    p.gvk(),
+		Path:   p.path,
+		Reason: reason,
+		Stderr: stderr,
+	}
+}
+
+// getEnv returns the environment of the plugin, holding the
+// allowed variables of kustomize's environment, and the
+// plugin's config.
+func (p *ExecPlugin) getEnv() []string {
+	allowed := p.opts.EnvAllowList
+	if allowed == nil {
+		allowed = types.DefaultExecPluginEnvAllowList
+	}
+	var env []string
+	for _, kv := range os.Environ() {
+		name := kv
+		if i := strings.Index(kv, "="); i >= 0 {
+			name = kv[:i]
+		}
+		if isAllowed(name, allowed) {
+			env = append(env, kv)
+		}
+	}
+	env = append(env,
+		"KUSTOMIZE_PLUGIN_CONFIG_STRING="+string(p.cfg),
+		"KUSTOMIZE_PLUGIN_CONFIG_ROOT="+p.h.Loader().Root())
+	return env
+}
+
+func isAllowed(name string, allowed []string) bool {
+	for _, a := range allowed {
+		if strings.HasSuffix(a, "*") {
+			if strings.HasPrefix(name, strings.TrimSuffix(a, "*")) {
+				return true
+			}
+		} else if name == a {
+			return true
+		}
+	}
+	return false
+}
+
+// tailWriter keeps the last max bytes written to it.
+type tailWriter struct {
+	mu  sync.Mutex
+	max int
+	buf []byte
+}
+
+func (w *tailWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = append(w.buf, b...)
+	if len(w.buf) > w.max {
+		w.buf = w.buf[len(w.buf)-w.max:]
+	}
+	return len(b), nil
+}
+
+func (w *tailWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return strings.TrimSpace(string(w.buf))
+}
@@ -0,0 +1,20 @@
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package execplugin
+
+import (
+	"os/exec"
+)
+
+// startInOwnGroup does nothing; process groups
+// are only used on unix.
+func startInOwnGroup(cmd *exec.Cmd) {}
+
+// killGroup kills the started command.  Its
+// children, if any, keep running.
+func killGroup(cmd *exec.Cmd) {
+	cmd.Process.Kill()
+}
@@ -0,0 +1,289 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package execplugin_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+	. "sigs.k8s.io/kustomize/api/internal/plugins/execplugin"
+	"sigs.k8s.io/kustomize/api/k8sdeps/kunstruct"
+	fLdr "sigs.k8s.io/kustomize/api/loader"
+	"sigs.k8s.io/kustomize/api/resid"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	valtest_test "sigs.k8s.io/kustomize/api/testutils/valtest"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+const printerConfig = `
+apiVersion: someteam.example.com/v1
+kind: Printer
+metadata:
+  name: printer
+`
+
+// makePlugin writes the given script to a temporary directory,
+// and returns an ExecPlugin running it with the given options,
+// along with the error configuring it with the given config.
+func makePlugin(
+	t *testing.T, opts *types.ExecPluginOptions,
+	script, config string) (*ExecPlugin, func(), error) {
+	dir, err := ioutil.TempDir("", "kustomize-execplugin-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "plugin")
+	err = ioutil.WriteFile(path, []byte("#!/bin/bash\n"+script), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ldr, err := fLdr.NewLoader(
+		fLdr.RestrictionRootOnly, filesys.Separator, filesys.MakeFsInMemory())
+	if err != nil {
+		t.Fatal(err)
+	}
+	rf := resmap.NewFactory(
+		resource.NewFactory(
+			kunstruct.NewKunstructuredFactoryImpl()), nil)
+	p := NewExecPluginWithOptions(path, opts)
+	err = p.Config(
+		resmap.NewPluginHelpers(ldr, valtest_test.MakeFakeValidator(), rf),
+		[]byte(config))
+	return p, func() { os.RemoveAll(dir) }, err
+}
+
+// configMapScript returns a script generating
+// a ConfigMap with the given data.
+func configMapScript(data string) string {
+	return `
+cat <<EOF
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: printed
+data:
+` + data + `
+EOF
+`
+}
+
+func generateYaml(t *testing.T, p *ExecPlugin) string {
+	rm, err := p.Generate()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	yml, err := rm.AsYaml()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(yml)
+}
+
+func TestTimeout(t *testing.T) {
+	testCases := map[string]struct {
+		opts   types.ExecPluginOptions
+		config string
+		reason string
+	}{
+		"global": {
+			opts:   types.ExecPluginOptions{Timeout: 200 * time.Millisecond},
+			config: printerConfig,
+			reason: "timed out after 200ms",
+		},
+		"per-plugin": {
+			config: printerConfig + `  annotations:
+    kustomize.config.k8s.io/exec-timeout: 300ms
+`,
+			reason: "timed out after 300ms",
+		},
+		"per-plugin, capped by global": {
+			opts: types.ExecPluginOptions{Timeout: 200 * time.Millisecond},
+			config: printerConfig + `  annotations:
+    kustomize.config.k8s.io/exec-timeout: 1h
+`,
+			reason: "timed out after 200ms",
+		},
+	}
+	for name, tc := range testCases {
+		opts := tc.opts
+		p, cleanup, err := makePlugin(t, &opts, `
+echo "starting to hang" >&2
+sleep 10 &
+echo $! > "$(dirname "$0")/sleep.pid"
+wait
+`, tc.config)
+		defer cleanup()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		start := time.Now()
+		_, err = p.Generate()
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("%s: took %s to time out", name, elapsed)
+		}
+		e, ok := types.AsErrExecPlugin(err)
+		if !ok {
+			t.Fatalf("%s: expected an ErrExecPlugin, got %v", name, err)
+		}
+		if e.Reason != tc.reason || e.Stderr != "starting to hang" {
+			t.Errorf("%s: unexpected error: %v", name, err)
+		}
+		pid, err := ioutil.ReadFile(
+			filepath.Join(filepath.Dir(p.Path()), "sleep.pid"))
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if isRunning(t, strings.TrimSpace(string(pid))) {
+			t.Errorf("%s: the plugin's sleep is still running", name)
+		}
+	}
+}
+
+// isRunning reports whether the process with the given pid is
+// still running a second after its group was killed.  Zombies,
+// killed but not yet reaped by init, don't count.
+func isRunning(t *testing.T, pid string) bool {
+	n, err := strconv.Atoi(pid)
+	if err != nil {
+		t.Fatalf("bad pid '%s'", pid)
+	}
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); {
+		proc, err := os.FindProcess(n)
+		if err != nil || proc.Signal(syscall.Signal(0)) != nil {
+			return false
+		}
+		stat, err := ioutil.ReadFile("/proc/" + pid + "/stat")
+		if err == nil && strings.Contains(string(stat), ") Z ") {
+			return false
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return true
+}
+
+func TestInvalidTimeout(t *testing.T) {
+	_, cleanup, err := makePlugin(t, nil, "", printerConfig+`  annotations:
+    kustomize.config.k8s.io/exec-timeout: soon
+`)
+	defer cleanup()
+	if err == nil || !strings.Contains(err.Error(),
+		"invalid kustomize.config.k8s.io/exec-timeout 'soon'") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestErrExecPlugin(t *testing.T) {
+	p, cleanup, err := makePlugin(t, nil, `
+echo "no resources to print" >&2
+exit 3
+`, printerConfig)
+	defer cleanup()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = p.Generate()
+	e, ok := types.AsErrExecPlugin(err)
+	if !ok {
+		t.Fatalf("expected an ErrExecPlugin, got %v", err)
+	}
+	expected := resid.Gvk{
+		Group: "someteam.example.com", Version: "v1", Kind: "Printer"}
+	if e.Gvk != expected || e.Path != p.Path() ||
+		e.Reason != "exit status 3" || e.Stderr != "no resources to print" {
+		t.Fatalf("unexpected error: %#v", e)
+	}
+	for _, s := range []string{
+		"failure in plugin configured via",
+		"exec plugin someteam.example.com/v1/Printer",
+		"failed: exit status 3",
+		"stderr ends with:\nno resources to print",
+	} {
+		if !strings.Contains(err.Error(), s) {
+			t.Errorf("expected '%s' in error: %v", s, err)
+		}
+	}
+}
+
+func TestEnvAllowList(t *testing.T) {
+	for name, value := range map[string]string{
+		"KUSTOMIZE_TEST_ALLOWED":  "yes",
+		"KUSTOMIZE_TEST_OTHER":    "yes",
+		"UNRELATED_TEST_VARIABLE": "yes",
+	} {
+		os.Setenv(name, value)
+		defer os.Unsetenv(name)
+	}
+	script := configMapScript(`  allowed: "${KUSTOMIZE_TEST_ALLOWED:-no}"
+  config: "${KUSTOMIZE_PLUGIN_CONFIG_STRING:+yes}"
+  other: "${KUSTOMIZE_TEST_OTHER:-no}"
+  path: "${PATH:+yes}"
+  unrelated: "${UNRELATED_TEST_VARIABLE:-no}"`)
+	testCases := map[string]struct {
+		allowList []string
+		expected  string
+	}{
+		"default": {
+			expected: `data:
+  allowed: "yes"
+  config: "yes"
+  other: "yes"
+  path: "yes"
+  unrelated: "no"
+`,
+		},
+		"given": {
+			allowList: []string{"PATH", "KUSTOMIZE_TEST_ALLOWED"},
+			expected: `data:
+  allowed: "yes"
+  config: "yes"
+  other: "no"
+  path: "yes"
+  unrelated: "no"
+`,
+		},
+	}
+	for name, tc := range testCases {
+		p, cleanup, err := makePlugin(t,
+			&types.ExecPluginOptions{EnvAllowList: tc.allowList},
+			script, printerConfig)
+		defer cleanup()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if yml := generateYaml(t, p); !strings.HasPrefix(yml, "apiVersion: v1\n"+tc.expected) {
+			t.Errorf("%s: expected\n%s\ngot\n%s", name, tc.expected, yml)
+		}
+	}
+}
+
+func TestResourceLimits(t *testing.T) {
+	p, cleanup, err := makePlugin(t,
+		&types.ExecPluginOptions{
+			MemoryLimit: 1 << 30,
+			CPULimit:    1500 * time.Millisecond,
+		},
+		configMapScript(`  args: "$#"
+  cpu: "$(ulimit -t)"
+  memory: "$(ulimit -v)"`), printerConfig)
+	defer cleanup()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `data:
+  args: "1"
+  cpu: "2"
+  memory: "1048576"
+`
+	if yml := generateYaml(t, p); !strings.HasPrefix(yml, "apiVersion: v1\n"+expected) {
+		t.Fatalf("expected\n%s\ngot\n%s", expected, yml)
+	}
+}
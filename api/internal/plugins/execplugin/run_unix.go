@@ -0,0 +1,25 @@
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package execplugin
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// startInOwnGroup makes the command start in a
+// process group of its own, whose id is its pid.
+func startInOwnGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// killGroup kills the process group of the started
+// command, so that no child of the plugin outlives it.
+func killGroup(cmd *exec.Cmd) {
+	if err := syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL); err != nil {
+		cmd.Process.Kill()
+	}
+}
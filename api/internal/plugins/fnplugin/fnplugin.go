@@ -0,0 +1,250 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package fnplugin runs kyaml functions, containers or
+// starlark scripts named by the config.kubernetes.io/function
+// annotation of a plugin config, as generators and
+// transformers.
+package fnplugin
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/internal/plugins/execplugin"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/kio/filters"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+	"sigs.k8s.io/kustomize/kyaml/starlark"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	// idAnnotation tracks the resources given to
+	// a function through to its output.
+	idAnnotation = "kustomize.config.k8s.io/fn-id"
+
+	defaultNetworkName = "bridge"
+)
+
+// GetFunctionSpec returns the function spec of the
+// given plugin config, or nil if it hasn't one.
+func GetFunctionSpec(res *resource.Resource) *filters.FunctionSpec {
+	y, err := res.AsYAML()
+	if err != nil {
+		return nil
+	}
+	n, err := yaml.Parse(string(y))
+	if err != nil {
+		return nil
+	}
+	return filters.GetFunctionSpec(n)
+}
+
+// FnPlugin is a generator and transformer running a function.
+type FnPlugin struct {
+	h    *resmap.PluginHelpers
+	opts *types.FnPluginLoadingOptions
+	spec *filters.FunctionSpec
+	cfg  *yaml.RNode
+
+	// name of the function, for messages.
+	name string
+
+	// filter runs the function.
+	filter kio.Filter
+}
+
+// NewFnPlugin returns a plugin running the function
+// with the given spec, per the given options.
+func NewFnPlugin(
+	opts *types.FnPluginLoadingOptions,
+	spec *filters.FunctionSpec) *FnPlugin {
+	return &FnPlugin{opts: opts, spec: spec}
+}
+
+// ErrIfNotEnabled returns an error if the
+// options don't allow running the function.
+func (p *FnPlugin) ErrIfNotEnabled() error {
+	switch {
+	case p.spec.Container.Image != "":
+		if !p.opts.EnableContainers {
+			return fmt.Errorf(
+				"container functions disabled; unable to run image %s",
+				p.spec.Container.Image)
+		}
+		if p.spec.Container.Network.Required && !p.opts.Network {
+			return fmt.Errorf(
+				"function in image %s requires network access, "+
+					"which isn't allowed", p.spec.Container.Image)
+		}
+	case p.spec.Starlark.Path != "":
+		if !p.opts.EnableStar {
+			return fmt.Errorf(
+				"starlark functions disabled; unable to run script %s",
+				p.spec.Starlark.Path)
+		}
+	default:
+		return errors.New(
+			"function names neither a container image nor a starlark script")
+	}
+	return nil
+}
+
+func (p *FnPlugin) Config(h *resmap.PluginHelpers, config []byte) error {
+	p.h = h
+	cfg, err := yaml.Parse(string(config))
+	if err != nil {
+		return err
+	}
+	p.cfg = cfg
+	if p.spec.Container.Image != "" {
+		p.name = p.spec.Container.Image
+		p.filter = p.containerFilter()
+		return nil
+	}
+	p.name = p.spec.Starlark.Path
+	program, err := h.Loader().Load(p.spec.Starlark.Path)
+	if err != nil {
+		return errors.Wrapf(
+			err, "loading starlark script %s", p.spec.Starlark.Path)
+	}
+	name := p.spec.Starlark.Name
+	if name == "" {
+		name = p.spec.Starlark.Path
+	}
+	p.filter = &starlark.Filter{
+		Name: name, Program: string(program), FunctionConfig: cfg}
+	return nil
+}
+
+// containerFilter returns a filter running the
+// container, with the mounts and network of the options.
+func (p *FnPlugin) containerFilter() kio.Filter {
+	c := &filters.ContainerFilter{
+		Image:       p.spec.Container.Image,
+		Config:      p.cfg,
+		GlobalScope: true,
+	}
+	if p.spec.Container.Network.Required {
+		c.Network = p.opts.NetworkName
+		if c.Network == "" {
+			c.Network = defaultNetworkName
+		}
+	}
+	for _, m := range p.opts.Mounts {
+		c.StorageMounts = append(
+			c.StorageMounts, filters.StringToStorageMount(m))
+	}
+	return c
+}
+
+func (p *FnPlugin) Generate() (resmap.ResMap, error) {
+	nodes, err := p.run(nil)
+	if err != nil {
+		return nil, err
+	}
+	rm := resmap.New()
+	for _, n := range nodes {
+		r, err := p.toResource(n)
+		if err != nil {
+			return nil, err
+		}
+		if err = rm.Append(r); err != nil {
+			return nil, err
+		}
+	}
+	return execplugin.UpdateResourceOptions(rm)
+}
+
+// Transform runs the function on the resources, merging
+// the resources it emits into the ResMap, as
+// execplugin.MergeTransformed does.
+func (p *FnPlugin) Transform(rm resmap.ResMap) error {
+	resources := rm.Resources()
+	var nodes []*yaml.RNode
+	for i, r := range resources {
+		y, err := r.AsYAML()
+		if err != nil {
+			return err
+		}
+		n, err := yaml.Parse(string(y))
+		if err != nil {
+			return err
+		}
+		err = n.PipeE(yaml.SetAnnotation(idAnnotation, strconv.Itoa(i)))
+		if err != nil {
+			return err
+		}
+		nodes = append(nodes, n)
+	}
+	nodes, err := p.run(nodes)
+	if err != nil {
+		return err
+	}
+
+	kept := make(map[*resource.Resource]*resource.Resource)
+	var added []*resource.Resource
+	for _, n := range nodes {
+		idNode, err := n.Pipe(yaml.GetAnnotation(idAnnotation))
+		if err != nil {
+			return err
+		}
+		if err = n.PipeE(yaml.ClearAnnotation(idAnnotation)); err != nil {
+			return err
+		}
+		r, err := p.toResource(n)
+		if err != nil {
+			return err
+		}
+		if idNode == nil {
+			added = append(added, r)
+			continue
+		}
+		i, err := strconv.Atoi(idNode.YNode().Value)
+		if err != nil || i < 0 || i >= len(resources) {
+			return fmt.Errorf(
+				"function %s emitted an unknown %s '%s'",
+				p.name, idAnnotation, idNode.YNode().Value)
+		}
+		kept[resources[i]] = r
+	}
+	return execplugin.MergeTransformed(rm, kept, added, "function "+p.name)
+}
+
+// run runs the function on the given nodes.
+func (p *FnPlugin) run(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	out, err := p.filter.Filter(nodes)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failure in function %s", p.name)
+	}
+	return out, nil
+}
+
+// toResource converts a node emitted by the function
+// to a resource, dropping the annotations kyaml adds.
+func (p *FnPlugin) toResource(n *yaml.RNode) (*resource.Resource, error) {
+	for _, a := range []string{
+		kioutil.PathAnnotation, kioutil.IndexAnnotation} {
+		if err := n.PipeE(yaml.ClearAnnotation(a)); err != nil {
+			return nil, err
+		}
+	}
+	s, err := n.String()
+	if err != nil {
+		return nil, err
+	}
+	r, err := p.h.ResmapFactory().RF().FromBytes([]byte(s))
+	if err != nil {
+		return nil, errors.Wrapf(
+			err, "reading resource emitted by function %s", p.name)
+	}
+	if len(r.GetAnnotations()) == 0 {
+		r.SetAnnotations(nil)
+	}
+	return r, nil
+}
@@ -0,0 +1,289 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package fnplugin_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+	. "sigs.k8s.io/kustomize/api/internal/plugins/fnplugin"
+	"sigs.k8s.io/kustomize/api/k8sdeps/kunstruct"
+	fLdr "sigs.k8s.io/kustomize/api/loader"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	valtest_test "sigs.k8s.io/kustomize/api/testutils/valtest"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+const (
+	starConfig = `
+apiVersion: someteam.example.com/v1
+kind: Scaler
+metadata:
+  name: scaler
+  annotations:
+    config.kubernetes.io/function: |
+      starlark:
+        path: scale.star
+replicas: 3
+`
+	containerConfig = `
+apiVersion: someteam.example.com/v1
+kind: Upgrader
+metadata:
+  name: upgrader
+  annotations:
+    config.kubernetes.io/function: |
+      container:
+        image: example.com/upgrader:v1
+`
+	input = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: kept
+  annotations:
+    owner: team
+spec:
+  replicas: 1
+  value: old
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: dropped
+`
+)
+
+func makeRf() *resmap.Factory {
+	return resmap.NewFactory(
+		resource.NewFactory(
+			kunstruct.NewKunstructuredFactoryImpl()), nil)
+}
+
+// makeFnPlugin returns a configured plugin for the given
+// config, with the given files in the root of its loader.
+func makeFnPlugin(
+	t *testing.T, opts *types.FnPluginLoadingOptions,
+	config string, files map[string]string) *FnPlugin {
+	fSys := filesys.MakeFsInMemory()
+	for name, content := range files {
+		fSys.WriteFile(filepath.Join("/app", name), []byte(content))
+	}
+	fSys.MkdirAll("/app")
+	ldr, err := fLdr.NewLoader(fLdr.RestrictionRootOnly, "/app", fSys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rf := makeRf()
+	res, err := rf.RF().FromBytes([]byte(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := GetFunctionSpec(res)
+	if spec == nil {
+		t.Fatalf("expected a function spec in config %s", config)
+	}
+	p := NewFnPlugin(opts, spec)
+	if err = p.ErrIfNotEnabled(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = p.Config(
+		resmap.NewPluginHelpers(ldr, valtest_test.MakeFakeValidator(), rf),
+		[]byte(config))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return p
+}
+
+func transform(t *testing.T, p *FnPlugin) string {
+	rm, err := makeRf().NewResMapFromBytes([]byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Transform(rm); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	yml, err := rm.AsYaml()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(yml)
+}
+
+func TestStarlarkTransformer(t *testing.T) {
+	p := makeFnPlugin(t, &types.FnPluginLoadingOptions{EnableStar: true},
+		starConfig, map[string]string{"scale.star": `
+def scale(resource_list):
+  items = []
+  for r in resource_list["items"]:
+    if r["metadata"]["name"] == "dropped":
+      continue
+    if r["kind"] == "Deployment":
+      r["spec"]["replicas"] = resource_list["functionConfig"]["replicas"]
+    items.append(r)
+  items.append({"apiVersion": "v1", "kind": "Service",
+    "metadata": {"name": "added"}})
+  resource_list["items"] = items
+
+scale(ctx.resource_list)
+`})
+	expected := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  annotations:
+    owner: team
+  name: kept
+spec:
+  replicas: 3
+  value: old
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: added
+`
+	if actual := transform(t, p); actual != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+}
+
+func TestStarlarkGenerator(t *testing.T) {
+	p := makeFnPlugin(t, &types.FnPluginLoadingOptions{EnableStar: true},
+		starConfig, map[string]string{"scale.star": `
+ctx.resource_list["items"] = [{
+  "apiVersion": "v1", "kind": "ConfigMap",
+  "metadata": {
+    "name": "replicas",
+    "annotations": {"kustomize.config.k8s.io/needs-hash": "true"}},
+  "data": {"replicas": str(ctx.resource_list["functionConfig"]["replicas"])}}]
+`})
+	rm, err := p.Generate()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rm.Size() != 1 || !rm.GetByIndex(0).NeedHashSuffix() {
+		t.Fatalf("expected one resource needing a hash suffix, got %v", rm)
+	}
+	yml, err := rm.AsYaml()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `apiVersion: v1
+data:
+  replicas: "3"
+kind: ConfigMap
+metadata:
+  name: replicas
+`
+	if string(yml) != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, yml)
+	}
+}
+
+func TestStarlarkError(t *testing.T) {
+	p := makeFnPlugin(t, &types.FnPluginLoadingOptions{EnableStar: true},
+		starConfig, map[string]string{"scale.star": `
+fail("too many replicas")
+`})
+	_, err := p.Generate()
+	if err == nil || !strings.Contains(err.Error(),
+		"failure in function scale.star") ||
+		!strings.Contains(err.Error(), "too many replicas") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// TestContainerTransformer runs the function with a
+// fake docker, which edits the ResourceList it's given.
+func TestContainerTransformer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kustomize-fnplugin-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	err = ioutil.WriteFile(filepath.Join(dir, "docker"), []byte(`#!/bin/bash
+sed 's/value: old/value: new/'
+`), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := os.Getenv("PATH")
+	defer os.Setenv("PATH", path)
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+path)
+
+	p := makeFnPlugin(t,
+		&types.FnPluginLoadingOptions{EnableContainers: true},
+		containerConfig, nil)
+	expected := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  annotations:
+    owner: team
+  name: kept
+spec:
+  replicas: 1
+  value: new
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: dropped
+`
+	if actual := transform(t, p); actual != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+}
+
+func TestErrIfNotEnabled(t *testing.T) {
+	rf := makeRf()
+	testCases := map[string]struct {
+		config string
+		opts   types.FnPluginLoadingOptions
+		errMsg string
+	}{
+		"container disabled": {
+			config: containerConfig,
+			opts:   types.FnPluginLoadingOptions{EnableStar: true},
+			errMsg: "container functions disabled; " +
+				"unable to run image example.com/upgrader:v1",
+		},
+		"starlark disabled": {
+			config: starConfig,
+			opts:   types.FnPluginLoadingOptions{EnableContainers: true},
+			errMsg: "starlark functions disabled; " +
+				"unable to run script scale.star",
+		},
+		"network disallowed": {
+			config: strings.Replace(containerConfig, "upgrader:v1\n",
+				"upgrader:v1\n        network:\n          required: true\n", 1),
+			opts: types.FnPluginLoadingOptions{EnableContainers: true},
+			errMsg: "function in image example.com/upgrader:v1 " +
+				"requires network access",
+		},
+		"nothing to run": {
+			config: strings.Replace(containerConfig,
+				"image: example.com/upgrader:v1", "{}", 1),
+			opts: types.FnPluginLoadingOptions{
+				EnableContainers: true, EnableStar: true},
+			errMsg: "names neither a container image nor a starlark script",
+		},
+	}
+	for name, tc := range testCases {
+		res, err := rf.RF().FromBytes([]byte(tc.config))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		opts := tc.opts
+		err = NewFnPlugin(&opts, GetFunctionSpec(res)).ErrIfNotEnabled()
+		if err == nil || !strings.Contains(err.Error(), tc.errMsg) {
+			t.Errorf("%s: unexpected error: %v", name, err)
+		}
+	}
+}
@@ -15,7 +15,9 @@ import (
 	"sigs.k8s.io/kustomize/api/ifc"
 	"sigs.k8s.io/kustomize/api/internal/plugins/builtinhelpers"
 	"sigs.k8s.io/kustomize/api/internal/plugins/execplugin"
+	"sigs.k8s.io/kustomize/api/internal/plugins/fnplugin"
 	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/pluginhome"
 	"sigs.k8s.io/kustomize/api/resid"
 	"sigs.k8s.io/kustomize/api/resmap"
 	"sigs.k8s.io/kustomize/api/resource"
@@ -92,8 +94,7 @@ func relativePluginPath(id resid.ResId) string {
 }
 
 func AbsolutePluginPath(pc *types.PluginConfig, id resid.ResId) string {
-	return filepath.Join(
-		pc.AbsPluginHome, relativePluginPath(id), id.Kind)
+	return pluginhome.Path(pc.AbsPluginHome, id.Gvk)
 }
 
 func (l *Loader) absolutePluginPath(id resid.ResId) string {
@@ -108,7 +109,17 @@ func isBuiltinPlugin(res *resource.Resource) bool {
 
 func (l *Loader) loadAndConfigurePlugin(
 	ldr ifc.Loader, v ifc.Validator, res *resource.Resource) (c resmap.Configurable, err error) {
-	if isBuiltinPlugin(res) {
+	if spec := fnplugin.GetFunctionSpec(res); spec != nil {
+		// The config names a kyaml function to run, as
+		// 'kustomize config run' would.  Like other external
+		// plugins, it needs the restrictions lifted, and
+		// then the function options to allow its kind.
+		if l.pc.PluginRestrictions != types.PluginRestrictionsNone {
+			return nil, types.NewErrOnlyBuiltinPluginsAllowed(res.OrgId().Kind)
+		}
+		p := fnplugin.NewFnPlugin(&l.pc.FnpLoadingOptions, spec)
+		c, err = p, p.ErrIfNotEnabled()
+	} else if isBuiltinPlugin(res) {
 		// Instead of looking for and loading a .so file, just
 		// instantiate the plugin from a generated factory
 		// function (see "pluginator").  Being able to do this
@@ -126,7 +137,7 @@ func (l *Loader) loadAndConfigurePlugin(
 	if err != nil {
 		return nil, errors.Wrapf(err, "marshalling yaml from res %s", res.OrgId())
 	}
-	err = c.Config(resmap.NewPluginHelpers(ldr, v, l.rf), yaml)
+	err = c.Config(l.PluginHelpers(ldr, v), yaml)
 	if err != nil {
 		return nil, errors.Wrapf(
 			err, "plugin %s fails configuration", res.OrgId())
@@ -134,6 +145,15 @@ func (l *Loader) loadAndConfigurePlugin(
 	return c, nil
 }
 
+// PluginHelpers returns the helpers of plugins loading files
+// with the given loader, which run executables as exec
+// plugins are run.
+func (l *Loader) PluginHelpers(
+	ldr ifc.Loader, v ifc.Validator) *resmap.PluginHelpers {
+	return resmap.NewPluginHelpersWithConfig(
+		ldr, v, l.rf, l.pc, execplugin.RunCommand)
+}
+
 func (l *Loader) makeBuiltinPlugin(r resid.Gvk) (resmap.Configurable, error) {
 	bpt := builtinhelpers.GetBuiltinPluginType(r.Kind)
 	if f, ok := builtinhelpers.GeneratorFactories[bpt]; ok {
@@ -147,7 +167,8 @@ func (l *Loader) makeBuiltinPlugin(r resid.Gvk) (resmap.Configurable, error) {
 
 func (l *Loader) loadPlugin(resId resid.ResId) (resmap.Configurable, error) {
 	// First try to load the plugin as an executable.
-	p := execplugin.NewExecPlugin(l.absolutePluginPath(resId))
+	p := execplugin.NewExecPluginWithOptions(
+		l.absolutePluginPath(resId), &l.pc.ExecpOptions)
 	err := p.ErrIfNotExecutable()
 	if err == nil {
 		return p, nil
@@ -0,0 +1,53 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"sync"
+
+	"sigs.k8s.io/kustomize/api/internal/accumulator"
+)
+
+// baseCache memoizes the accumulation of kustomizations,
+// keyed by loader root, for the duration of one build.
+//
+// A base reachable via more than one path (a diamond)
+// is built only once.  Concurrent requests for the same
+// root wait for the first one to finish.  Loaders reject
+// cycles before a root is requested, so a build can never
+// wait on itself.
+type baseCache struct {
+	mu      sync.Mutex
+	entries map[string]*baseCacheEntry
+}
+
+type baseCacheEntry struct {
+	once sync.Once
+	ra   *accumulator.ResAccumulator
+	err  error
+}
+
+func newBaseCache() *baseCache {
+	return &baseCache{entries: make(map[string]*baseCacheEntry)}
+}
+
+// get returns the accumulation for the given root, calling
+// build to create it if this is the first request for root.
+// The returned accumulator is shared, and must not be modified.
+func (c *baseCache) get(
+	root string,
+	build func() (*accumulator.ResAccumulator, error)) (
+	*accumulator.ResAccumulator, error) {
+	c.mu.Lock()
+	e, ok := c.entries[root]
+	if !ok {
+		e = &baseCacheEntry{}
+		c.entries[root] = e
+	}
+	c.mu.Unlock()
+	e.once.Do(func() {
+		e.ra, e.err = build()
+	})
+	return e.ra, e.err
+}
@@ -7,18 +7,24 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"log"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/pkg/errors"
 	"sigs.k8s.io/kustomize/api/builtins"
+	"sigs.k8s.io/kustomize/api/explain"
 	"sigs.k8s.io/kustomize/api/ifc"
 	"sigs.k8s.io/kustomize/api/internal/accumulator"
+	"sigs.k8s.io/kustomize/api/internal/kusterr"
 	"sigs.k8s.io/kustomize/api/internal/plugins/builtinconfig"
 	"sigs.k8s.io/kustomize/api/internal/plugins/builtinhelpers"
 	"sigs.k8s.io/kustomize/api/internal/plugins/loader"
 	"sigs.k8s.io/kustomize/api/konfig"
 	"sigs.k8s.io/kustomize/api/resmap"
-	"sigs.k8s.io/kustomize/api/transform"
+	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/yaml"
 )
@@ -31,6 +37,27 @@ type KustTarget struct {
 	rFactory      *resmap.Factory
 	tFactory      resmap.PatchFactory
 	pLdr          *loader.Loader
+	bases         *baseCache
+	// When true, note on each resource
+	// the transformers that changed it.
+	trackTransformations bool
+	// When true, record a trace of the build's steps.
+	traceSteps bool
+	trace      *explain.Trace
+	// The kustomization file, for positioning errors.
+	kustFile *kusterr.File
+	// Whether and how to check the output against
+	// the OpenAPI schemas of its resources.
+	schemaValidation types.SchemaValidation
+	// The transformer config of the last build, whose
+	// name references the topological sort follows.
+	tConfig *builtinconfig.TransformerConfig
+	// The files and kustomizations loaded ahead of
+	// accumulation.  Shared by the targets of a build.
+	preloads *preloads
+	// When true, patches, replicas, images and vars
+	// that match nothing are errors.
+	strict bool
 }
 
 // NewKustTarget returns a new instance of KustTarget.
@@ -46,20 +73,67 @@ func NewKustTarget(
 		rFactory:  rFactory,
 		tFactory:  tFactory,
 		pLdr:      pLdr,
+		bases:     newBaseCache(),
+		preloads:  newPreloads(),
 	}
 }
 
+// EnableTransformationTracking makes the target note, on
+// each resource, the transformers that changed it.
+// Tracking holds a copy of every resource while each
+// transformer runs, so it's off by default.
+func (kt *KustTarget) EnableTransformationTracking() {
+	kt.trackTransformations = true
+}
+
+// EnableTrace makes the target record each generator and
+// transformer run, and how it changed the resources.
+func (kt *KustTarget) EnableTrace() {
+	kt.traceSteps = true
+}
+
+// Trace returns the steps recorded by the last call to
+// MakeCustomizedResMap, or nil if tracing isn't enabled.
+func (kt *KustTarget) Trace() *explain.Trace {
+	return kt.trace
+}
+
+// EnableStrictMode makes the build fail on patches, replicas,
+// images and vars that match no resources or fields, which
+// are otherwise ignored, e.g. on a typo in a patch target.
+func (kt *KustTarget) EnableStrictMode() {
+	kt.strict = true
+}
+
+// SetSchemaValidation sets whether the target checks the
+// resources it makes against their OpenAPI schemas, and
+// whether the problems found are warnings or errors.
+func (kt *KustTarget) SetSchemaValidation(v types.SchemaValidation) {
+	kt.schemaValidation = v
+}
+
+func (kt *KustTarget) validatesSchemas() bool {
+	return kt.schemaValidation == types.SchemaValidationWarn ||
+		kt.schemaValidation == types.SchemaValidationError
+}
+
 // Load attempts to load the target's kustomization file.
 func (kt *KustTarget) Load() error {
-	content, err := loadKustFile(kt.ldr)
+	content, kf, err := loadKustFile(kt.ldr)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(kt.ldr.Root(), kf)
+	err = kusterr.SyntaxError(path, content)
 	if err != nil {
 		return err
 	}
 	content = types.FixKustomizationPreUnmarshalling(content)
+	kt.kustFile = kusterr.NewFile(path, content)
 	var k types.Kustomization
 	err = unmarshal(content, &k)
 	if err != nil {
-		return err
+		return kusterr.At(positionOfUnmarshalError(kt.kustFile, err), err)
 	}
 	k.FixKustomizationPostUnmarshalling()
 	errs := k.EnforceFields()
@@ -72,23 +146,27 @@ func (kt *KustTarget) Load() error {
 	return nil
 }
 
-func loadKustFile(ldr ifc.Loader) ([]byte, error) {
+// loadKustFile returns the content of the target's
+// kustomization file, and the file's name.
+func loadKustFile(ldr ifc.Loader) ([]byte, string, error) {
 	var content []byte
+	var name string
 	match := 0
 	for _, kf := range konfig.RecognizedKustomizationFileNames() {
 		c, err := ldr.Load(kf)
 		if err == nil {
 			match += 1
 			content = c
+			name = kf
 		}
 	}
 	switch match {
 	case 0:
-		return nil, NewErrMissingKustomization(ldr.Root())
+		return nil, "", NewErrMissingKustomization(ldr.Root())
 	case 1:
-		return content, nil
+		return content, name, nil
 	default:
-		return nil, fmt.Errorf(
+		return nil, "", fmt.Errorf(
 			"Found multiple kustomization files under: %s\n", ldr.Root())
 	}
 }
@@ -103,6 +181,22 @@ func unmarshal(y []byte, o interface{}) error {
 	return dec.Decode(o)
 }
 
+// positionOfUnmarshalError returns the position in the
+// kustomization file of the field unmarshal complained of.
+func positionOfUnmarshalError(f *kusterr.File, err error) kusterr.Position {
+	if e, ok := err.(*json.UnmarshalTypeError); ok && e.Field != "" {
+		return f.PositionOf(strings.Split(e.Field, ".")...)
+	}
+	const unknownField = "json: unknown field "
+	if msg := err.Error(); strings.HasPrefix(msg, unknownField) {
+		name, uErr := strconv.Unquote(strings.TrimPrefix(msg, unknownField))
+		if uErr == nil {
+			return f.PositionOfKey(name)
+		}
+	}
+	return kusterr.Position{Path: f.Path()}
+}
+
 // MakeCustomizedResMap creates a fully customized ResMap
 // per the instructions contained in its kustomiztion instance.
 func (kt *KustTarget) MakeCustomizedResMap() (resmap.ResMap, error) {
@@ -115,6 +209,16 @@ func (kt *KustTarget) MakePruneConfigMap() (resmap.ResMap, error) {
 
 func (kt *KustTarget) makeCustomizedResMap(
 	garbagePolicy types.GarbagePolicy) (resmap.ResMap, error) {
+	defer kt.preloads.cleanup()
+	schemas, err := kt.preload()
+	if err != nil {
+		return nil, err
+	}
+	release, err := accumulator.UseOpenAPISchemas(schemas)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
 	ra, err := kt.AccumulateTarget()
 	if err != nil {
 		return nil, err
@@ -130,13 +234,18 @@ func (kt *KustTarget) makeCustomizedResMap(
 
 	// Given that names have changed (prefixs/suffixes added),
 	// fix all the back references to those names.
-	err = ra.FixBackReferences()
+	err = kt.track(ra, "NameReferenceTransformer", ra.FixBackReferences)
 	if err != nil {
 		return nil, err
 	}
 
 	// With all the back references fixed, it's OK to resolve Vars.
-	err = ra.ResolveVars()
+	err = kt.track(ra, "RefVarTransformer", ra.ResolveVars)
+	if err != nil {
+		return nil, err
+	}
+
+	err = kt.addConfigHashes(ra)
 	if err != nil {
 		return nil, err
 	}
@@ -146,9 +255,71 @@ func (kt *KustTarget) makeCustomizedResMap(
 		return nil, err
 	}
 
+	err = kt.validateSchemas(ra)
+	if err != nil {
+		return nil, err
+	}
+
+	kt.trace = ra.Trace()
+	kt.tConfig = ra.GetTransformerConfig()
 	return ra.ResMap(), nil
 }
 
+// SortOptions returns the sortOptions of the kustomization,
+// or nil if it has none.
+func (kt *KustTarget) SortOptions() *types.SortOptions {
+	return kt.kustomization.SortOptions
+}
+
+// Sort orders the resources of m, as made by the last call to
+// MakeCustomizedResMap, per the given options.
+func (kt *KustTarget) Sort(m resmap.ResMap, o *types.SortOptions) error {
+	switch o.Order {
+	case types.NoSortOrder:
+		return nil
+	case types.LegacySortOrder, "":
+		p := builtins.NewLegacyOrderTransformerPlugin()
+		err := kt.configureBuiltinPlugin(
+			p, o.KindOrder, builtinhelpers.LegacyOrderTransformer)
+		if err != nil {
+			return err
+		}
+		return p.Transform(m)
+	case types.TopologicalSortOrder:
+		return accumulator.SortTopologically(m, kt.tConfig, o.KindOrder)
+	default:
+		return fmt.Errorf(
+			"unknown sort order '%s'; expected one of %v", o.Order,
+			[]types.SortOrder{types.LegacySortOrder,
+				types.NoSortOrder, types.TopologicalSortOrder})
+	}
+}
+
+// validateSchemas checks the accumulated resources against
+// their OpenAPI schemas, if the target is to, logging or
+// returning the problems found.
+func (kt *KustTarget) validateSchemas(ra *accumulator.ResAccumulator) error {
+	if !kt.validatesSchemas() {
+		return nil
+	}
+	problems := ra.ValidateSchemas()
+	if len(problems) == 0 {
+		return nil
+	}
+	if kt.schemaValidation == types.SchemaValidationWarn {
+		for _, p := range problems {
+			log.Printf("warning: schema validation: %s\n", p)
+		}
+		return nil
+	}
+	msgs := make([]string, len(problems))
+	for i, p := range problems {
+		msgs[i] = "  " + p.String()
+	}
+	return fmt.Errorf(
+		"resources fail schema validation:\n%s", strings.Join(msgs, "\n"))
+}
+
 func (kt *KustTarget) addHashesToNames(
 	ra *accumulator.ResAccumulator) error {
 	p := builtins.NewHashTransformerPlugin()
@@ -156,7 +327,108 @@ func (kt *KustTarget) addHashesToNames(
 	if err != nil {
 		return err
 	}
-	return ra.Transform(p)
+	return kt.track(ra, builtinhelpers.HashTransformer.String(), func() error {
+		return ra.Transform(p)
+	})
+}
+
+// addConfigHashes annotates pod templates with the hash of
+// the ConfigMaps and Secrets they refer to, if the
+// kustomization they came from, or one including it, asked
+// for it.  It's done once all the resources are final, so
+// that the hash covers every change made to them.
+func (kt *KustTarget) addConfigHashes(
+	ra *accumulator.ResAccumulator) error {
+	var configMapRefs, secretRefs types.FsSlice
+	for _, nbr := range ra.GetTransformerConfig().NameReference {
+		switch nbr.Kind {
+		case "ConfigMap":
+			configMapRefs = append(configMapRefs, nbr.FieldSpecs...)
+		case "Secret":
+			secretRefs = append(secretRefs, nbr.FieldSpecs...)
+		}
+	}
+	// Group the resources to annotate by annotation.  Each
+	// group is transformed along with every ConfigMap and
+	// Secret, which its pod templates may refer to.
+	var annotations []string
+	groups := make(map[string]resmap.ResMap)
+	configs := resmap.New()
+	for _, r := range ra.ResMap().Resources() {
+		switch r.GetKind() {
+		case "ConfigMap", "Secret":
+			if err := configs.Append(r); err != nil {
+				return err
+			}
+			continue
+		}
+		ch := r.ConfigHash()
+		if ch == nil {
+			continue
+		}
+		if _, ok := groups[ch.Annotation]; !ok {
+			annotations = append(annotations, ch.Annotation)
+			groups[ch.Annotation] = resmap.New()
+		}
+		if err := groups[ch.Annotation].Append(r); err != nil {
+			return err
+		}
+	}
+	if len(annotations) == 0 {
+		return nil
+	}
+	return kt.track(ra, builtinhelpers.ConfigHashTransformer.String(), func() error {
+		for _, a := range annotations {
+			var c struct {
+				Annotation    string
+				ConfigMapRefs types.FsSlice
+				SecretRefs    types.FsSlice
+			}
+			c.Annotation = a
+			c.ConfigMapRefs = configMapRefs
+			c.SecretRefs = secretRefs
+			p := builtins.NewConfigHashTransformerPlugin()
+			err := kt.configureBuiltinPlugin(
+				p, c, builtinhelpers.ConfigHashTransformer)
+			if err != nil {
+				return err
+			}
+			m := groups[a]
+			if err = m.AppendAll(configs); err != nil {
+				return err
+			}
+			if err = p.Transform(m); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// track calls f, which modifies the given accumulator
+// on behalf of the named transformer.
+func (kt *KustTarget) track(
+	ra *accumulator.ResAccumulator, name string, f func() error) error {
+	return ra.TrackChanges(resource.Transformation{
+		Transformer: name,
+		Root:        kt.ldr.Root(),
+	}, f)
+}
+
+// makeAccumulator returns an empty accumulator that
+// tracks and traces changes if the target is to.
+func (kt *KustTarget) makeAccumulator() *accumulator.ResAccumulator {
+	ra := accumulator.MakeEmptyAccumulator()
+	if kt.trackTransformations {
+		ra.EnableTransformationTracking()
+	}
+	if kt.traceSteps {
+		ra.EnableTrace()
+	}
+	if kt.strict {
+		ra.EnableStrictMode()
+	}
+	return ra
 }
 
 func (kt *KustTarget) computeInventory(
@@ -165,21 +437,41 @@ func (kt *KustTarget) computeInventory(
 	if inv == nil {
 		return nil
 	}
-	if inv.Type != "ConfigMap" {
-		return fmt.Errorf("don't know how to do that")
+	switch inv.Type {
+	case "", types.InventoryTypeConfigMap, types.InventoryTypeSecret:
+	case types.InventoryTypeCustom:
+		if inv.Custom.APIVersion == "" || inv.Custom.Kind == "" {
+			return fmt.Errorf(
+				"inventory of type %s requires an apiVersion and kind",
+				inv.Type)
+		}
+	default:
+		return fmt.Errorf(
+			"unknown inventory type '%s'; expected one of %v", inv.Type,
+			[]string{types.InventoryTypeConfigMap,
+				types.InventoryTypeSecret, types.InventoryTypeCustom})
 	}
 
-	if inv.ConfigMap.Namespace != kt.kustomization.Namespace {
-		return fmt.Errorf("namespace mismatch")
+	obj := inv.Object()
+	if obj.Namespace != kt.kustomization.Namespace &&
+		!inv.AllowCrossNamespace {
+		return fmt.Errorf(
+			"inventory namespace '%s' differs from kustomization "+
+				"namespace '%s'; set allowCrossNamespace to allow this",
+			obj.Namespace, kt.kustomization.Namespace)
 	}
 
 	var c struct {
 		Policy           string
+		Type             string
+		Custom           types.TypeMeta
 		types.ObjectMeta `json:"metadata,omitempty" yaml:"metadata,omitempty"`
 	}
-	c.Name = inv.ConfigMap.Name
-	c.Namespace = inv.ConfigMap.Namespace
+	c.Name = obj.Name
+	c.Namespace = obj.Namespace
 	c.Policy = garbagePolicy.String()
+	c.Type = inv.Type
+	c.Custom = inv.Custom.TypeMeta
 	p := builtins.NewInventoryTransformerPlugin()
 	err := kt.configureBuiltinPlugin(p, c, builtinhelpers.InventoryTransformer)
 	if err != nil {
@@ -191,14 +483,27 @@ func (kt *KustTarget) computeInventory(
 // AccumulateTarget returns a new ResAccumulator,
 // holding customized resources and the data/rules used
 // to do so.  The name back references and vars are
-// not yet fixed.
+// not yet fixed, and the OpenAPI schemas the build
+// names aren't used; MakeCustomizedResMap uses them.
 func (kt *KustTarget) AccumulateTarget() (
 	ra *accumulator.ResAccumulator, err error) {
-	ra = accumulator.MakeEmptyAccumulator()
-	err = kt.accumulateResources(ra, kt.kustomization.Resources)
+	return kt.accumulateTarget(kt.makeAccumulator())
+}
+
+// accumulateTarget customizes the resources in the given
+// accumulator, which is empty unless the target is a
+// component, in which case it holds the resources of
+// the kustomization that includes the component.
+func (kt *KustTarget) accumulateTarget(
+	ra *accumulator.ResAccumulator) (*accumulator.ResAccumulator, error) {
+	err := kt.accumulateResources(ra, kt.kustomization.Resources)
 	if err != nil {
 		return nil, errors.Wrap(err, "accumulating resources")
 	}
+	err = kt.accumulateComponents(ra, kt.kustomization.Components)
+	if err != nil {
+		return nil, errors.Wrap(err, "accumulating components")
+	}
 	tConfig, err := builtinconfig.MakeTransformerConfig(
 		kt.ldr, kt.kustomization.Configurations)
 	if err != nil {
@@ -219,6 +524,15 @@ func (kt *KustTarget) AccumulateTarget() (
 		return nil, errors.Wrapf(
 			err, "merging CRDs %v", crdTc)
 	}
+	if kt.validatesSchemas() {
+		defs, err := accumulator.LoadDefinitionsFromCRDs(
+			kt.ldr, kt.kustomization.Crds)
+		if err != nil {
+			return nil, errors.Wrapf(
+				err, "loading CRDs %v", kt.kustomization.Crds)
+		}
+		ra.MergeCrdDefinitions(defs)
+	}
 	err = kt.runGenerators(ra)
 	if err != nil {
 		return nil, err
@@ -227,33 +541,53 @@ func (kt *KustTarget) AccumulateTarget() (
 	if err != nil {
 		return nil, err
 	}
+	if kt.kustomization.ConfigHash != nil {
+		ra.SetConfigHash(kt.kustomization.ConfigHash)
+	}
 	err = ra.MergeVars(kt.kustomization.Vars)
 	if err != nil {
 		return nil, errors.Wrapf(
 			err, "merging vars %v", kt.kustomization.Vars)
 	}
+	if kt.kustFile != nil {
+		for i, v := range kt.kustomization.Vars {
+			ra.SetVarPosition(
+				v.Name, kt.kustFile.PositionOf("vars", strconv.Itoa(i)))
+		}
+	}
 	return ra, nil
 }
 
 func (kt *KustTarget) runGenerators(
 	ra *accumulator.ResAccumulator) error {
 	var generators []resmap.Generator
-	gs, err := kt.configureBuiltinGenerators()
+	var names []string
+	gs, ns, err := kt.configureBuiltinGenerators()
 	if err != nil {
 		return err
 	}
 	generators = append(generators, gs...)
-	gs, err = kt.configureExternalGenerators()
+	names = append(names, ns...)
+	gs, ns, err = kt.configureExternalGenerators()
 	if err != nil {
 		return errors.Wrap(err, "loading generator plugins")
 	}
 	generators = append(generators, gs...)
-	for _, g := range generators {
+	names = append(names, ns...)
+	for i, g := range generators {
 		resMap, err := g.Generate()
 		if err != nil {
 			return err
 		}
-		err = ra.AbsorbAll(resMap)
+		for _, r := range resMap.Resources() {
+			r.SetOrigin(&resource.Origin{
+				Root:      kt.ldr.Root(),
+				Generator: names[i],
+			})
+		}
+		err = ra.TraceChanges(names[i], kt.ldr.Root(), func() error {
+			return ra.AbsorbAll(resMap)
+		})
 		if err != nil {
 			return errors.Wrapf(err, "merging from generator %v", g)
 		}
@@ -261,95 +595,265 @@ func (kt *KustTarget) runGenerators(
 	return nil
 }
 
-func (kt *KustTarget) configureExternalGenerators() ([]resmap.Generator, error) {
+// configureExternalGenerators returns the generator plugins
+// configured in the kustomization, along with a name for
+// each (the id of its config).
+func (kt *KustTarget) configureExternalGenerators() (
+	[]resmap.Generator, []string, error) {
 	ra := accumulator.MakeEmptyAccumulator()
 	err := kt.accumulateResources(ra, kt.kustomization.Generators)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return kt.pLdr.LoadGenerators(kt.ldr, kt.validator, ra.ResMap())
+	gs, err := kt.pLdr.LoadGenerators(kt.ldr, kt.validator, ra.ResMap())
+	if err != nil {
+		return nil, nil, err
+	}
+	return gs, pluginNames(ra.ResMap()), nil
 }
 
 func (kt *KustTarget) runTransformers(ra *accumulator.ResAccumulator) error {
 	var r []resmap.Transformer
+	var names []string
 	tConfig := ra.GetTransformerConfig()
-	lts, err := kt.configureBuiltinTransformers(tConfig)
+	lts, ns, err := kt.configureBuiltinTransformers(tConfig)
 	if err != nil {
 		return err
 	}
 	r = append(r, lts...)
-	lts, err = kt.configureExternalTransformers()
+	names = append(names, ns...)
+	lts, ns, err = kt.configureExternalTransformers()
 	if err != nil {
 		return err
 	}
 	r = append(r, lts...)
-	t := transform.NewMultiTransformer(r)
-	return ra.Transform(t)
+	names = append(names, ns...)
+	for i, t := range r {
+		err = kt.track(ra, names[i], func() error {
+			return ra.Transform(t)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func (kt *KustTarget) configureExternalTransformers() ([]resmap.Transformer, error) {
+// configureExternalTransformers returns the transformer plugins
+// configured in the kustomization, along with a name for
+// each (the id of its config).
+func (kt *KustTarget) configureExternalTransformers() (
+	[]resmap.Transformer, []string, error) {
 	ra := accumulator.MakeEmptyAccumulator()
 	err := kt.accumulateResources(ra, kt.kustomization.Transformers)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return kt.pLdr.LoadTransformers(kt.ldr, kt.validator, ra.ResMap())
+	ts, err := kt.pLdr.LoadTransformers(kt.ldr, kt.validator, ra.ResMap())
+	if err != nil {
+		return nil, nil, err
+	}
+	return ts, pluginNames(ra.ResMap()), nil
+}
+
+// pluginNames names plugins by the ids of their configs.
+func pluginNames(configs resmap.ResMap) []string {
+	var result []string
+	for _, res := range configs.Resources() {
+		result = append(result, res.OrgId().GvknString())
+	}
+	return result
 }
 
 // accumulateResources fills the given resourceAccumulator
 // with resources read from the given list of paths.
+// Paths that name other kustomizations are independent of
+// each other, so they're accumulated concurrently; results
+// are merged in the order the paths were given.
 func (kt *KustTarget) accumulateResources(
 	ra *accumulator.ResAccumulator, paths []string) error {
-	for _, path := range paths {
-		// try loading resource as file then as base (directory or git repository)
-		if errF := kt.accumulateFile(ra, path); errF != nil {
-			ldr, errL := kt.ldr.New(path)
-			if errL != nil {
-				return fmt.Errorf("accumulateFile %q, loader.New %q", errF, errL)
+	files := make([]resmap.ResMap, len(paths))
+	bases := make([]*accumulator.ResAccumulator, len(paths))
+	errs := make([]error, len(paths))
+	var wg sync.WaitGroup
+	for i, path := range paths {
+		p := kt.preloads.take(kt.ldr.Root(), path)
+		if p != nil && p.subKt == nil {
+			files[i] = p.resources
+			continue
+		}
+		var errF error
+		if p != nil {
+			errF = p.fileErr
+		} else {
+			// try loading resource as file then as base (directory or git repository)
+			var resources resmap.ResMap
+			resources, errF = kt.rFactory.FromFile(kt.ldr, path)
+			if errF == nil {
+				files[i] = resources
+				continue
+			}
+			errF = errors.Wrapf(errF, "accumulating resources from '%s'", path)
+			if kusterr.Locate(errF) != nil {
+				// The file was read, but is broken.
+				errs[i] = errF
+				continue
+			}
+		}
+		wg.Add(1)
+		go func(i int, path string, errF error) {
+			defer wg.Done()
+			var subKt *KustTarget
+			var ldr ifc.Loader
+			if p != nil {
+				ldr, subKt = p.ldr, p.subKt
+			} else {
+				var errL error
+				ldr, errL = kt.ldr.New(path)
+				if errL != nil {
+					errs[i] = fmt.Errorf("accumulateFile %q, loader.New %q", errF, errL)
+					return
+				}
+			}
+			subRa, errD := kt.accumulateDirectory(ldr, subKt)
+			if errD != nil && kusterr.Locate(errD) != nil {
+				// Keep the position of the error in the directory.
+				errs[i] = errD
+				return
 			}
-			errD := kt.accumulateDirectory(ra, ldr)
 			if errD != nil {
-				return fmt.Errorf("accumulateFile %q, accumulateDirector: %q", errF, errD)
+				errs[i] = fmt.Errorf("accumulateFile %q, accumulateDirector: %q", errF, errD)
+				return
+			}
+			bases[i] = subRa
+		}(i, path, errF)
+	}
+	wg.Wait()
+	for i, path := range paths {
+		if errs[i] != nil {
+			return errs[i]
+		}
+		if files[i] != nil {
+			err := ra.AppendAll(files[i])
+			if err != nil {
+				return errors.Wrapf(err, "merging resources from '%s'", path)
 			}
+			continue
+		}
+		err := ra.MergeAccumulator(bases[i])
+		if err != nil {
+			return errors.Wrapf(
+				err, "recursed merging from path '%s'", path)
 		}
 	}
 	return nil
 }
 
+// accumulateDirectory returns the accumulation of the
+// kustomization at the root of the given loader.
+// Bases are memoized by root, so the result is a copy
+// that the caller is free to transform.  The target of the
+// kustomization is loaded, unless it's given.
 func (kt *KustTarget) accumulateDirectory(
-	ra *accumulator.ResAccumulator, ldr ifc.Loader) error {
+	ldr ifc.Loader, subKt *KustTarget) (*accumulator.ResAccumulator, error) {
 	defer ldr.Cleanup()
-	subKt := NewKustTarget(
-		ldr, kt.validator, kt.rFactory, kt.tFactory, kt.pLdr)
-	err := subKt.Load()
+	subRa, err := kt.bases.get(ldr.Root(), func() (
+		*accumulator.ResAccumulator, error) {
+		if subKt == nil {
+			var err error
+			subKt, err = kt.loadSubTarget(ldr)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if subKt.kustomization.Kind == types.ComponentKind {
+			return nil, fmt.Errorf(
+				"expected kind != '%s' for path '%s'",
+				types.ComponentKind, ldr.Root())
+		}
+		subRa, err := subKt.AccumulateTarget()
+		if err != nil {
+			return nil, errors.Wrapf(
+				err, "recursed accumulation of path '%s'", ldr.Root())
+		}
+		return subRa, nil
+	})
 	if err != nil {
-		return errors.Wrapf(
-			err, "couldn't make target for path '%s'", ldr.Root())
+		return nil, err
 	}
-	subRa, err := subKt.AccumulateTarget()
-	if err != nil {
-		return errors.Wrapf(
-			err, "recursed accumulation of path '%s'", ldr.Root())
+	return subRa.DeepCopy(), nil
+}
+
+// accumulateComponents applies the components at the
+// given paths, in order, to the given accumulator.
+// Components see the resources accumulated before them,
+// so unlike bases they are neither concurrent nor memoized.
+func (kt *KustTarget) accumulateComponents(
+	ra *accumulator.ResAccumulator, paths []string) error {
+	for _, path := range paths {
+		if p := kt.preloads.take(kt.ldr.Root(), path); p != nil && p.subKt != nil {
+			err := kt.accumulateComponent(ra, p.ldr, p.subKt)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		ldr, err := kt.ldr.New(path)
+		if err != nil {
+			return errors.Wrapf(err, "loading component '%s'", path)
+		}
+		err = kt.accumulateComponent(ra, ldr, nil)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// accumulateComponent applies the component at the root of
+// the given loader to the given accumulator.  The target of
+// the component is loaded, unless it's given.
+func (kt *KustTarget) accumulateComponent(
+	ra *accumulator.ResAccumulator, ldr ifc.Loader, subKt *KustTarget) error {
+	defer ldr.Cleanup()
+	if subKt == nil {
+		var err error
+		subKt, err = kt.loadSubTarget(ldr)
+		if err != nil {
+			return err
+		}
 	}
-	err = ra.MergeAccumulator(subRa)
+	if subKt.kustomization.Kind != types.ComponentKind {
+		return fmt.Errorf(
+			"expected kind '%s' for path '%s' but got '%s'",
+			types.ComponentKind, ldr.Root(), subKt.kustomization.Kind)
+	}
+	_, err := subKt.accumulateTarget(ra)
 	if err != nil {
 		return errors.Wrapf(
-			err, "recursed merging from path '%s'", ldr.Root())
+			err, "recursed accumulation of component '%s'", ldr.Root())
 	}
 	return nil
 }
 
-func (kt *KustTarget) accumulateFile(
-	ra *accumulator.ResAccumulator, path string) error {
-	resources, err := kt.rFactory.FromFile(kt.ldr, path)
-	if err != nil {
-		return errors.Wrapf(err, "accumulating resources from '%s'", path)
-	}
-	err = ra.AppendAll(resources)
+// loadSubTarget returns a loaded target for the kustomization
+// at the root of the given loader, sharing this target's
+// memoized bases and settings.
+func (kt *KustTarget) loadSubTarget(ldr ifc.Loader) (*KustTarget, error) {
+	subKt := NewKustTarget(
+		ldr, kt.validator, kt.rFactory, kt.tFactory, kt.pLdr)
+	subKt.bases = kt.bases
+	subKt.preloads = kt.preloads
+	subKt.trackTransformations = kt.trackTransformations
+	subKt.traceSteps = kt.traceSteps
+	subKt.schemaValidation = kt.schemaValidation
+	subKt.strict = kt.strict
+	err := subKt.Load()
 	if err != nil {
-		return errors.Wrapf(err, "merging resources from '%s'", path)
+		return nil, errors.Wrapf(
+			err, "couldn't make target for path '%s'", ldr.Root())
 	}
-	return nil
+	return subKt, nil
 }
 
 func (kt *KustTarget) configureBuiltinPlugin(
@@ -362,7 +866,7 @@ func (kt *KustTarget) configureBuiltinPlugin(
 				err, "builtin %s marshal", bpt)
 		}
 	}
-	err = p.Config(resmap.NewPluginHelpers(kt.ldr, kt.validator, kt.rFactory), y)
+	err = p.Config(kt.pLdr.PluginHelpers(kt.ldr, kt.validator), y)
 	if err != nil {
 		return errors.Wrapf(err, "builtin %s config: %v", bpt, y)
 	}
@@ -4,6 +4,8 @@
 package target
 
 import (
+	"strings"
+
 	"sigs.k8s.io/kustomize/api/internal/plugins/builtinconfig"
 	"sigs.k8s.io/kustomize/api/internal/plugins/builtinhelpers"
 	"sigs.k8s.io/kustomize/api/resmap"
@@ -25,26 +27,35 @@ import (
 // image tag transforms.  In these cases, we'll need
 // N plugin instances with differing configurations.
 
+// configureBuiltinGenerators returns the builtin generators,
+// along with the name of the plugin type of each.
 func (kt *KustTarget) configureBuiltinGenerators() (
-	result []resmap.Generator, err error) {
+	result []resmap.Generator, names []string, err error) {
 	for _, bpt := range []builtinhelpers.BuiltinPluginType{
 		builtinhelpers.ConfigMapGenerator,
 		builtinhelpers.SecretGenerator,
+		builtinhelpers.HelmChartInflationGenerator,
 	} {
 		r, err := generatorConfigurators[bpt](
 			kt, bpt, builtinhelpers.GeneratorFactories[bpt])
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		result = append(result, r...)
+		for range r {
+			names = append(names, bpt.String())
+		}
 	}
-	return result, nil
+	return result, names, nil
 }
 
+// configureBuiltinTransformers returns the builtin transformers,
+// along with the name of the plugin type of each.
 func (kt *KustTarget) configureBuiltinTransformers(
 	tc *builtinconfig.TransformerConfig) (
-	result []resmap.Transformer, err error) {
+	result []resmap.Transformer, names []string, err error) {
 	for _, bpt := range []builtinhelpers.BuiltinPluginType{
+		builtinhelpers.ExclusionTransformer,
 		builtinhelpers.PatchStrategicMergeTransformer,
 		builtinhelpers.PatchTransformer,
 		builtinhelpers.NamespaceTransformer,
@@ -54,15 +65,19 @@ func (kt *KustTarget) configureBuiltinTransformers(
 		builtinhelpers.PatchJson6902Transformer,
 		builtinhelpers.ReplicaCountTransformer,
 		builtinhelpers.ImageTagTransformer,
+		builtinhelpers.ReplacementTransformer,
 	} {
 		r, err := transformerConfigurators[bpt](
 			kt, bpt, builtinhelpers.TransformerFactories[bpt], tc)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		result = append(result, r...)
+		for range r {
+			names = append(names, bpt.String())
+		}
 	}
-	return result, nil
+	return result, names, nil
 }
 
 type gFactory func() resmap.GeneratorPlugin
@@ -90,6 +105,23 @@ var generatorConfigurators = map[builtinhelpers.BuiltinPluginType]func(
 		return
 	},
 
+	builtinhelpers.HelmChartInflationGenerator: func(kt *KustTarget, bpt builtinhelpers.BuiltinPluginType, f gFactory) (
+		result []resmap.Generator, err error) {
+		var c struct {
+			types.HelmChart
+		}
+		for _, args := range kt.kustomization.HelmCharts {
+			c.HelmChart = args
+			p := f()
+			err := kt.configureBuiltinPlugin(p, c, bpt)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, p)
+		}
+		return
+	},
+
 	builtinhelpers.ConfigMapGenerator: func(kt *KustTarget, bpt builtinhelpers.BuiltinPluginType, f gFactory) (
 		result []resmap.Generator, err error) {
 		var c struct {
@@ -156,6 +188,26 @@ var transformerConfigurators = map[builtinhelpers.BuiltinPluginType]func(
 		}
 		return
 	},
+	builtinhelpers.ExclusionTransformer: func(
+		kt *KustTarget, bpt builtinhelpers.BuiltinPluginType, f tFactory, tc *builtinconfig.TransformerConfig) (
+		result []resmap.Transformer, err error) {
+		if len(kt.kustomization.Exclude) == 0 {
+			return
+		}
+		var c struct {
+			Selectors      []types.Selector                   `json:"selectors,omitempty" yaml:"selectors,omitempty"`
+			NameReferences []builtinconfig.NameBackReferences `json:"nameReferences,omitempty" yaml:"nameReferences,omitempty"`
+		}
+		c.Selectors = kt.kustomization.Exclude
+		c.NameReferences = tc.NameReference
+		p := f()
+		err = kt.configureBuiltinPlugin(p, c, bpt)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, p)
+		return
+	},
 	builtinhelpers.PatchStrategicMergeTransformer: func(
 		kt *KustTarget, bpt builtinhelpers.BuiltinPluginType, f tFactory, _ *builtinconfig.TransformerConfig) (
 		result []resmap.Transformer, err error) {
@@ -185,11 +237,15 @@ var transformerConfigurators = map[builtinhelpers.BuiltinPluginType]func(
 			Path   string          `json:"path,omitempty" yaml:"path,omitempty"`
 			Patch  string          `json:"patch,omitempty" yaml:"patch,omitempty"`
 			Target *types.Selector `json:"target,omitempty" yaml:"target,omitempty"`
+			Type   types.PatchType `json:"type,omitempty" yaml:"type,omitempty"`
+			Strict bool            `json:"strict,omitempty" yaml:"strict,omitempty"`
 		}
+		c.Strict = kt.strict
 		for _, pc := range kt.kustomization.Patches {
 			c.Target = pc.Target
 			c.Patch = pc.Patch
 			c.Path = pc.Path
+			c.Type = pc.Type
 			p := f()
 			err = kt.configureBuiltinPlugin(p, c, bpt)
 			if err != nil {
@@ -214,6 +270,16 @@ var transformerConfigurators = map[builtinhelpers.BuiltinPluginType]func(
 			return nil, err
 		}
 		result = append(result, p)
+		for _, label := range kt.kustomization.Labels {
+			c.Labels = label.Pairs
+			c.FieldSpecs = labelFieldSpecs(label, tc.CommonLabels)
+			p := f()
+			err = kt.configureBuiltinPlugin(p, c, bpt)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, p)
+		}
 		return
 	},
 	builtinhelpers.AnnotationsTransformer: func(
@@ -258,7 +324,9 @@ var transformerConfigurators = map[builtinhelpers.BuiltinPluginType]func(
 		var c struct {
 			ImageTag   types.Image
 			FieldSpecs []types.FieldSpec
+			Strict     bool
 		}
+		c.Strict = kt.strict
 		for _, args := range kt.kustomization.Images {
 			c.ImageTag = args
 			c.FieldSpecs = tc.Images
@@ -277,7 +345,9 @@ var transformerConfigurators = map[builtinhelpers.BuiltinPluginType]func(
 		var c struct {
 			Replica    types.Replica
 			FieldSpecs []types.FieldSpec
+			Strict     bool
 		}
+		c.Strict = kt.strict
 		for _, args := range kt.kustomization.Replicas {
 			c.Replica = args
 			c.FieldSpecs = tc.Replicas
@@ -290,4 +360,43 @@ var transformerConfigurators = map[builtinhelpers.BuiltinPluginType]func(
 		}
 		return
 	},
+	builtinhelpers.ReplacementTransformer: func(
+		kt *KustTarget, bpt builtinhelpers.BuiltinPluginType, f tFactory, _ *builtinconfig.TransformerConfig) (
+		result []resmap.Transformer, err error) {
+		if len(kt.kustomization.Replacements) == 0 {
+			return
+		}
+		var c struct {
+			Replacements []types.Replacement
+		}
+		c.Replacements = kt.kustomization.Replacements
+		p := f()
+		err = kt.configureBuiltinPlugin(p, c, bpt)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, p)
+		return
+	},
+}
+
+// labelFieldSpecs returns the fields that the pairs of
+// label go in, picked from the commonLabels fields.
+// Templates are the objects whose labels are set at a
+// path ending in metadata/labels other than the top one.
+func labelFieldSpecs(label types.Label, fss types.FsSlice) types.FsSlice {
+	if label.IncludeSelectors {
+		return fss
+	}
+	result := types.FsSlice{{Path: "metadata/labels", CreateIfNotPresent: true}}
+	if !label.IncludeTemplates {
+		return result
+	}
+	for _, fs := range fss {
+		if fs.Path != "metadata/labels" &&
+			strings.HasSuffix(fs.Path, "/metadata/labels") {
+			result = append(result, fs)
+		}
+	}
+	return result
 }
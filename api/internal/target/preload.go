@@ -0,0 +1,154 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/internal/accumulator"
+	"sigs.k8s.io/kustomize/api/internal/kusterr"
+	"sigs.k8s.io/kustomize/api/resmap"
+)
+
+// preloads holds the files and kustomizations named by the
+// kustomizations of a build, loaded before the build starts
+// accumulating them, so that the OpenAPI schemas of every
+// kustomization are known beforehand.  Entries are keyed by
+// the root of the kustomization naming them, and their path.
+type preloads struct {
+	mu      sync.Mutex
+	entries map[preloadKey]*preload
+	visited map[string]bool
+}
+
+type preloadKey struct {
+	root string
+	path string
+}
+
+// preload is a loaded file or kustomization.
+type preload struct {
+	// The resources of a file.
+	resources resmap.ResMap
+	// The error reading the path as a file,
+	// for a kustomization.
+	fileErr error
+	// The loader and target of a kustomization.
+	ldr   ifc.Loader
+	subKt *KustTarget
+}
+
+func newPreloads() *preloads {
+	return &preloads{
+		entries: make(map[preloadKey]*preload),
+		visited: make(map[string]bool),
+	}
+}
+
+func (p *preloads) put(root, path string, e *preload) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.entries[preloadKey{root: root, path: path}] = e
+}
+
+// take returns, and forgets, the entry for the given
+// path named by the kustomization at the given root,
+// or nil if it wasn't preloaded.
+func (p *preloads) take(root, path string) *preload {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	k := preloadKey{root: root, path: path}
+	e := p.entries[k]
+	delete(p.entries, k)
+	return e
+}
+
+// visit returns true if the given root
+// hasn't been visited before.
+func (p *preloads) visit(root string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.visited[root] {
+		return false
+	}
+	p.visited[root] = true
+	return true
+}
+
+// cleanup cleans up the loaders of the
+// kustomizations that were never taken.
+func (p *preloads) cleanup() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for k, e := range p.entries {
+		if e.ldr != nil {
+			e.ldr.Cleanup()
+		}
+		delete(p.entries, k)
+	}
+}
+
+// preload loads the files and kustomizations named by the
+// kustomization of the target, and, recursively, by those
+// kustomizations, returning the OpenAPI schemas they name.
+// The schemas of a kustomization follow those of the
+// kustomizations it names, so that its own take precedence.
+// Paths that fail to load are left for the accumulation to
+// report.
+func (kt *KustTarget) preload() ([]accumulator.OpenAPISchema, error) {
+	kt.preloads.visit(kt.ldr.Root())
+	k := kt.kustomization
+	var paths []string
+	paths = append(paths, k.Resources...)
+	paths = append(paths, k.Components...)
+	paths = append(paths, k.Generators...)
+	paths = append(paths, k.Transformers...)
+	schemas := make([][]accumulator.OpenAPISchema, len(paths))
+	errs := make([]error, len(paths))
+	var wg sync.WaitGroup
+	for i, path := range paths {
+		resources, errF := kt.rFactory.FromFile(kt.ldr, path)
+		if errF == nil {
+			kt.preloads.put(kt.ldr.Root(), path, &preload{resources: resources})
+			continue
+		}
+		errF = errors.Wrapf(errF, "accumulating resources from '%s'", path)
+		if kusterr.Locate(errF) != nil {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, path string, errF error) {
+			defer wg.Done()
+			ldr, err := kt.ldr.New(path)
+			if err != nil {
+				return
+			}
+			subKt, err := kt.loadSubTarget(ldr)
+			if err != nil {
+				ldr.Cleanup()
+				return
+			}
+			kt.preloads.put(kt.ldr.Root(), path, &preload{
+				fileErr: errF, ldr: ldr, subKt: subKt})
+			if kt.preloads.visit(ldr.Root()) {
+				schemas[i], errs[i] = subKt.preload()
+			}
+		}(i, path, errF)
+	}
+	wg.Wait()
+	var result []accumulator.OpenAPISchema
+	for i := range paths {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		result = append(result, schemas[i]...)
+	}
+	own, err := accumulator.LoadOpenAPISchemas(kt.ldr, k.OpenAPI)
+	if err != nil {
+		return nil, errors.Wrapf(err, "adding OpenAPI schemas %v", k.OpenAPI)
+	}
+	return append(result, own...), nil
+}
@@ -0,0 +1,308 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package validate checks resources against OpenAPI schemas,
+// those of kyaml/openapi and those of CRDs.
+package validate
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/go-openapi/spec"
+	"sigs.k8s.io/kustomize/api/resid"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// Definitions holds OpenAPI definitions by name, as read
+// from the files listed in a kustomization's crds field.
+// Definitions whose name ends in a kind, e.g.
+// github.com/example/pkg/apis/jingfang/v1beta1.Bee,
+// and that have kind, apiVersion and metadata properties,
+// are the schemas of resources of that kind.
+type Definitions map[string]spec.Schema
+
+// Merge returns the union of the definitions, preferring
+// those of other to those of the receiver.
+func (d Definitions) Merge(other Definitions) Definitions {
+	if len(other) == 0 {
+		return d
+	}
+	result := make(Definitions, len(d)+len(other))
+	for k, v := range d {
+		result[k] = v
+	}
+	for k, v := range other {
+		result[k] = v
+	}
+	return result
+}
+
+// schemaForKind returns the definition of the given kind, if any.
+func (d Definitions) schemaForKind(kind string) *spec.Schema {
+	var names []string
+	for name := range d {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if name != kind && !strings.HasSuffix(name, "."+kind) {
+			continue
+		}
+		s := d[name]
+		if isResourceSchema(&s) {
+			return &s
+		}
+	}
+	return nil
+}
+
+func isResourceSchema(s *spec.Schema) bool {
+	for _, p := range []string{"kind", "apiVersion", "metadata"} {
+		if _, ok := s.Properties[p]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Problem is a way in which a resource doesn't match its schema.
+type Problem struct {
+	Id resid.ResId
+	// Path is the path to the offending field,
+	// e.g. spec.template.spec.containers[0].image
+	Path    string
+	Message string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s: %s", p.Id, p.Path, p.Message)
+}
+
+// Resources checks each resource in m against its schema,
+// returning the problems found.  The schema of a resource is
+// that of kyaml/openapi for its apiVersion and kind, including
+// any added with openapi.AddSchemaFromFile, else that of the CRD
+// definition for its kind.  Resources with no schema aren't checked.
+func Resources(m resmap.ResMap, crds Definitions) []Problem {
+	var result []Problem
+	for _, r := range m.Resources() {
+		result = append(result, Resource(r, crds)...)
+	}
+	return result
+}
+
+// Resource checks one resource, as Resources does.
+func Resource(r *resource.Resource, crds Definitions) []Problem {
+	c := &checker{id: r.CurId(), crds: crds}
+	s := c.schemaFor(r)
+	if s == nil {
+		return nil
+	}
+	c.check("", r.Map(), s)
+	return c.problems
+}
+
+// checker accumulates the problems found in one resource.
+type checker struct {
+	id       resid.ResId
+	crds     Definitions
+	problems []Problem
+}
+
+func (c *checker) schemaFor(r *resource.Resource) *spec.Schema {
+	gvk := r.GetGvk()
+	apiVersion := gvk.Version
+	if gvk.Group != "" {
+		apiVersion = gvk.Group + "/" + gvk.Version
+	}
+	rs := openapi.SchemaForResourceType(
+		yaml.TypeMeta{APIVersion: apiVersion, Kind: gvk.Kind})
+	if !rs.IsEmpty() {
+		return rs.Schema
+	}
+	return c.crds.schemaForKind(gvk.Kind)
+}
+
+func (c *checker) report(path, format string, args ...interface{}) {
+	if path == "" {
+		path = "."
+	}
+	c.problems = append(c.problems, Problem{
+		Id: c.id, Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// resolve follows the references of s.  References are
+// resolved against the CRD definitions, then against the
+// definitions of kyaml/openapi.  It returns nil if a
+// reference can't be resolved, leaving the value unchecked.
+func (c *checker) resolve(s *spec.Schema) *spec.Schema {
+	for i := 0; s != nil && s.Ref.String() != ""; i++ {
+		if i > 100 {
+			return nil
+		}
+		ref := s.Ref.String()
+		if d, ok := c.crds[ref]; ok {
+			s = &d
+			continue
+		}
+		if !strings.HasPrefix(ref, "#/") {
+			ref = "#/definitions/" + builtinDefinitionName(ref)
+		}
+		r, err := spec.NewRef(ref)
+		if err != nil {
+			return nil
+		}
+		s, err = openapi.Resolve(&r)
+		if err != nil {
+			return nil
+		}
+	}
+	return s
+}
+
+// builtinDefinitionName converts a Go type name of the kind
+// used in CRD definitions, e.g.
+// k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta, to the name
+// of the same type in the Kubernetes OpenAPI definitions, e.g.
+// io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta.
+func builtinDefinitionName(n string) string {
+	parts := strings.Split(n, "/")
+	domain := strings.Split(parts[0], ".")
+	for i, j := 0, len(domain)-1; i < j; i, j = i+1, j-1 {
+		domain[i], domain[j] = domain[j], domain[i]
+	}
+	parts[0] = strings.Join(domain, ".")
+	return strings.Join(parts, ".")
+}
+
+func (c *checker) check(path string, v interface{}, s *spec.Schema) {
+	isQuantity := strings.HasSuffix(s.Ref.String(), ".Quantity")
+	s = c.resolve(s)
+	if s == nil || v == nil {
+		return
+	}
+	if len(s.Type) == 1 && !matchesType(v, s.Type[0], s.Format, isQuantity) {
+		c.report(path, "expected %s, found %s", s.Type[0], typeOf(v))
+		return
+	}
+	switch t := v.(type) {
+	case map[string]interface{}:
+		c.checkObject(path, t, s)
+	case []interface{}:
+		if s.Items == nil || s.Items.Schema == nil {
+			return
+		}
+		for i, e := range t {
+			c.check(fmt.Sprintf("%s[%d]", path, i), e, s.Items.Schema)
+		}
+	}
+}
+
+func (c *checker) checkObject(
+	path string, m map[string]interface{}, s *spec.Schema) {
+	for _, name := range s.Required {
+		if _, ok := m[name]; !ok {
+			c.report(join(path, name), "missing required field")
+		}
+	}
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if p, ok := s.Properties[k]; ok {
+			c.check(join(path, k), m[k], &p)
+			continue
+		}
+		if s.AdditionalProperties != nil {
+			if s.AdditionalProperties.Schema != nil {
+				c.check(join(path, k), m[k], s.AdditionalProperties.Schema)
+			}
+			continue
+		}
+		if len(s.Properties) > 0 {
+			c.report(join(path, k), "unknown field")
+		}
+	}
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// matchesType reports whether v, a value of a resource in map
+// form, has the given OpenAPI type.  Quantities and int-or-string
+// fields are typed as strings, but may be numbers too.
+func matchesType(v interface{}, t, format string, isQuantity bool) bool {
+	switch t {
+	case "string":
+		if isQuantity || format == "int-or-string" {
+			return isString(v) || isNumber(v)
+		}
+		return isString(v)
+	case "integer":
+		return isInteger(v)
+	case "number":
+		return isNumber(v)
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "object":
+		_, ok := v.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := v.([]interface{})
+		return ok
+	}
+	return true
+}
+
+func isString(v interface{}) bool {
+	_, ok := v.(string)
+	return ok
+}
+
+func isNumber(v interface{}) bool {
+	switch v.(type) {
+	case int, int32, int64, float32, float64:
+		return true
+	}
+	return false
+}
+
+func isInteger(v interface{}) bool {
+	switch t := v.(type) {
+	case int, int32, int64:
+		return true
+	case float64:
+		return t == math.Trunc(t)
+	}
+	return false
+}
+
+func typeOf(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return fmt.Sprintf("string %q", t)
+	case bool:
+		return fmt.Sprintf("boolean %v", t)
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	}
+	if isNumber(v) {
+		return fmt.Sprintf("number %v", v)
+	}
+	return fmt.Sprintf("%T", v)
+}
@@ -0,0 +1,173 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package validate_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-openapi/spec"
+	. "sigs.k8s.io/kustomize/api/internal/validate"
+	"sigs.k8s.io/kustomize/api/k8sdeps/kunstruct"
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+var rf = resource.NewFactory(kunstruct.NewKunstructuredFactoryImpl())
+
+func problems(t *testing.T, y string, crds Definitions) string {
+	r, err := rf.FromBytes([]byte(y))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result []string
+	for _, p := range Resource(r, crds) {
+		result = append(result, p.String())
+	}
+	return strings.Join(result, "\n")
+}
+
+func TestBuiltinKinds(t *testing.T) {
+	testCases := map[string]struct {
+		input    string
+		expected string
+	}{
+		"valid": {
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  replicas: 3
+  selector:
+    matchLabels:
+      app: app
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app
+        ports:
+        - containerPort: 80
+        resources:
+          limits:
+            cpu: 1
+            memory: 1Gi
+        readinessProbe:
+          httpGet:
+            port: 8080
+`,
+		},
+		"wrongType": {
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  replicas: three
+  selector:
+    matchLabels:
+      app: app
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app
+`,
+			expected: `apps_v1_Deployment|~X|app: spec.replicas: expected integer, found string "three"`,
+		},
+		"unknownAndMissing": {
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  lables:
+    app: app
+spec:
+  template:
+    spec:
+      containers:
+      - image: app
+`,
+			expected: `apps_v1_Deployment|~X|app: metadata.lables: unknown field
+apps_v1_Deployment|~X|app: spec.selector: missing required field
+apps_v1_Deployment|~X|app: spec.template.spec.containers[0].name: missing required field`,
+		},
+		"unknownKind": {
+			input: `
+apiVersion: example.com/v1
+kind: Gadget
+metadata:
+  name: app
+spec:
+  whatever: 1
+`,
+		},
+	}
+	for n, tc := range testCases {
+		if actual := problems(t, tc.input, nil); actual != tc.expected {
+			t.Errorf("%s: expected\n%s\nbut got\n%s", n, tc.expected, actual)
+		}
+	}
+}
+
+func TestCrdKinds(t *testing.T) {
+	crds := Definitions{
+		"example.com/gadgets/v1.Gadget": spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Required: []string{"spec"},
+				Properties: map[string]spec.Schema{
+					"apiVersion": *spec.StringProperty(),
+					"kind":       *spec.StringProperty(),
+					"metadata": *spec.RefSchema(
+						"k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+					"spec": *spec.RefSchema("example.com/gadgets/v1.GadgetSpec"),
+				},
+			},
+		},
+		"example.com/gadgets/v1.GadgetSpec": spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Properties: map[string]spec.Schema{
+					"size":    *spec.Int64Property(),
+					"options": *spec.MapProperty(spec.BooleanProperty()),
+				},
+			},
+		},
+	}
+	actual := problems(t, `
+apiVersion: example.com/v1
+kind: Gadget
+metadata:
+  name: gizmo
+  namespace: 7
+spec:
+  size: big
+  colour: red
+  options:
+    shiny: true
+    loud: maybe
+`, crds)
+	expected := `example.com_v1_Gadget|~X|gizmo: metadata.namespace: expected string, found number 7
+example.com_v1_Gadget|~X|gizmo: spec.colour: unknown field
+example.com_v1_Gadget|~X|gizmo: spec.options.loud: expected boolean, found string "maybe"
+example.com_v1_Gadget|~X|gizmo: spec.size: expected integer, found string "big"`
+	if actual != expected {
+		t.Fatalf("expected\n%s\nbut got\n%s", expected, actual)
+	}
+}
+
+func TestDefinitionsMerge(t *testing.T) {
+	a := Definitions{"a": *spec.StringProperty()}
+	b := Definitions{"b": *spec.StringProperty()}
+	c := a.Merge(b)
+	if len(a) != 1 || len(b) != 1 || len(c) != 2 {
+		t.Fatalf("unexpected merge: %v %v %v", a, b, c)
+	}
+	var empty Definitions
+	if len(empty.Merge(nil)) != 0 {
+		t.Fatalf("expected empty merge")
+	}
+}
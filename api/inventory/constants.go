@@ -9,4 +9,8 @@ const (
 
 	// Annotation for inventory content hash.
 	HashAnnotation = "kustomize.config.k8s.io/InventoryHash"
+
+	// Annotation that contains a hash of each object
+	// in the inventory, by the object's id.
+	ObjectHashesAnnotation = "kustomize.config.k8s.io/InventoryObjectHashes"
 )
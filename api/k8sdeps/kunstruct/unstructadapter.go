@@ -18,6 +18,10 @@ import (
 	"k8s.io/client-go/kubernetes/scheme"
 	"sigs.k8s.io/kustomize/api/ifc"
 	"sigs.k8s.io/kustomize/api/resid"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
+	"sigs.k8s.io/kustomize/kyaml/yaml/merge2"
+	"sigs.k8s.io/yaml"
 )
 
 var _ ifc.Kunstructured = &UnstructAdapter{}
@@ -286,6 +290,11 @@ func (fs *UnstructAdapter) Patch(patch ifc.Kunstructured) error {
 	merged := map[string]interface{}{}
 	saveName := fs.GetName()
 	switch {
+	case runtime.IsNotRegisteredError(err) && hasOpenAPISchema(patch):
+		merged, err = mergeUsingOpenAPISchema(fs.Map(), patch.Map())
+		if err != nil {
+			return err
+		}
 	case runtime.IsNotRegisteredError(err):
 		baseBytes, err := json.Marshal(fs.Map())
 		if err != nil {
@@ -331,6 +340,54 @@ func (fs *UnstructAdapter) Patch(patch ifc.Kunstructured) error {
 	return nil
 }
 
+// hasOpenAPISchema returns true if kyaml/openapi
+// has a schema for the kind of the given object.
+func hasOpenAPISchema(x ifc.Kunstructured) bool {
+	gvk := x.GetGvk()
+	apiVersion := gvk.Version
+	if gvk.Group != "" {
+		apiVersion = gvk.Group + "/" + gvk.Version
+	}
+	return openapi.SchemaForResourceType(
+		kyaml.TypeMeta{APIVersion: apiVersion, Kind: gvk.Kind}) != nil
+}
+
+// mergeUsingOpenAPISchema applies a strategic merge patch
+// to base, merging the lists that the kyaml/openapi schema
+// of base gives a merge key and the merge patch strategy.
+// Other lists are replaced, and fields set to null in the
+// patch are removed, as in a JSON merge patch.
+func mergeUsingOpenAPISchema(
+	base, patch map[string]interface{}) (map[string]interface{}, error) {
+	baseNode, err := toRNode(base)
+	if err != nil {
+		return nil, err
+	}
+	patchNode, err := toRNode(patch)
+	if err != nil {
+		return nil, err
+	}
+	mergedNode, err := merge2.Merge(patchNode, baseNode)
+	if err != nil {
+		return nil, err
+	}
+	y, err := mergedNode.String()
+	if err != nil {
+		return nil, err
+	}
+	merged := map[string]interface{}{}
+	err = yaml.Unmarshal([]byte(y), &merged)
+	return merged, err
+}
+
+func toRNode(m map[string]interface{}) (*kyaml.RNode, error) {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return kyaml.Parse(string(b))
+}
+
 // toSchemaGvk converts to a schema.GroupVersionKind.
 func toSchemaGvk(x resid.Gvk) schema.GroupVersionKind {
 	return schema.GroupVersionKind{
@@ -19,6 +19,10 @@ func DefaultKustomizationFileName() string {
 	return RecognizedKustomizationFileNames()[0]
 }
 
+// KustomizationLockFileName is the name of the file, beside
+// a kustomization file, recording the remote roots it loads.
+const KustomizationLockFileName = "kustomization.lock"
+
 const (
 	// An environment variable to consult for kustomization
 	// configuration data.  See:
@@ -28,6 +32,13 @@ const (
 	// Use this when XdgConfigHomeEnv not defined.
 	XdgConfigHomeEnvDefault = ".config"
 
+	// An environment variable to consult for where
+	// to cache data, e.g. clones of remote bases.
+	XdgCacheHomeEnv = "XDG_CACHE_HOME"
+
+	// Use this when XdgCacheHomeEnv not defined.
+	XdgCacheHomeEnvDefault = ".cache"
+
 	// A program name, for use in help, finding the XDG_CONFIG_DIR, etc.
 	ProgramName = "kustomize"
 )
@@ -0,0 +1,224 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeComponentBase(th kusttest_test.Harness) {
+	th.WriteK("/app/base", `
+resources:
+- deploy.yaml
+configMapGenerator:
+- name: settings
+  literals:
+  - color=blue
+`)
+	th.WriteF("/app/base/deploy.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: storefront
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: nginx
+`)
+}
+
+// The "monitoring" component adds a resource of its own,
+// patches the deployment of the including overlay, and
+// merges a setting into the overlay's generated map.
+func writeMonitoringComponent(th kusttest_test.Harness) {
+	th.WriteF("/app/components/monitoring/kustomization.yaml", `
+apiVersion: kustomize.config.k8s.io/v1alpha1
+kind: Component
+resources:
+- service.yaml
+patchesStrategicMerge:
+- sidecar.yaml
+configMapGenerator:
+- name: settings
+  behavior: merge
+  literals:
+  - metrics=on
+`)
+	th.WriteF("/app/components/monitoring/service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: metrics
+`)
+	th.WriteF("/app/components/monitoring/sidecar.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: storefront
+spec:
+  template:
+    spec:
+      containers:
+      - name: exporter
+        image: exporter
+`)
+}
+
+func TestComponent(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeComponentBase(th)
+	writeMonitoringComponent(th)
+	th.WriteK("/app/prod", `
+namePrefix: prod-
+resources:
+- ../base
+components:
+- ../components/monitoring
+`)
+	m := th.Run("/app/prod", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: prod-storefront
+spec:
+  template:
+    spec:
+      containers:
+      - image: exporter
+        name: exporter
+      - image: nginx
+        name: app
+---
+apiVersion: v1
+data:
+  color: blue
+  metrics: "on"
+kind: ConfigMap
+metadata:
+  annotations: {}
+  labels: {}
+  name: prod-settings-b92687tfbd
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: prod-metrics
+`)
+}
+
+// Components are applied in the order listed, each
+// seeing the changes made by those before it.
+func TestComponentsInOrder(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeComponentBase(th)
+	th.WriteF("/app/components/ha/kustomization.yaml", `
+kind: Component
+replicas:
+- name: storefront
+  count: 3
+`)
+	th.WriteF("/app/components/canary/kustomization.yaml", `
+kind: Component
+nameSuffix: -canary
+commonLabels:
+  track: canary
+`)
+	th.WriteK("/app/overlay", `
+resources:
+- ../base
+components:
+- ../components/ha
+- ../components/canary
+`)
+	m := th.Run("/app/overlay", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    track: canary
+  name: storefront-canary
+spec:
+  replicas: 3
+  selector:
+    matchLabels:
+      track: canary
+  template:
+    metadata:
+      labels:
+        track: canary
+    spec:
+      containers:
+      - image: nginx
+        name: app
+---
+apiVersion: v1
+data:
+  color: blue
+kind: ConfigMap
+metadata:
+  labels:
+    track: canary
+  name: settings-canary-5hcb4tm27m
+`)
+}
+
+func TestComponentInResourcesIsAnError(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeMonitoringComponent(th)
+	th.WriteK("/app/overlay", `
+resources:
+- ../components/monitoring
+`)
+	err := th.RunWithErr("/app/overlay", th.MakeDefaultOptions())
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if !strings.Contains(err.Error(), "expected kind != 'Component'") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestKustomizationInComponentsIsAnError(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeComponentBase(th)
+	th.WriteK("/app/overlay", `
+components:
+- ../base
+`)
+	err := th.RunWithErr("/app/overlay", th.MakeDefaultOptions())
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if !strings.Contains(err.Error(),
+		"expected kind 'Component' for path '/app/base' but got 'Kustomization'") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestComponentWrongApiVersion(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteF("/app/components/bad/kustomization.yaml", `
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Component
+`)
+	th.WriteK("/app/overlay", `
+components:
+- ../components/bad
+`)
+	err := th.RunWithErr("/app/overlay", th.MakeDefaultOptions())
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if !strings.Contains(err.Error(),
+		"apiVersion for Component should be kustomize.config.k8s.io/v1alpha1") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+// readCountingFs counts successful reads per path.
+type readCountingFs struct {
+	filesys.FileSystem
+	mu    sync.Mutex
+	reads map[string]int
+}
+
+func (fs *readCountingFs) ReadFile(path string) ([]byte, error) {
+	b, err := fs.FileSystem.ReadFile(path)
+	if err == nil {
+		fs.mu.Lock()
+		fs.reads[path]++
+		fs.mu.Unlock()
+	}
+	return b, err
+}
+
+// Many sibling bases are built concurrently, but
+// the output must respect the order in which the
+// bases are listed.
+func TestSiblingBasesOutputOrder(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	var resources, expected []string
+	for i := 0; i < 20; i++ {
+		th.WriteK(fmt.Sprintf("/app/base%02d", i), `
+resources:
+- map.yaml
+`)
+		th.WriteF(fmt.Sprintf("/app/base%02d/map.yaml", i), fmt.Sprintf(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: map%02d
+`, i))
+		resources = append(resources, fmt.Sprintf("- base%02d", i))
+		expected = append(expected, fmt.Sprintf(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: map%02d
+`, i))
+	}
+	th.WriteK("/app", `
+resources:
+`+strings.Join(resources, "\n"))
+	opts := th.MakeDefaultOptions()
+	m := th.Run("/app", opts)
+	th.AssertActualEqualsExpected(m, strings.Join(expected, "---\n"))
+}
+
+// A base reached via several paths is built once,
+// yet each referrer gets its own copy to transform.
+//
+//	      app
+//	    /     \
+//	left       right
+//	    \     /
+//	     base
+func TestDiamondBaseBuiltOnce(t *testing.T) {
+	fSys := &readCountingFs{
+		FileSystem: filesys.MakeFsInMemory(),
+		reads:      make(map[string]int),
+	}
+	th := kusttest_test.MakeHarnessWithFs(t, fSys)
+	th.WriteK("/app/base", `
+resources:
+- deploy.yaml
+`)
+	th.WriteF("/app/base/deploy.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: storefront
+`)
+	th.WriteK("/app/left", `
+namePrefix: left-
+resources:
+- ../base
+`)
+	th.WriteK("/app/right", `
+namePrefix: right-
+resources:
+- ../base
+`)
+	th.WriteK("/app", `
+resources:
+- left
+- right
+`)
+	opts := th.MakeDefaultOptions()
+	m := th.Run("/app", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: left-storefront
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: right-storefront
+`)
+	if n := fSys.reads["/app/base/deploy.yaml"]; n != 1 {
+		t.Fatalf("expected base to be read once, got %d reads", n)
+	}
+}
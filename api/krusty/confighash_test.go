@@ -0,0 +1,234 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/resmap"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeConfigHashBase(th kusttest_test.Harness) {
+	th.WriteK("/app/base", `
+configHash: {}
+resources:
+- resources.yaml
+`)
+	th.WriteF("/app/base/resources.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  mode: fast
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: token
+type: Opaque
+data:
+  token: c2VjcmV0
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    metadata:
+      labels:
+        app: app
+    spec:
+      containers:
+      - name: app
+        image: app
+        envFrom:
+        - secretRef:
+            name: token
+      volumes:
+      - name: config
+        configMap:
+          name: settings
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: debug
+spec:
+  containers:
+  - name: debug
+    image: debug
+  volumes:
+  - name: config
+    configMap:
+      name: settings
+`)
+}
+
+// The hash covers the changes made by overlays, and pods
+// aren't annotated, since they have no pod template.
+func TestConfigHash(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeConfigHashBase(th)
+	th.WriteK("/app/overlay", `
+namePrefix: dev-
+resources:
+- ../base
+patchesStrategicMerge:
+- patch.yaml
+`)
+	th.WriteF("/app/overlay/patch.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  mode: slow
+`)
+	m := th.Run("/app/base", th.MakeDefaultOptions())
+	annotations, err := m.Resources()[2].GetStringMap(
+		"spec.template.metadata.annotations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h := annotations["kustomize.config.k8s.io/config-hash"]; h != "8b9t864cd6" {
+		t.Fatalf("unexpected hash %s", h)
+	}
+	m = th.Run("/app/overlay", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+data:
+  mode: slow
+kind: ConfigMap
+metadata:
+  name: dev-settings
+---
+apiVersion: v1
+data:
+  token: c2VjcmV0
+kind: Secret
+metadata:
+  name: dev-token
+type: Opaque
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: dev-app
+spec:
+  template:
+    metadata:
+      annotations:
+        kustomize.config.k8s.io/config-hash: m6hm8g47mk
+      labels:
+        app: app
+    spec:
+      containers:
+      - envFrom:
+        - secretRef:
+            name: dev-token
+        image: app
+        name: app
+      volumes:
+      - configMap:
+          name: dev-settings
+        name: config
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: dev-debug
+spec:
+  containers:
+  - image: debug
+    name: debug
+  volumes:
+  - configMap:
+      name: dev-settings
+    name: config
+`)
+}
+
+func TestConfigHashAnnotation(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeConfigHashBase(th)
+	th.WriteK("/app/overlay", `
+configHash:
+  annotation: example.com/config-hash
+resources:
+- ../base
+`)
+	m := th.Run("/app/overlay", th.MakeDefaultOptions())
+	annotations := m.Resources()[2].GetAnnotations()
+	if len(annotations) != 0 {
+		t.Fatalf("unexpected annotations %v", annotations)
+	}
+	templateAnnotations, err := m.Resources()[2].GetStringMap(
+		"spec.template.metadata.annotations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(templateAnnotations) != 1 ||
+		templateAnnotations["example.com/config-hash"] == "" {
+		t.Fatalf("unexpected annotations %v", templateAnnotations)
+	}
+}
+
+// A base's configHash covers only the resources of the base;
+// an overlay's covers its own and those of its bases.
+func TestConfigHashScope(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeConfigHashBase(th)
+	th.WriteK("/app/overlay", `
+resources:
+- ../base
+- worker.yaml
+`)
+	th.WriteF("/app/overlay/worker.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: worker
+spec:
+  template:
+    spec:
+      volumes:
+      - name: config
+        configMap:
+          name: settings
+`)
+	th.WriteK("/app/prod", `
+configHash:
+  annotation: example.com/config-hash
+resources:
+- ../overlay
+`)
+	templateAnnotations := func(m resmap.ResMap, name string) map[string]string {
+		for _, r := range m.Resources() {
+			if r.GetKind() == "Deployment" && r.GetName() == name {
+				a, _ := r.GetStringMap("spec.template.metadata.annotations")
+				return a
+			}
+		}
+		t.Fatalf("no Deployment %s", name)
+		return nil
+	}
+	m := th.Run("/app/overlay", th.MakeDefaultOptions())
+	if a := templateAnnotations(m, "app"); len(a) != 1 ||
+		a["kustomize.config.k8s.io/config-hash"] == "" {
+		t.Fatalf("unexpected annotations of app %v", a)
+	}
+	if a := templateAnnotations(m, "worker"); len(a) != 0 {
+		t.Fatalf("unexpected annotations of worker %v", a)
+	}
+	m = th.Run("/app/prod", th.MakeDefaultOptions())
+	for _, name := range []string{"app", "worker"} {
+		if a := templateAnnotations(m, name); len(a) != 1 ||
+			a["example.com/config-hash"] == "" {
+			t.Fatalf("unexpected annotations of %s %v", name, a)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty
+
+import "sigs.k8s.io/kustomize/api/internal/kusterr"
+
+// LocateError returns err prefixed with the place, in a
+// kustomization or resource file, of the most specific error
+// it wraps that knows it, e.g. an unknown field in a
+// kustomization file.  Its message has the form
+// path:line:col: message, like a compiler's, the message
+// keeping the context of every wrap.  If there's no such
+// error, err is returned unchanged.
+func LocateError(err error) error {
+	return kusterr.Hoist(err)
+}
@@ -0,0 +1,88 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestExcludeFromBase(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app/base", `
+namePrefix: base-
+resources:
+- resources.yaml
+`)
+	th.WriteF("/app/base/resources.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+---
+apiVersion: policy/v1beta1
+kind: PodDisruptionBudget
+metadata:
+  name: app
+---
+apiVersion: policy/v1beta1
+kind: PodDisruptionBudget
+metadata:
+  name: other
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: debug
+  labels:
+    env: dev
+`)
+	th.WriteK("/app/overlay", `
+resources:
+- ../base
+exclude:
+- kind: PodDisruptionBudget
+- labelSelector: env=dev
+`)
+	m := th.Run("/app/overlay", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: base-app
+`)
+}
+
+// Resources are excluded before the kustomization's
+// other transformers run, so their selectors match
+// the names the resources have in the base.
+func TestExcludeBeforeTransformers(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+namePrefix: prod-
+resources:
+- resources.yaml
+exclude:
+- name: debug
+`)
+	th.WriteF("/app/resources.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: debug
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+`)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: prod-settings
+`)
+}
@@ -0,0 +1,129 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestExplain(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app/base", `
+namePrefix: b-
+resources:
+- deploy.yaml
+`)
+	th.WriteF("/app/base/deploy.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: storefront
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: nginx
+        envFrom:
+        - configMapRef:
+            name: settings
+`)
+	th.WriteK("/app/overlay", `
+resources:
+- ../base
+images:
+- name: nginx
+  newTag: "1.17"
+configMapGenerator:
+- name: settings
+  literals:
+  - color=blue
+`)
+	opts := th.MakeDefaultOptions()
+	k := krusty.MakeKustomizer(th.GetFSys(), &opts)
+	m, trace, err := k.Explain("/app/overlay")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m.Size() != 2 {
+		t.Fatalf("expected 2 resources, got %d", m.Size())
+	}
+	var b strings.Builder
+	err = trace.WriteText(&b)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `step 1: NamespaceTransformer (root: ../base)
+  no changes
+step 2: PrefixSuffixTransformer (root: ../base)
+  changed apps_v1_Deployment|~X|b-storefront
+    metadata.name: "storefront" -> "b-storefront"
+step 3: LabelTransformer (root: ../base)
+  no changes
+step 4: AnnotationsTransformer (root: ../base)
+  no changes
+step 5: ConfigMapGenerator (root: .)
+  added ~G_v1_ConfigMap|~X|settings
+step 6: NamespaceTransformer (root: .)
+  no changes
+step 7: PrefixSuffixTransformer (root: .)
+  no changes
+step 8: LabelTransformer (root: .)
+  no changes
+step 9: AnnotationsTransformer (root: .)
+  no changes
+step 10: ImageTagTransformer (root: .)
+  changed apps_v1_Deployment|~X|b-storefront
+    spec.template.spec.containers[0].image: "nginx" -> "nginx:1.17"
+step 11: HashTransformer (root: .)
+  changed ~G_v1_ConfigMap|~X|settings-788gth9fg6
+    metadata.name: "settings" -> "settings-788gth9fg6"
+step 12: NameReferenceTransformer (root: .)
+  changed apps_v1_Deployment|~X|b-storefront
+    spec.template.spec.containers[0].envFrom[0].configMapRef.name: "settings" -> "settings-788gth9fg6"
+step 13: RefVarTransformer (root: .)
+  no changes
+`
+	if b.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, b.String())
+	}
+}
+
+// Explaining a build doesn't change its output.
+func TestExplainSameAsRun(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+namePrefix: p-
+commonLabels:
+  app: storefront
+configMapGenerator:
+- name: settings
+  literals:
+  - color=blue
+`)
+	opts := th.MakeDefaultOptions()
+	k := krusty.MakeKustomizer(th.GetFSys(), &opts)
+	m, trace, err := k.Explain("/app")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(trace.Steps) == 0 {
+		t.Fatalf("expected a trace")
+	}
+	explained, err := m.AsYaml()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ran, err := th.Run("/app", opts).AsYaml()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(explained) != string(ran) {
+		t.Fatalf("expected:\n%s\ngot:\n%s", ran, explained)
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+func writeStarlarkFunction(th kusttest_test.Harness) {
+	th.WriteK("/app", `
+resources:
+- deployment.yaml
+transformers:
+- scaler.yaml
+`)
+	th.WriteF("/app/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+`)
+	th.WriteF("/app/scaler.yaml", `
+apiVersion: someteam.example.com/v1
+kind: Scaler
+metadata:
+  name: scaler
+  annotations:
+    config.kubernetes.io/function: |
+      starlark:
+        path: scale.star
+replicas: 3
+`)
+	th.WriteF("/app/scale.star", `
+def scale(resource_list):
+  for r in resource_list["items"]:
+    if r["kind"] == "Deployment":
+      r["spec"]["replicas"] = resource_list["functionConfig"]["replicas"]
+
+scale(ctx.resource_list)
+`)
+}
+
+func TestStarlarkFunctionTransformer(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeStarlarkFunction(th)
+	opts := th.MakeDefaultOptions()
+	opts.PluginConfig.PluginRestrictions = types.PluginRestrictionsNone
+	opts.PluginConfig.FnpLoadingOptions.EnableStar = true
+	m := th.Run("/app", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+`)
+}
+
+func TestStarlarkFunctionsDisabled(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeStarlarkFunction(th)
+	opts := th.MakeDefaultOptions()
+	opts.PluginConfig.PluginRestrictions = types.PluginRestrictionsNone
+	err := th.RunWithErr("/app", opts)
+	if err == nil || !strings.Contains(err.Error(),
+		"starlark functions disabled; unable to run script scale.star") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestStarlarkFunctionsRestricted(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeStarlarkFunction(th)
+	opts := th.MakeDefaultOptions()
+	opts.PluginConfig.FnpLoadingOptions.EnableStar = true
+	err := th.RunWithErr("/app", opts)
+	if !types.IsErrOnlyBuiltinPluginsAllowed(err) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// fakeHelm renders a Deployment named by the
+// release, with replicas from the values file.
+const fakeHelm = `#!/bin/sh
+name=$2
+while [ $# -gt 0 ]; do
+  case "$1" in
+  --values) values=$2 ;;
+  esac
+  shift
+done
+cat <<EOF
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: $name-minecraft
+spec:
+  $(cat "$values")
+  template:
+    spec:
+      containers:
+      - name: minecraft
+        image: minecraft
+EOF
+`
+
+// writeFakeHelm writes the given script to a temporary
+// directory, returning the options of a build running it
+// as helm, and a func that removes it.
+func writeFakeHelm(
+	t *testing.T, th kusttest_test.Harness, script string) (krusty.Options, func()) {
+	dir, err := ioutil.TempDir("", "kustomize-helm-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	helm := filepath.Join(dir, "helm")
+	err = ioutil.WriteFile(helm, []byte(script), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := th.MakeDefaultOptions()
+	o.PluginConfig.HelmConfig = types.HelmConfig{
+		Enabled: true, Command: helm}
+	return o, func() { os.RemoveAll(dir) }
+}
+
+func TestHelmChartsWithPatch(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	o, cleanup := writeFakeHelm(t, th, fakeHelm)
+	defer cleanup()
+	th.WriteF("/app/charts/minecraft/Chart.yaml", `
+name: minecraft
+`)
+	th.WriteF("/app/values.yaml", `replicas: 2`)
+	th.WriteK("/app", `
+namePrefix: games-
+helmCharts:
+- chart: charts/minecraft
+  releaseName: moria
+  valuesFile: values.yaml
+patchesStrategicMerge:
+- |-
+  apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    name: moria-minecraft
+  spec:
+    template:
+      spec:
+        containers:
+        - name: minecraft
+          image: minecraft:1.15
+`)
+	m := th.Run("/app", o)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: games-moria-minecraft
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+      - image: minecraft:1.15
+        name: minecraft
+`)
+}
+
+func TestHelmChartsMissingChart(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	o, cleanup := writeFakeHelm(t, th, fakeHelm)
+	defer cleanup()
+	th.WriteK("/app", `
+helmCharts:
+- chart: charts/minecraft
+`)
+	err := th.RunWithErr("/app", o)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if !strings.Contains(err.Error(), "loading chart 'charts/minecraft'") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Charts aren't inflated unless helm is enabled.
+func TestHelmChartsDisabled(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteF("/app/charts/minecraft/Chart.yaml", `
+name: minecraft
+`)
+	th.WriteK("/app", `
+helmCharts:
+- chart: charts/minecraft
+`)
+	err := th.RunWithErr("/app", th.MakeDefaultOptions())
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if !strings.Contains(err.Error(), "helm charts aren't enabled") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Helm runs within the limits of exec plugins.
+func TestHelmChartsTimeout(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	o, cleanup := writeFakeHelm(t, th, `#!/bin/sh
+sleep 10
+`)
+	defer cleanup()
+	o.PluginConfig.ExecpOptions.Timeout = 200 * time.Millisecond
+	th.WriteF("/app/charts/minecraft/Chart.yaml", `
+name: minecraft
+`)
+	th.WriteK("/app", `
+helmCharts:
+- chart: charts/minecraft
+`)
+	err := th.RunWithErr("/app", o)
+	e, ok := types.AsErrExecPlugin(err)
+	if !ok {
+		t.Fatalf("expected an ErrExecPlugin, got %v", err)
+	}
+	if e.Reason != "timed out after 200ms" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeInventoryBase(th kusttest_test.Harness, inventory string) {
+	th.WriteK("/app", `
+namespace: apps
+resources:
+- service.yaml
+`+inventory)
+	th.WriteF("/app/service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`)
+}
+
+func TestInventorySecretCrossNamespace(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeInventoryBase(th, `
+inventory:
+  type: Secret
+  secret:
+    name: web-inventory
+    namespace: inventories
+  allowCrossNamespace: true
+`)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: apps
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  annotations:
+    kustomize.config.k8s.io/Inventory: '{"current":{"~G_v1_Service|apps|web":null}}'
+    kustomize.config.k8s.io/InventoryHash: kg26hbc6dk
+    kustomize.config.k8s.io/InventoryObjectHashes: '{"~G_v1_Service|apps|web":"8k8mdc257d"}'
+  name: web-inventory
+  namespace: inventories
+type: Opaque
+`)
+}
+
+func TestInventoryCustom(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeInventoryBase(th, `
+inventory:
+  type: Custom
+  custom:
+    apiVersion: example.com/v1
+    kind: Inventory
+    name: web-inventory
+    namespace: apps
+`)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: apps
+---
+apiVersion: example.com/v1
+kind: Inventory
+metadata:
+  annotations:
+    kustomize.config.k8s.io/Inventory: '{"current":{"~G_v1_Service|apps|web":null}}'
+    kustomize.config.k8s.io/InventoryHash: kg26hbc6dk
+    kustomize.config.k8s.io/InventoryObjectHashes: '{"~G_v1_Service|apps|web":"8k8mdc257d"}'
+  name: web-inventory
+  namespace: apps
+`)
+}
+
+func TestInventoryErrors(t *testing.T) {
+	testCases := map[string]struct {
+		inventory string
+		expected  string
+	}{
+		"namespaceMismatch": {
+			inventory: `
+inventory:
+  type: ConfigMap
+  configMap:
+    name: web-inventory
+    namespace: inventories
+`,
+			expected: "inventory namespace 'inventories' differs " +
+				"from kustomization namespace 'apps'",
+		},
+		"unknownType": {
+			inventory: `
+inventory:
+  type: App
+`,
+			expected: "unknown inventory type 'App'",
+		},
+		"customWithoutKind": {
+			inventory: `
+inventory:
+  type: Custom
+  custom:
+    name: web-inventory
+    namespace: apps
+`,
+			expected: "inventory of type Custom requires an apiVersion and kind",
+		},
+	}
+	for n, tc := range testCases {
+		t.Run(n, func(t *testing.T) {
+			th := kusttest_test.MakeHarness(t)
+			writeInventoryBase(th, tc.inventory)
+			err := th.RunWithErr("/app", th.MakeDefaultOptions())
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			if !strings.Contains(err.Error(), tc.expected) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -4,8 +4,13 @@
 package krusty
 
 import (
-	"sigs.k8s.io/kustomize/api/builtins"
+	"fmt"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/explain"
 	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/gitcache"
 	"sigs.k8s.io/kustomize/api/internal/k8sdeps/transformer"
 	pLdr "sigs.k8s.io/kustomize/api/internal/plugins/loader"
 	"sigs.k8s.io/kustomize/api/internal/target"
@@ -15,6 +20,7 @@ import (
 	"sigs.k8s.io/kustomize/api/resmap"
 	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
 )
 
 // Kustomizer performs kustomizations.  It's meant to behave
@@ -44,19 +50,81 @@ func MakeKustomizer(fSys filesys.FileSystem, o *Options) *Kustomizer {
 // internal filesystem.  One may call Run any number of times,
 // on any number of internal paths (e.g. the filesystem may contain
 // multiple overlays, and Run can be called on each of them).
+//
+// If the kustomization at the path has a lock file, the
+// remote roots it loads must match those in the lock.
 func (b *Kustomizer) Run(path string) (resmap.ResMap, error) {
+	locker, err := b.lockChecker(path)
+	if err != nil {
+		return nil, err
+	}
+	m, _, err := b.run(path, locker, false)
+	return m, err
+}
+
+// Explain performs a kustomization as Run does, and also
+// returns a trace of each generator and transformer run,
+// showing how it changed the resources.  Kustomization
+// roots in the trace are relative to the given path.
+func (b *Kustomizer) Explain(path string) (
+	resmap.ResMap, *explain.Trace, error) {
+	locker, err := b.lockChecker(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return b.run(path, locker, true)
+}
+
+// Lock performs a kustomization as Run does, ignoring any
+// lock file, and returns a lock recording the remote roots
+// loaded, e.g. remote bases, to be written beside the
+// kustomization file at the path.
+func (b *Kustomizer) Lock(path string) (*types.KustomizationLock, error) {
+	locker := fLdr.NewLockRecorder()
+	_, _, err := b.run(path, locker, false)
+	if err != nil {
+		return nil, err
+	}
+	return locker.Lock(), nil
+}
+
+// lockChecker returns a Locker checking remote roots against
+// the lock file at the given path, or nil, if there's none.
+func (b *Kustomizer) lockChecker(path string) (*fLdr.Locker, error) {
+	if !b.fSys.IsDir(path) {
+		return nil, nil
+	}
+	lock, err := fLdr.ReadLock(b.fSys, path)
+	if err != nil || lock == nil {
+		return nil, err
+	}
+	return fLdr.NewLockChecker(lock), nil
+}
+
+func (b *Kustomizer) run(
+	path string, locker *fLdr.Locker, doTrace bool) (
+	resmap.ResMap, *explain.Trace, error) {
 	pf := transformer.NewFactoryImpl()
 	rf := resmap.NewFactory(
 		resource.NewFactory(
 			kunstruct.NewKunstructuredFactoryImpl()),
 		pf)
-	lr := fLdr.RestrictionNone
-	if b.options.LoadRestrictions == types.LoadRestrictionsRootOnly {
-		lr = fLdr.RestrictionRootOnly
+	lr, err := b.loadRestrictor()
+	if err != nil {
+		return nil, nil, err
 	}
-	ldr, err := fLdr.NewLoader(lr, path, b.fSys)
+	if b.options.Offline && b.options.RemoteCacheDir == "" {
+		return nil, nil, fmt.Errorf(
+			"offline builds require a remote cache directory")
+	}
+	ldr, err := fLdr.NewLoaderWithRemoteOptions(
+		lr, path, b.fSys, fLdr.RemoteOptions{
+			Cache:   b.remoteCache(),
+			Offline: b.options.Offline,
+			Locker:  locker,
+		})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer ldr.Cleanup()
 	kt := target.NewKustTarget(
@@ -68,8 +136,18 @@ func (b *Kustomizer) Run(path string) (resmap.ResMap, error) {
 	)
 	err = kt.Load()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
+	if b.options.AddProvenanceAnnotations {
+		kt.EnableTransformationTracking()
+	}
+	if doTrace {
+		kt.EnableTrace()
+	}
+	if b.options.Strict {
+		kt.EnableStrictMode()
+	}
+	kt.SetSchemaValidation(b.options.SchemaValidation)
 	var m resmap.ResMap
 	if b.options.DoPrune {
 		m, err = kt.MakePruneConfigMap()
@@ -77,10 +155,120 @@ func (b *Kustomizer) Run(path string) (resmap.ResMap, error) {
 		m, err = kt.MakeCustomizedResMap()
 	}
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	if so := b.sortOptions(kt); so != nil {
+		err = kt.Sort(m, so)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if b.options.AddProvenanceAnnotations {
+		err = addProvenanceAnnotations(m, ldr.Root())
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if !doTrace {
+		return m, nil, nil
+	}
+	trace := kt.Trace()
+	for i := range trace.Steps {
+		trace.Steps[i].Root = relativeRoot(ldr.Root(), trace.Steps[i].Root)
+	}
+	return m, trace, nil
+}
+
+// sortOptions returns the options for ordering the output of kt,
+// or nil if it's to be left in the order the kustomizations list it.
+func (b *Kustomizer) sortOptions(kt *target.KustTarget) *types.SortOptions {
+	if b.options.SortOptions != nil {
+		return b.options.SortOptions
+	}
+	if so := kt.SortOptions(); so != nil {
+		return so
 	}
 	if b.options.DoLegacyResourceSort {
-		builtins.NewLegacyOrderTransformerPlugin().Transform(m)
+		return &types.SortOptions{Order: types.LegacySortOrder}
+	}
+	return nil
+}
+
+// addProvenanceAnnotations writes the origin and transformations
+// recorded on each resource into its annotations.  Kustomization
+// roots are made relative to the root of the build, so that the
+// output doesn't depend on where the build ran.
+func addProvenanceAnnotations(m resmap.ResMap, root string) error {
+	for _, r := range m.Resources() {
+		annotations := r.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		if o := r.GetOrigin(); o != nil {
+			origin := *o
+			origin.Root = relativeRoot(root, origin.Root)
+			y, err := yaml.Marshal(origin)
+			if err != nil {
+				return err
+			}
+			annotations[resource.OriginAnnotation] = string(y)
+		}
+		if ts := r.GetTransformations(); len(ts) > 0 {
+			var transformations []resource.Transformation
+			for _, t := range ts {
+				t.Root = relativeRoot(root, t.Root)
+				transformations = append(transformations, t)
+			}
+			y, err := yaml.Marshal(transformations)
+			if err != nil {
+				return err
+			}
+			annotations[resource.TransformationsAnnotation] = string(y)
+		}
+		r.SetAnnotations(annotations)
+	}
+	return nil
+}
+
+func relativeRoot(buildRoot, root string) string {
+	rel, err := filepath.Rel(buildRoot, root)
+	if err != nil {
+		return root
+	}
+	return rel
+}
+
+// remoteCache returns the cache of remote
+// git repos, or nil if there's none.
+func (b *Kustomizer) remoteCache() *gitcache.Cache {
+	if b.options.RemoteCacheDir == "" {
+		return nil
+	}
+	return gitcache.New(b.options.RemoteCacheDir)
+}
+
+// loadRestrictor returns the function
+// restricting what files may be loaded.
+func (b *Kustomizer) loadRestrictor() (fLdr.LoadRestrictorFunc, error) {
+	switch b.options.LoadRestrictions {
+	case types.LoadRestrictionsRootOnly:
+		return fLdr.RestrictionRootOnly, nil
+	case types.LoadRestrictionsAllowList:
+		var allowed []filesys.ConfirmedDir
+		for _, root := range b.options.AllowedLoadRoots {
+			d, f, err := b.fSys.CleanedAbs(root)
+			if err != nil {
+				return nil, errors.Wrapf(
+					err, "allowed load root '%s'", root)
+			}
+			if f != "" {
+				return nil, fmt.Errorf(
+					"allowed load root '%s' must be a directory", root)
+			}
+			allowed = append(allowed, d)
+		}
+		return fLdr.RestrictionAllowList(allowed), nil
+	default:
+		return fLdr.RestrictionNone, nil
 	}
-	return m, nil
 }
@@ -0,0 +1,181 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeLabelsResources(th kusttest_test.Harness) {
+	th.WriteF("/app/resources.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  selector:
+    matchLabels:
+      app: app
+  template:
+    metadata:
+      labels:
+        app: app
+    spec:
+      containers:
+      - name: app
+        image: app
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+spec:
+  selector:
+    app: app
+`)
+}
+
+func TestLabelsMetadataOnly(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeLabelsResources(th)
+	th.WriteK("/app", `
+resources:
+- resources.yaml
+labels:
+- pairs:
+    team: bees
+`)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    team: bees
+  name: app
+spec:
+  selector:
+    matchLabels:
+      app: app
+  template:
+    metadata:
+      labels:
+        app: app
+    spec:
+      containers:
+      - image: app
+        name: app
+---
+apiVersion: v1
+kind: Service
+metadata:
+  labels:
+    team: bees
+  name: app
+spec:
+  selector:
+    app: app
+`)
+}
+
+func TestLabelsIncludeTemplates(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeLabelsResources(th)
+	th.WriteK("/app", `
+resources:
+- resources.yaml
+labels:
+- pairs:
+    team: bees
+  includeTemplates: true
+`)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    team: bees
+  name: app
+spec:
+  selector:
+    matchLabels:
+      app: app
+  template:
+    metadata:
+      labels:
+        app: app
+        team: bees
+    spec:
+      containers:
+      - image: app
+        name: app
+---
+apiVersion: v1
+kind: Service
+metadata:
+  labels:
+    team: bees
+  name: app
+spec:
+  selector:
+    app: app
+`)
+}
+
+// Each entry is applied with its own options,
+// and an entry including selectors acts like
+// commonLabels.
+func TestLabelsIncludeSelectors(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeLabelsResources(th)
+	th.WriteK("/app", `
+resources:
+- resources.yaml
+labels:
+- pairs:
+    team: bees
+- pairs:
+    tier: web
+  includeSelectors: true
+`)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    team: bees
+    tier: web
+  name: app
+spec:
+  selector:
+    matchLabels:
+      app: app
+      tier: web
+  template:
+    metadata:
+      labels:
+        app: app
+        tier: web
+    spec:
+      containers:
+      - image: app
+        name: app
+---
+apiVersion: v1
+kind: Service
+metadata:
+  labels:
+    team: bees
+    tier: web
+  name: app
+spec:
+  selector:
+    app: app
+    tier: web
+`)
+}
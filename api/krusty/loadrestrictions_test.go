@@ -0,0 +1,103 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"strings"
+	"testing"
+
+	. "sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+func writeAppUsingCommon(th kusttest_test.Harness) {
+	th.WriteF("/common/labels.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: common
+  labels:
+    team: web
+`)
+	th.WriteF("/secret/passwords.yaml", `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: passwords
+`)
+	th.WriteF("/app/service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`)
+}
+
+func allowListOptions(th kusttest_test.Harness, roots ...string) Options {
+	o := th.MakeDefaultOptions()
+	o.LoadRestrictions = types.LoadRestrictionsAllowList
+	o.AllowedLoadRoots = roots
+	return o
+}
+
+func TestLoadRestrictionsAllowList(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeAppUsingCommon(th)
+	th.WriteK("/app", `
+resources:
+- service.yaml
+- ../common/labels.yaml
+`)
+	m := th.Run("/app", allowListOptions(th, "/common"))
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  labels:
+    team: web
+  name: common
+`)
+}
+
+func TestLoadRestrictionsAllowListOutOfBounds(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeAppUsingCommon(th)
+	th.WriteK("/app", `
+resources:
+- service.yaml
+- ../secret/passwords.yaml
+`)
+	err := th.RunWithErr("/app", allowListOptions(th, "/common"))
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if !strings.Contains(err.Error(),
+		"file '/secret/passwords.yaml' is not in or below '/app', "+
+			"nor any of the allowed roots [/common]") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestLoadRestrictionsAllowListRootNotADirectory(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeAppUsingCommon(th)
+	th.WriteK("/app", `
+resources:
+- service.yaml
+`)
+	err := th.RunWithErr("/app", allowListOptions(th, "/common/labels.yaml"))
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if !strings.Contains(err.Error(),
+		"allowed load root '/common/labels.yaml' must be a directory") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
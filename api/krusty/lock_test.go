@@ -0,0 +1,89 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+	. "sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/loader"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestLockRemoteBase(t *testing.T) {
+	th := kusttest_test.MakeHarnessWithFs(t, filesys.MakeFsOnDisk())
+	dir := makeTmpDir(t)
+	defer os.RemoveAll(dir)
+	url := makeRemoteBase(t, th, dir)
+	app := filepath.Join(dir, "app")
+	if err := th.GetFSys().MkdirAll(app); err != nil {
+		t.Fatal(err)
+	}
+	th.WriteK(app, `
+resources:
+- `+url+`//base?ref=main
+`)
+	opts := th.MakeDefaultOptions()
+	opts.RemoteCacheDir = filepath.Join(dir, "cache")
+
+	lock, err := MakeKustomizer(th.GetFSys(), &opts).Lock(app)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(lock.Remotes) != 1 ||
+		lock.Remotes[0].URL != url+"//base?ref=main" ||
+		len(lock.Remotes[0].Commit) != 40 ||
+		!strings.HasPrefix(lock.Remotes[0].Digest, "sha256:") {
+		t.Fatalf("unexpected lock: %v", lock)
+	}
+	err = loader.WriteLock(th.GetFSys(), app, lock)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m := th.Run(app, opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`)
+
+	// The branch moves, so the build fails.
+	work := filepath.Join(dir, "work")
+	th.WriteF(filepath.Join(work, "base", "service.yaml"), `
+apiVersion: v1
+kind: Service
+metadata:
+  name: changed
+`)
+	runGit(t, work, "commit", "--quiet", "-am", "change")
+	runGit(t, work, "push", "--quiet", filepath.Join(dir, "repo.git"),
+		"HEAD:refs/heads/main")
+	err = th.RunWithErr(app, opts)
+	if err == nil || !strings.Contains(err.Error(),
+		"but kustomization.lock locks it at commit "+lock.Remotes[0].Commit) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Locking again accepts the change.
+	lock, err = MakeKustomizer(th.GetFSys(), &opts).Lock(app)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = loader.WriteLock(th.GetFSys(), app, lock)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m = th.Run(app, opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Service
+metadata:
+  name: changed
+`)
+}
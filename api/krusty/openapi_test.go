@@ -0,0 +1,268 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+	"sigs.k8s.io/yaml"
+)
+
+func writeOpenAPIBase(th kusttest_test.Harness) {
+	th.WriteK("/app/base", `
+resources:
+- hive.yaml
+`)
+	th.WriteF("/app/base/hive.yaml", `
+apiVersion: jingfang.example.com/v1beta1
+kind: Hive
+metadata:
+  name: hive
+spec:
+  bees:
+  - name: queen
+    role: lay
+  - name: worker
+    role: forage
+  flowers:
+  - clover
+`)
+	th.WriteF("/app/overlay/patch.yaml", hivePatch)
+	th.WriteF("/app/other/patch.yaml", hivePatch)
+}
+
+const hivePatch = `
+apiVersion: jingfang.example.com/v1beta1
+kind: Hive
+metadata:
+  name: hive
+spec:
+  bees:
+  - name: worker
+    role: guard
+  flowers:
+  - lavender
+`
+
+const hiveSchema = `
+definitions:
+  v1beta1.Hive:
+    type: object
+    properties:
+      apiVersion:
+        type: string
+      kind:
+        type: string
+      metadata:
+        type: object
+      spec:
+        type: object
+        properties:
+          bees:
+            type: array
+            x-kubernetes-patch-merge-key: name
+            x-kubernetes-patch-strategy: merge
+            items:
+              $ref: "#/definitions/v1beta1.Bee"
+          flowers:
+            type: array
+            items:
+              type: string
+    x-kubernetes-group-version-kind:
+    - group: jingfang.example.com
+      kind: Hive
+      version: v1beta1
+  v1beta1.Bee:
+    type: object
+    properties:
+      name:
+        type: string
+      role:
+        type: string
+`
+
+func TestOpenAPIFieldMergesCustomLists(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeOpenAPIBase(th)
+	th.WriteF("/app/overlay/hive_schema.yaml", hiveSchema)
+	th.WriteK("/app/overlay", `
+resources:
+- ../base
+openapi:
+- hive_schema.yaml
+patchesStrategicMerge:
+- patch.yaml
+`)
+	m := th.Run("/app/overlay", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, mergedHive)
+}
+
+const mergedHive = `
+apiVersion: jingfang.example.com/v1beta1
+kind: Hive
+metadata:
+  name: hive
+spec:
+  bees:
+  - name: queen
+    role: lay
+  - name: worker
+    role: guard
+  flowers:
+  - lavender
+`
+
+// The schema can be held in an openAPI field, as
+// read by kyaml's openapi.AddSchemaFromFile.
+func TestOpenAPIFieldInOpenAPIField(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeOpenAPIBase(th)
+	th.WriteF("/app/overlay/Kptfile", `
+apiVersion: kpt.dev/v1alpha1
+kind: Kptfile
+openAPI:`+indent(hiveSchema))
+	th.WriteK("/app/overlay", `
+resources:
+- ../base
+openapi:
+- Kptfile
+patchesStrategicMerge:
+- patch.yaml
+`)
+	m := th.Run("/app/overlay", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, mergedHive)
+}
+
+// Without a schema, a custom resource's lists are replaced,
+// and the schema of one build doesn't apply to the next.
+func TestOpenAPIFieldMissing(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeOpenAPIBase(th)
+	th.WriteF("/app/overlay/hive_schema.yaml", hiveSchema)
+	th.WriteK("/app/other", `
+resources:
+- ../base
+patchesStrategicMerge:
+- patch.yaml
+`)
+	th.WriteK("/app/overlay", `
+resources:
+- ../base
+openapi:
+- hive_schema.yaml
+`)
+	th.Run("/app/overlay", th.MakeDefaultOptions())
+	m := th.Run("/app/other", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: jingfang.example.com/v1beta1
+kind: Hive
+metadata:
+  name: hive
+spec:
+  bees:
+  - name: worker
+    role: guard
+  flowers:
+  - lavender
+`)
+}
+
+// The schemas apply to the whole build, even when
+// named by bases accumulated concurrently.
+func TestOpenAPIFieldInSiblingBases(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeOpenAPIBase(th)
+	var resources, expected string
+	for i := 0; i < 8; i++ {
+		dir := fmt.Sprintf("/app/sibling%d", i)
+		th.WriteF(dir+"/hive_schema.yaml", hiveSchema)
+		th.WriteF(dir+"/patch.yaml", hivePatch)
+		th.WriteK(dir, fmt.Sprintf(`
+namePrefix: s%d-
+resources:
+- ../base
+openapi:
+- hive_schema.yaml
+patchesStrategicMerge:
+- patch.yaml
+`, i))
+		resources += fmt.Sprintf("- sibling%d\n", i)
+		if i > 0 {
+			expected += "---"
+		}
+		expected += strings.Replace(
+			mergedHive, "name: hive", fmt.Sprintf("name: s%d-hive", i), 1)
+	}
+	th.WriteK("/app", "resources:\n"+resources)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, expected)
+}
+
+// A schema named by an overlay applies
+// to the patches of its bases.
+func TestOpenAPIFieldAppliesToBases(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeOpenAPIBase(th)
+	th.WriteF("/app/overlay/hive_schema.yaml", hiveSchema)
+	th.WriteK("/app/other", `
+resources:
+- ../base
+patchesStrategicMerge:
+- patch.yaml
+`)
+	th.WriteK("/app/overlay", `
+resources:
+- ../other
+openapi:
+- hive_schema.yaml
+`)
+	m := th.Run("/app/overlay", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, mergedHive)
+}
+
+// The schemas a library caller adds
+// outlive builds naming others.
+func TestOpenAPIFieldKeepsAddedSchemas(t *testing.T) {
+	defer openapi.ResetOpenAPI()
+	j, err := yaml.YAMLToJSON([]byte(hiveSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := openapi.AddSchema(j); err != nil {
+		t.Fatal(err)
+	}
+	th := kusttest_test.MakeHarness(t)
+	writeOpenAPIBase(th)
+	th.WriteF("/app/overlay/hive_schema.yaml", hiveSchema)
+	th.WriteK("/app/overlay", `
+resources:
+- ../base
+openapi:
+- hive_schema.yaml
+`)
+	th.WriteK("/app/other", `
+resources:
+- ../base
+patchesStrategicMerge:
+- patch.yaml
+`)
+	th.Run("/app/overlay", th.MakeDefaultOptions())
+	m := th.Run("/app/other", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, mergedHive)
+}
+
+func indent(s string) string {
+	result := ""
+	for _, line := range strings.Split(s, "\n") {
+		if line != "" {
+			line = "  " + line
+		}
+		result += line + "\n"
+	}
+	return result
+}
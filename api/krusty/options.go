@@ -16,15 +16,50 @@ type Options struct {
 	// per a particular sort order.  When false, don't do the
 	// sort, and instead respect the depth-first resource input
 	// order as specified by the kustomization file(s).
+	// The sortOptions of the kustomization, if any,
+	// apply instead.
 	DoLegacyResourceSort bool
 
+	// How to order the resources before emitting them.
+	// When set, these options override both the
+	// kustomization's sortOptions and DoLegacyResourceSort.
+	SortOptions *types.SortOptions
+
 	// Restrictions on what can be loaded from the file system.
 	// See type definition.
 	LoadRestrictions types.LoadRestrictions
 
+	// Directories, besides the kustomization root, from
+	// which files may be loaded when LoadRestrictions is
+	// LoadRestrictionsAllowList.
+	AllowedLoadRoots []string
+
+	// Where to cache clones of remote git repos, by repo
+	// URL and commit.  When empty, remote repos are cloned
+	// afresh, into a temporary directory, for each build.
+	RemoteCacheDir string
+
+	// When true, remote repos are only taken from the
+	// cache in RemoteCacheDir, and never fetched.
+	Offline bool
+
 	// Create an inventory object for pruning.
 	DoPrune bool
 
+	// When true, annotate each resource with where it came
+	// from, and with the transformers that changed it.
+	AddProvenanceAnnotations bool
+
+	// Whether to check the output against the OpenAPI schemas
+	// of its resources, and whether problems found are
+	// warnings or errors.  See type definition.
+	SchemaValidation types.SchemaValidation
+
+	// When true, patches, replicas, images and vars that
+	// match no resources or fields fail the build, rather
+	// than being ignored.
+	Strict bool
+
 	// Options related to kustomize plugins.
 	PluginConfig *types.PluginConfig
 }
@@ -32,9 +67,12 @@ type Options struct {
 // MakeDefaultOptions returns a default instance of Options.
 func MakeDefaultOptions() *Options {
 	return &Options{
-		DoLegacyResourceSort: true,
-		LoadRestrictions:     types.LoadRestrictionsRootOnly,
-		DoPrune:              false,
-		PluginConfig:         konfig.DisabledPluginConfig(),
+		DoLegacyResourceSort:     true,
+		LoadRestrictions:         types.LoadRestrictionsRootOnly,
+		DoPrune:                  false,
+		AddProvenanceAnnotations: false,
+		SchemaValidation:         types.SchemaValidationOff,
+		Strict:                   false,
+		PluginConfig:             konfig.DisabledPluginConfig(),
 	}
 }
@@ -0,0 +1,135 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeProvenanceBaseAndOverlay(th kusttest_test.Harness) {
+	th.WriteK("/app/base", `
+namePrefix: b-
+resources:
+- deploy.yaml
+- service.yaml
+`)
+	th.WriteF("/app/base/deploy.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: storefront
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: nginx
+`)
+	th.WriteF("/app/base/service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: storefront
+`)
+	th.WriteK("/app/overlay", `
+resources:
+- ../base
+images:
+- name: nginx
+  newTag: "1.17"
+configMapGenerator:
+- name: settings
+  literals:
+  - color=blue
+`)
+}
+
+func TestProvenanceAnnotationsOffByDefault(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeProvenanceBaseAndOverlay(th)
+	m := th.Run("/app/overlay", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: b-storefront
+spec:
+  template:
+    spec:
+      containers:
+      - image: nginx:1.17
+        name: app
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: b-storefront
+---
+apiVersion: v1
+data:
+  color: blue
+kind: ConfigMap
+metadata:
+  name: settings-788gth9fg6
+`)
+}
+
+func TestProvenanceAnnotations(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeProvenanceBaseAndOverlay(th)
+	opts := th.MakeDefaultOptions()
+	opts.AddProvenanceAnnotations = true
+	m := th.Run("/app/overlay", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  annotations:
+    kustomize.config.k8s.io/origin: |
+      line: 2
+      path: deploy.yaml
+      root: ../base
+    kustomize.config.k8s.io/transformations: |
+      - root: ../base
+        transformer: PrefixSuffixTransformer
+      - root: .
+        transformer: ImageTagTransformer
+  name: b-storefront
+spec:
+  template:
+    spec:
+      containers:
+      - image: nginx:1.17
+        name: app
+---
+apiVersion: v1
+kind: Service
+metadata:
+  annotations:
+    kustomize.config.k8s.io/origin: |
+      line: 2
+      path: service.yaml
+      root: ../base
+    kustomize.config.k8s.io/transformations: |
+      - root: ../base
+        transformer: PrefixSuffixTransformer
+  name: b-storefront
+---
+apiVersion: v1
+data:
+  color: blue
+kind: ConfigMap
+metadata:
+  annotations:
+    kustomize.config.k8s.io/origin: |
+      generator: ConfigMapGenerator
+      root: .
+    kustomize.config.k8s.io/transformations: |
+      - root: .
+        transformer: HashTransformer
+  name: settings-788gth9fg6
+`)
+}
@@ -157,6 +157,7 @@ metadata:
   annotations:
     kustomize.config.k8s.io/Inventory: '{"current":{"apps_v1beta2_Deployment|default|my-mysql":null,"~G_v1_Secret|default|my-pass":[{"group":"apps","version":"v1beta2","kind":"Deployment","name":"my-mysql","namespace":"default"}],"~G_v1_Service|default|my-mmmysql":null}}'
     kustomize.config.k8s.io/InventoryHash: kd67f7ht8t
+    kustomize.config.k8s.io/InventoryObjectHashes: '{"apps_v1beta2_Deployment|default|my-mysql":"k87dh9g724","~G_v1_Secret|default|my-pass":"cg6cb58t67","~G_v1_Service|default|my-mmmysql":"g5gfh2gbch"}'
   name: haha
   namespace: default
 `)
@@ -0,0 +1,123 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/gitcache"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func runGit(t *testing.T, dir string, args ...string) {
+	cmd := exec.Command("git", append([]string{
+		"-c", "user.name=test", "-c", "user.email=test@example.com"},
+		args...)...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+// makeRemoteBase makes a bare git repo in the given
+// directory, holding a base, and returns its URL.
+func makeRemoteBase(t *testing.T, th kusttest_test.Harness, dir string) string {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("no 'git' program on path")
+	}
+	work := filepath.Join(dir, "work")
+	bare := filepath.Join(dir, "repo.git")
+	if err := th.GetFSys().MkdirAll(filepath.Join(work, "base")); err != nil {
+		t.Fatal(err)
+	}
+	th.WriteK(filepath.Join(work, "base"), `
+resources:
+- service.yaml
+`)
+	th.WriteF(filepath.Join(work, "base", "service.yaml"), `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`)
+	runGit(t, dir, "init", "--quiet", "--bare", bare)
+	runGit(t, work, "init", "--quiet")
+	runGit(t, work, "add", ".")
+	runGit(t, work, "commit", "--quiet", "-m", "base")
+	runGit(t, work, "push", "--quiet", bare, "HEAD:refs/heads/main")
+	return "file://" + bare
+}
+
+func TestRemoteBaseCache(t *testing.T) {
+	th := kusttest_test.MakeHarnessWithFs(t, filesys.MakeFsOnDisk())
+	dir := makeTmpDir(t)
+	defer os.RemoveAll(dir)
+	url := makeRemoteBase(t, th, dir)
+	app := filepath.Join(dir, "app")
+	if err := th.GetFSys().MkdirAll(app); err != nil {
+		t.Fatal(err)
+	}
+	th.WriteK(app, `
+namePrefix: app-
+resources:
+- `+url+`//base?ref=main
+`)
+	expected := `
+apiVersion: v1
+kind: Service
+metadata:
+  name: app-web
+`
+	cacheDir := filepath.Join(dir, "cache")
+	opts := th.MakeDefaultOptions()
+
+	// Offline, with nothing cached, the base can't be had.
+	opts.RemoteCacheDir = cacheDir
+	opts.Offline = true
+	err := th.RunWithErr(app, opts)
+	if err == nil || !strings.Contains(err.Error(), "offline") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Online, the base is cached.
+	opts.Offline = false
+	m := th.Run(app, opts)
+	th.AssertActualEqualsExpected(m, expected)
+	entries, err := gitcache.New(cacheDir).List()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 1 || entries[0].URL != url {
+		t.Fatalf("unexpected cache entries: %v", entries)
+	}
+
+	// Offline, the cached base is used, even when the repo is gone.
+	err = os.RemoveAll(filepath.Join(dir, "repo.git"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts.Offline = true
+	m = th.Run(app, opts)
+	th.AssertActualEqualsExpected(m, expected)
+}
+
+func TestOfflineRequiresRemoteCache(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+resources:
+- service.yaml
+`)
+	opts := th.MakeDefaultOptions()
+	opts.Offline = true
+	err := th.RunWithErr("/app", opts)
+	if err == nil || !strings.Contains(
+		err.Error(), "offline builds require a remote cache directory") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
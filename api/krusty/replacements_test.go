@@ -0,0 +1,110 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeReplacementsBase(th kusttest_test.Harness) {
+	th.WriteK("/app/base", `
+resources:
+- service.yaml
+- deploy.yaml
+`)
+	th.WriteF("/app/base/service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: backend
+`)
+	th.WriteF("/app/base/deploy.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: frontend
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app
+        env:
+        - name: BACKEND_HOST
+          value: unset
+`)
+}
+
+// Replacements run after the kustomization's other
+// transformers, so they see the prefixed name.
+func TestReplacementsField(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeReplacementsBase(th)
+	th.WriteK("/app/overlay", `
+namePrefix: dev-
+resources:
+- ../base
+replacements:
+- source:
+    objref:
+      kind: Service
+      name: backend
+  target:
+    objref:
+      kind: Deployment
+    fieldrefs:
+    - spec.template.spec.containers[name=app].env[name=BACKEND_HOST].value
+`)
+	m := th.Run("/app/overlay", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: Service
+metadata:
+  name: dev-backend
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: dev-frontend
+spec:
+  template:
+    spec:
+      containers:
+      - env:
+        - name: BACKEND_HOST
+          value: dev-backend
+        image: app
+        name: app
+`)
+}
+
+func TestReplacementsMissingTargetField(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeReplacementsBase(th)
+	th.WriteK("/app/overlay", `
+resources:
+- ../base
+replacements:
+- source:
+    value: "8080"
+  target:
+    objref:
+      kind: Deployment
+    fieldrefs:
+    - spec.template.spec.containers[name=app].env[name=BACKEND_PORT].value
+`)
+	err := th.RunWithErr("/app/overlay", th.MakeDefaultOptions())
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if !strings.Contains(err.Error(),
+		"replacement target field "+
+			"'spec.template.spec.containers[name=app].env[name=BACKEND_PORT].value' "+
+			"not found in apps_v1_Deployment|~X|frontend") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
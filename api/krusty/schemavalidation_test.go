@@ -0,0 +1,121 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+func writeInvalidResources(th kusttest_test.Harness) {
+	th.WriteK("/app/base", `
+crds:
+- crd.json
+resources:
+- deploy.yaml
+- gadget.yaml
+`)
+	th.WriteF("/app/base/deploy.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: storefront
+spec:
+  replicas: three
+  selector:
+    matchLabels:
+      app: storefront
+  template:
+    spec:
+      containers:
+      - name: app
+        image: storefront
+        imagePullPolcy: Always
+`)
+	th.WriteF("/app/base/gadget.yaml", `
+apiVersion: example.com/v1
+kind: Gadget
+metadata:
+  name: gizmo
+spec:
+  size: big
+`)
+	th.WriteF("/app/base/crd.json", `
+{
+  "example.com/gadgets/v1.Gadget": {
+    "Schema": {
+      "required": ["spec"],
+      "properties": {
+        "apiVersion": {"type": "string"},
+        "kind": {"type": "string"},
+        "metadata": {"$ref": "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+        "spec": {"$ref": "example.com/gadgets/v1.GadgetSpec"}
+      }
+    }
+  },
+  "example.com/gadgets/v1.GadgetSpec": {
+    "Schema": {
+      "properties": {
+        "size": {"type": "integer"}
+      }
+    }
+  }
+}
+`)
+	th.WriteK("/app/overlay", `
+namePrefix: dev-
+resources:
+- ../base
+`)
+}
+
+func TestSchemaValidationError(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeInvalidResources(th)
+	opts := th.MakeDefaultOptions()
+	opts.SchemaValidation = types.SchemaValidationError
+	err := th.RunWithErr("/app/overlay", opts)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	expected := `resources fail schema validation:
+  apps_v1_Deployment|~X|dev-storefront: spec.replicas: expected integer, found string "three"
+  apps_v1_Deployment|~X|dev-storefront: spec.template.spec.containers[0].imagePullPolcy: unknown field
+  example.com_v1_Gadget|~X|dev-gizmo: spec.size: expected integer, found string "big"`
+	if err.Error() != expected {
+		t.Fatalf("expected error\n%s\nbut got\n%s", expected, err)
+	}
+}
+
+func TestSchemaValidationWarn(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeInvalidResources(th)
+	opts := th.MakeDefaultOptions()
+	opts.SchemaValidation = types.SchemaValidationWarn
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+	m := th.Run("/app/overlay", opts)
+	if m.Size() != 2 {
+		t.Fatalf("expected 2 resources, got %d", m.Size())
+	}
+	if n := strings.Count(buf.String(), "warning: schema validation: "); n != 3 {
+		t.Fatalf("expected 3 warnings, got %d in\n%s", n, buf.String())
+	}
+}
+
+func TestSchemaValidationOffByDefault(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeInvalidResources(th)
+	m := th.Run("/app/overlay", th.MakeDefaultOptions())
+	if m.Size() != 2 {
+		t.Fatalf("expected 2 resources, got %d", m.Size())
+	}
+}
@@ -0,0 +1,256 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+func writeSortOptionsResources(th kusttest_test.Harness) {
+	th.WriteF("/app/resources.yaml", `
+apiVersion: admissionregistration.k8s.io/v1beta1
+kind: MutatingWebhookConfiguration
+metadata:
+  name: injector
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  namespace: prod
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app
+      volumes:
+      - name: config
+        configMap:
+          name: settings
+---
+apiVersion: jingfang.example.com/v1beta1
+kind: Bee
+metadata:
+  name: bee
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: prod
+---
+apiVersion: apiextensions.k8s.io/v1beta1
+kind: CustomResourceDefinition
+metadata:
+  name: bees.jingfang.example.com
+spec:
+  group: jingfang.example.com
+  names:
+    kind: Bee
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: prod
+`)
+}
+
+// kinds returns the kinds of the resources
+// of the given output, in order.
+func kinds(th kusttest_test.Harness, path string, o krusty.Options) string {
+	var result []string
+	for _, r := range th.Run(path, o).Resources() {
+		result = append(result, r.GetKind())
+	}
+	return strings.Join(result, " ")
+}
+
+func TestSortOptionsDefaultIsLegacy(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeSortOptionsResources(th)
+	th.WriteK("/app", `
+resources:
+- resources.yaml
+`)
+	o := th.MakeDefaultOptions()
+	o.DoLegacyResourceSort = true
+	actual := kinds(th, "/app", o)
+	expected := "Namespace CustomResourceDefinition " +
+		"MutatingWebhookConfiguration ConfigMap Deployment Bee"
+	if actual != expected {
+		t.Fatalf("expected %s, got %s", expected, actual)
+	}
+}
+
+func TestSortOptionsNone(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeSortOptionsResources(th)
+	th.WriteK("/app", `
+resources:
+- resources.yaml
+sortOptions:
+  order: none
+`)
+	actual := kinds(th, "/app", th.MakeDefaultOptions())
+	expected := "MutatingWebhookConfiguration Deployment Bee " +
+		"ConfigMap CustomResourceDefinition Namespace"
+	if actual != expected {
+		t.Fatalf("expected %s, got %s", expected, actual)
+	}
+}
+
+func TestSortOptionsKindOrder(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeSortOptionsResources(th)
+	th.WriteK("/app", `
+resources:
+- resources.yaml
+sortOptions:
+  order: legacy
+  kindOrder:
+    first:
+    - Bee
+    - Deployment
+    last:
+    - Namespace
+`)
+	actual := kinds(th, "/app", th.MakeDefaultOptions())
+	expected := "Bee Deployment CustomResourceDefinition " +
+		"MutatingWebhookConfiguration ConfigMap Namespace"
+	if actual != expected {
+		t.Fatalf("expected %s, got %s", expected, actual)
+	}
+}
+
+// With this kind order, the legacy order would put each resource
+// before those it depends on; the topological order fixes that.
+func TestSortOptionsTopological(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeSortOptionsResources(th)
+	th.WriteK("/app", `
+resources:
+- resources.yaml
+sortOptions:
+  order: topological
+  kindOrder:
+    first:
+    - MutatingWebhookConfiguration
+    - Bee
+    - Deployment
+    last:
+    - ConfigMap
+    - CustomResourceDefinition
+    - Namespace
+`)
+	actual := kinds(th, "/app", th.MakeDefaultOptions())
+	expected := "CustomResourceDefinition Bee Namespace " +
+		"ConfigMap Deployment MutatingWebhookConfiguration"
+	if actual != expected {
+		t.Fatalf("expected %s, got %s", expected, actual)
+	}
+}
+
+// The name references of the kustomization's
+// configurations count as dependencies too.
+func TestSortOptionsTopologicalCustomReference(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+resources:
+- resources.yaml
+configurations:
+- config.yaml
+sortOptions:
+  order: topological
+  kindOrder:
+    first:
+    - Bee
+`)
+	th.WriteF("/app/config.yaml", `
+nameReference:
+- kind: Secret
+  fieldSpecs:
+  - kind: Bee
+    path: spec/secrets
+`)
+	th.WriteF("/app/resources.yaml", `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: unused
+---
+apiVersion: jingfang.example.com/v1beta1
+kind: Bee
+metadata:
+  name: bee
+spec:
+  secrets:
+  - other
+  - token
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: token
+`)
+	m := th.Run("/app", th.MakeDefaultOptions())
+	var actual []string
+	for _, r := range m.Resources() {
+		actual = append(actual, r.GetName())
+	}
+	expected := "token bee unused"
+	if strings.Join(actual, " ") != expected {
+		t.Fatalf("expected %s, got %v", expected, actual)
+	}
+}
+
+func TestSortOptionsFromBuildOptions(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeSortOptionsResources(th)
+	th.WriteK("/app", `
+resources:
+- resources.yaml
+sortOptions:
+  order: none
+`)
+	o := th.MakeDefaultOptions()
+	o.SortOptions = &types.SortOptions{Order: types.TopologicalSortOrder}
+	actual := kinds(th, "/app", o)
+	expected := "Namespace CustomResourceDefinition ConfigMap " +
+		"Deployment Bee MutatingWebhookConfiguration"
+	if actual != expected {
+		t.Fatalf("expected %s, got %s", expected, actual)
+	}
+	o.SortOptions = nil
+	o.DoLegacyResourceSort = true
+	actual = kinds(th, "/app", o)
+	expected = "MutatingWebhookConfiguration Deployment Bee " +
+		"ConfigMap CustomResourceDefinition Namespace"
+	if actual != expected {
+		t.Fatalf("expected %s, got %s", expected, actual)
+	}
+}
+
+func TestSortOptionsUnknownOrder(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeSortOptionsResources(th)
+	th.WriteK("/app", `
+resources:
+- resources.yaml
+sortOptions:
+  order: alphabetical
+`)
+	err := th.RunWithErr("/app", th.MakeDefaultOptions())
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if !strings.Contains(err.Error(), "unknown sort order 'alphabetical'") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func assertLocatedError(th kusttest_test.Harness, expected string) {
+	t := th.GetT()
+	t.Helper()
+	err := th.RunWithErr("/app", th.MakeDefaultOptions())
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if actual := krusty.LocateError(err).Error(); actual != expected {
+		t.Fatalf("expected error\n  %s\nbut got\n  %s", expected, actual)
+	}
+}
+
+func TestErrorUnknownFieldInKustomization(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+resources:
+- deploy.yaml
+namePrefx: dev-
+`)
+	assertLocatedError(th,
+		`/app/kustomization.yaml:7:1: json: unknown field "namePrefx"`)
+}
+
+func TestErrorFieldOfWrongTypeInKustomization(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+commonLabels:
+  app: hello
+resources: deploy.yaml
+`)
+	assertLocatedError(th,
+		"/app/kustomization.yaml:7:1: json: cannot unmarshal string "+
+			"into Go struct field Kustomization.resources of type []string")
+}
+
+func TestErrorBadYamlInBaseKustomization(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+resources:
+- base
+`)
+	th.WriteF("/app/base/kustomization.yaml", `resources:
+- deploy.yaml
+namePrefix: a: b
+`)
+	assertLocatedError(th, "/app/base/kustomization.yaml:3: "+
+		"accumulating resources: couldn't make target for path '/app/base': "+
+		"mapping values are not allowed in this context")
+}
+
+func TestErrorBadYamlInResource(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+resources:
+- deploy.yaml
+`)
+	th.WriteF("/app/deploy.yaml", `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: storefront
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: storefront
+   labels: {}
+`)
+	assertLocatedError(th, "/app/deploy.yaml:10: "+
+		"accumulating resources: accumulating resources from 'deploy.yaml': "+
+		"mapping values are not allowed in this context")
+}
+
+func TestErrorPatchWithoutTarget(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+resources:
+- deploy.yaml
+patchesStrategicMerge:
+- patches.yaml
+`)
+	th.WriteF("/app/deploy.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: storefront
+`)
+	th.WriteF("/app/patches.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: storefront
+spec:
+  replicas: 2
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: checkout
+spec:
+  replicas: 2
+`)
+	assertLocatedError(th, "/app/patches.yaml:9: "+
+		"no matches for OriginalId apps_v1_Deployment|~X|checkout; "+
+		"no matches for CurrentId apps_v1_Deployment|~X|checkout; "+
+		"failed to find unique target for patch apps_v1_Deployment|checkout")
+}
+
+func TestErrorUnresolvableVar(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+resources:
+- deploy.yaml
+vars:
+- name: SERVICE
+  objref:
+    apiVersion: v1
+    kind: Service
+    name: storefront
+`)
+	th.WriteF("/app/deploy.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: storefront
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app
+        args: ["$(SERVICE)"]
+`)
+	assertLocatedError(th, "/app/kustomization.yaml:8:3: "+
+		"var '{SERVICE ~G_v1_Service {metadata.name}}' "+
+		"cannot be mapped to a field in the set of known resources")
+}
+
+func writeStorefront(th kusttest_test.Harness) {
+	th.WriteF("/app/deploy.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: storefront
+spec:
+  replicas: 1
+`)
+	th.WriteF("/app/ops.yaml", `
+- op: replace
+  path: /spec/replicas
+  value: 2
+- op: remove
+  path: /spec/paused
+`)
+}
+
+func TestErrorFailingJson6902Op(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+resources:
+- deploy.yaml
+patchesJson6902:
+- target:
+    group: apps
+    version: v1
+    kind: Deployment
+    name: storefront
+  path: ops.yaml
+`)
+	writeStorefront(th)
+	assertLocatedError(th, "/app/ops.yaml:5: failed to apply json patch "+
+		`'[{"op":"replace","path":"/spec/replicas","value":2},`+
+		`{"op":"remove","path":"/spec/paused"}]': `+
+		"error in remove for path: '/spec/paused': "+
+		"Unable to remove nonexistent key: paused: missing value")
+}
+
+func TestErrorFailingPatchesEntry(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/app", `
+resources:
+- deploy.yaml
+patches:
+- target:
+    kind: Deployment
+  path: ops.yaml
+`)
+	writeStorefront(th)
+	assertLocatedError(th, `/app/ops.yaml:5: failed to apply json patch '
+- op: replace
+  path: /spec/replicas
+  value: 2
+- op: remove
+  path: /spec/paused
+': error in remove for path: '/spec/paused': `+
+		"Unable to remove nonexistent key: paused: missing value")
+}
@@ -0,0 +1,222 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeStrictResources(th kusttest_test.Harness) {
+	th.WriteF("/app/resources.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app:v1
+        env:
+        - name: PORT
+          value: "8080"
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+`)
+}
+
+func TestStrictMode(t *testing.T) {
+	testCases := map[string]struct {
+		kustomization string
+		expectedErr   string
+	}{
+		"patchTarget": {
+			kustomization: `
+patches:
+- target:
+    kind: Deployment
+    name: ap
+  patch: |-
+    - op: replace
+      path: /spec/replicas
+      value: 2
+`,
+			expectedErr: "matched no resources",
+		},
+		"image": {
+			kustomization: `
+images:
+- name: ap
+  newTag: v2
+`,
+			expectedErr: "image ap matched no image fields",
+		},
+		"vars": {
+			kustomization: `
+vars:
+- name: SERVICE
+  objref:
+    kind: Service
+    name: app
+    apiVersion: v1
+`,
+			expectedErr: "vars that were never replaced: SERVICE",
+		},
+	}
+	for name, tc := range testCases {
+		th := kusttest_test.MakeHarness(t)
+		writeStrictResources(th)
+		th.WriteK("/app", "resources:\n- resources.yaml\n"+tc.kustomization)
+		// Ignored by default.
+		th.Run("/app", th.MakeDefaultOptions())
+		o := th.MakeDefaultOptions()
+		o.Strict = true
+		err := th.RunWithErr("/app", o)
+		if err == nil {
+			t.Fatalf("%s: expected an error", name)
+		}
+		if !strings.Contains(err.Error(), tc.expectedErr) {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+	}
+}
+
+// Entries that match something pass in strict mode,
+// including in bases.
+func TestStrictModeMatches(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeStrictResources(th)
+	th.WriteK("/app", `
+resources:
+- resources.yaml
+images:
+- name: app
+  newTag: v2
+replicas:
+- name: app
+  count: 3
+vars:
+- name: SERVICE
+  objref:
+    kind: Service
+    name: app
+    apiVersion: v1
+patches:
+- target:
+    kind: Deployment
+  patch: |-
+    - op: add
+      path: /spec/template/spec/containers/0/args
+      value: ["$(SERVICE)"]
+`)
+	th.WriteK("/overlay", `
+resources:
+- ../app
+patchesStrategicMerge:
+- |-
+  apiVersion: v1
+  kind: Service
+  metadata:
+    name: app
+  spec:
+    type: NodePort
+`)
+	o := th.MakeDefaultOptions()
+	o.Strict = true
+	m := th.Run("/overlay", o)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - args:
+        - app
+        env:
+        - name: PORT
+          value: "8080"
+        image: app:v2
+        name: app
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+spec:
+  type: NodePort
+`)
+}
+
+// Patches that match their targets pass in strict
+// mode, even if they set fields to the values
+// those already have.
+func TestStrictModeUnchangingPatches(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeStrictResources(th)
+	th.WriteK("/app", `
+resources:
+- resources.yaml
+patches:
+- target:
+    kind: Deployment
+  patch: |-
+    - op: replace
+      path: /spec/replicas
+      value: 1
+patchesStrategicMerge:
+- |-
+  apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    name: app
+  spec:
+    replicas: 1
+patchesJson6902:
+- target:
+    group: apps
+    version: v1
+    kind: Deployment
+    name: app
+  patch: |-
+    - op: test
+      path: /spec/replicas
+      value: 1
+`)
+	o := th.MakeDefaultOptions()
+	o.Strict = true
+	m := th.Run("/app", o)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - env:
+        - name: PORT
+          value: "8080"
+        image: app:v1
+        name: app
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+`)
+}
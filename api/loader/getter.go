@@ -5,6 +5,7 @@ package loader
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"os"
 
@@ -52,6 +53,21 @@ func newLoaderAtGetter(raw string, fSys filesys.FileSystem, referrer *fileLoader
 	}, nil
 }
 
+// cachingGetter returns a getter that leaves git repos to
+// the cloner, so that they're cached, and that, when
+// offline, gets nothing at all.
+func cachingGetter(offline bool) remoteTargetGetter {
+	return func(rs *remoteTargetSpec) error {
+		if _, err := git.NewRepoSpecFromUrl(rs.Raw); err == nil {
+			return fmt.Errorf("'%s' is a git repo, for the cloner", rs.Raw)
+		}
+		if offline {
+			return fmt.Errorf("offline, so not getting '%s'", rs.Raw)
+		}
+		return getRemoteTarget(rs)
+	}
+}
+
 func getRemoteTarget(rs *remoteTargetSpec) error {
 	var err error
 
@@ -79,10 +95,28 @@ func getRemoteTarget(rs *remoteTargetSpec) error {
 			new(getter.BitBucketDetector),
 		},
 		Options: opts,
+		Getters: newGetters(),
 	}
 	return client.Get()
 }
 
+// newGetters returns getters private to one client.
+// The package level getter.Getters are mutated by
+// every client that uses them, so they can't be
+// shared by loaders running concurrently.
+func newGetters() map[string]getter.Getter {
+	httpGetter := &getter.HttpGetter{
+		Netrc: true,
+	}
+	return map[string]getter.Getter{
+		"file":  new(getter.FileGetter),
+		"git":   new(getter.GitGetter),
+		"hg":    new(getter.HgGetter),
+		"http":  httpGetter,
+		"https": httpGetter,
+	}
+}
+
 func getNothing(rs *remoteTargetSpec) error {
 	var err error
 	rs.Dir, err = filesys.NewTmpConfirmedDir()
@@ -8,6 +8,7 @@ import (
 	"fmt"
 
 	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/gitcache"
 	"sigs.k8s.io/kustomize/api/ifc"
 	"sigs.k8s.io/kustomize/api/internal/git"
 )
@@ -21,8 +22,59 @@ import (
 func NewLoader(
 	lr LoadRestrictorFunc,
 	target string, fSys filesys.FileSystem) (ifc.Loader, error) {
+	return newLoader(
+		lr, target, fSys, git.ClonerUsingGitExec, getRemoteTarget)
+}
+
+// NewCachingLoader is like NewLoader, but takes remote git
+// repos from the given cache, adding to it those it lacks.
+// When offline, remote targets are only taken from the cache.
+func NewCachingLoader(
+	lr LoadRestrictorFunc, target string, fSys filesys.FileSystem,
+	cache *gitcache.Cache, offline bool) (ifc.Loader, error) {
+	return NewLoaderWithRemoteOptions(
+		lr, target, fSys, RemoteOptions{Cache: cache, Offline: offline})
+}
+
+// RemoteOptions say how a loader gets remote targets.
+type RemoteOptions struct {
+	// If not nil, remote git repos are taken from this
+	// cache, and those it lacks are added to it.
+	Cache *gitcache.Cache
+
+	// If true, remote targets are only taken from Cache.
+	Offline bool
+
+	// If not nil, remote targets are checked
+	// against, or recorded in, a lock.
+	Locker *Locker
+}
+
+// NewLoaderWithRemoteOptions is like NewLoader, but
+// gets remote targets as the given options say.
+func NewLoaderWithRemoteOptions(
+	lr LoadRestrictorFunc, target string, fSys filesys.FileSystem,
+	o RemoteOptions) (ifc.Loader, error) {
+	var cloner git.Cloner = git.ClonerUsingGitExec
+	var getter remoteTargetGetter = getRemoteTarget
+	if o.Cache != nil {
+		cloner = git.CachingClonerUsingGitExec(o.Cache, o.Offline)
+		getter = cachingGetter(o.Offline)
+	} else if o.Offline {
+		return nil, fmt.Errorf("offline loading requires a remote cache")
+	}
+	if o.Locker != nil {
+		cloner = o.Locker.cloner(fSys, cloner)
+		getter = o.Locker.getter(fSys, getter)
+	}
+	return newLoader(lr, target, fSys, cloner, getter)
+}
+
+func newLoader(
+	lr LoadRestrictorFunc, target string, fSys filesys.FileSystem,
+	cloner git.Cloner, getter remoteTargetGetter) (ifc.Loader, error) {
 
-	ldr, errGet := newLoaderAtGetter(target, fSys, nil, git.ClonerUsingGitExec, getRemoteTarget)
+	ldr, errGet := newLoaderAtGetter(target, fSys, nil, cloner, getter)
 	if errGet == nil {
 		return ldr, nil
 	}
@@ -31,12 +83,12 @@ func NewLoader(
 	if errGit == nil {
 		// The target qualifies as a remote git target.
 		return newLoaderAtGitClone(
-			repoSpec, fSys, nil, git.ClonerUsingGitExec, getRemoteTarget)
+			repoSpec, fSys, nil, cloner, getter)
 	}
 
 	root, errDir := demandDirectoryRoot(fSys, target)
 	if errDir == nil {
-		return newLoaderAtConfirmedDir(lr, root, fSys, nil, git.ClonerUsingGitExec, getRemoteTarget), nil
+		return newLoaderAtConfirmedDir(lr, root, fSys, nil, cloner, getter), nil
 	}
 
 	return nil, fmt.Errorf("Error creating new loader with git: %v, dir: %v, get: %v", errGit, errDir, errGet)
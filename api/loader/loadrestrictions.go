@@ -29,6 +29,34 @@ func RestrictionRootOnly(
 	return d.Join(f), nil
 }
 
+// RestrictionAllowList returns a LoadRestrictorFunc that
+// allows files in or below the root, or any of the given
+// allowed roots.
+func RestrictionAllowList(allowed []filesys.ConfirmedDir) LoadRestrictorFunc {
+	return func(
+		fSys filesys.FileSystem, root filesys.ConfirmedDir, path string) (string, error) {
+		d, f, err := fSys.CleanedAbs(path)
+		if err != nil {
+			return "", err
+		}
+		if f == "" {
+			return "", fmt.Errorf("'%s' must resolve to a file", path)
+		}
+		if d.HasPrefix(root) {
+			return d.Join(f), nil
+		}
+		for _, a := range allowed {
+			if d.HasPrefix(a) {
+				return d.Join(f), nil
+			}
+		}
+		return "", fmt.Errorf(
+			"security; file '%s' is not in or below '%s', "+
+				"nor any of the allowed roots %v",
+			path, root, allowed)
+	}
+}
+
 func RestrictionNone(
 	_ filesys.FileSystem, _ filesys.ConfirmedDir, path string) (string, error) {
 	return path, nil
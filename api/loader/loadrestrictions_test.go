@@ -65,3 +65,48 @@ func TestRestrictionRootOnly(t *testing.T) {
 		t.Fatalf("unexpected err: %s", err)
 	}
 }
+
+func TestRestrictionAllowList(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	root := filesys.ConfirmedDir(
+		filesys.Separator + filepath.Join("tmp", "foo"))
+	common := filesys.ConfirmedDir(
+		filesys.Separator + filepath.Join("tmp", "common"))
+	lr := RestrictionAllowList([]filesys.ConfirmedDir{common})
+
+	// Legal; in the root.
+	path := filepath.Join(string(root), "whatever", "beans")
+	fSys.Create(path)
+	p, err := lr(fSys, root, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p != path {
+		t.Fatalf("expected '%s', got '%s'", path, p)
+	}
+
+	// Legal; in an allowed root.
+	path = filepath.Join(string(common), "whatever", "beans")
+	fSys.Create(path)
+	p, err = lr(fSys, root, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p != path {
+		t.Fatalf("expected '%s', got '%s'", path, p)
+	}
+
+	// Illegal; file exists but is out of bounds.
+	path = filepath.Join(filesys.Separator+"tmp", "illegal")
+	fSys.Create(path)
+	_, err = lr(fSys, root, path)
+	if err == nil {
+		t.Fatal("should have an error")
+	}
+	if !strings.Contains(
+		err.Error(),
+		"file '/tmp/illegal' is not in or below '/tmp/foo', "+
+			"nor any of the allowed roots [/tmp/common]") {
+		t.Fatalf("unexpected err: %s", err)
+	}
+}
@@ -0,0 +1,210 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package loader
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/internal/git"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+)
+
+const digestPrefix = "sha256:"
+
+// Locker checks the remote roots that loaders get against
+// a lock, or, when recording, records them in the lock.
+// A Locker may be shared by loaders running concurrently.
+type Locker struct {
+	mu     sync.Mutex
+	lock   *types.KustomizationLock
+	record bool
+}
+
+// NewLockChecker returns a Locker failing on any remote
+// root that isn't in the given lock, or has changed since
+// it was recorded.
+func NewLockChecker(lock *types.KustomizationLock) *Locker {
+	return &Locker{lock: lock}
+}
+
+// NewLockRecorder returns a Locker recording
+// the remote roots in a new lock.
+func NewLockRecorder() *Locker {
+	return &Locker{
+		lock: &types.KustomizationLock{
+			TypeMeta: types.TypeMeta{
+				APIVersion: types.KustomizationLockVersion,
+				Kind:       types.KustomizationLockKind,
+			},
+		},
+		record: true,
+	}
+}
+
+// Lock returns the lock, with its remotes sorted by URL.
+func (l *Locker) Lock() *types.KustomizationLock {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	sort.Slice(l.lock.Remotes, func(i, j int) bool {
+		return l.lock.Remotes[i].URL < l.lock.Remotes[j].URL
+	})
+	return l.lock
+}
+
+// check checks, or records, the remote root at the given
+// URL, fetched into the given directory at the given
+// commit, which is empty if unknown.
+func (l *Locker) check(
+	fSys filesys.FileSystem, url, commit, dir string) error {
+	digest, err := digestDir(fSys, dir)
+	if err != nil {
+		return errors.Wrapf(err, "computing digest of remote root '%s'", url)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var locked *types.RemoteLock
+	for i := range l.lock.Remotes {
+		if l.lock.Remotes[i].URL == url {
+			locked = &l.lock.Remotes[i]
+			break
+		}
+	}
+	if locked == nil {
+		if l.record {
+			l.lock.Remotes = append(l.lock.Remotes, types.RemoteLock{
+				URL: url, Commit: commit, Digest: digest})
+			return nil
+		}
+		return fmt.Errorf(
+			"remote root '%s' isn't in %s; run 'kustomize edit lock' to add it",
+			url, konfig.KustomizationLockFileName)
+	}
+	if locked.Commit != "" && commit != "" && locked.Commit != commit {
+		return fmt.Errorf(
+			"remote root '%s' is at commit %s, but %s locks it at commit %s",
+			url, commit, konfig.KustomizationLockFileName, locked.Commit)
+	}
+	if locked.Digest != digest {
+		return fmt.Errorf(
+			"remote root '%s' has digest %s, but %s locks it at digest %s",
+			url, digest, konfig.KustomizationLockFileName, locked.Digest)
+	}
+	return nil
+}
+
+// cloner returns a Cloner checking, or recording,
+// the clones made by the given cloner.
+func (l *Locker) cloner(
+	fSys filesys.FileSystem, cloner git.Cloner) git.Cloner {
+	return func(repoSpec *git.RepoSpec) error {
+		err := cloner(repoSpec)
+		if err != nil {
+			return err
+		}
+		if !fSys.IsDir(repoSpec.AbsPath()) {
+			// The loader reports this.
+			return nil
+		}
+		return l.check(
+			fSys, repoSpec.Raw(), repoSpec.Commit, repoSpec.AbsPath())
+	}
+}
+
+// getter returns a remoteTargetGetter checking, or
+// recording, the targets got by the given getter.
+func (l *Locker) getter(
+	fSys filesys.FileSystem, getter remoteTargetGetter) remoteTargetGetter {
+	return func(rs *remoteTargetSpec) error {
+		err := getter(rs)
+		if err != nil {
+			return err
+		}
+		return l.check(fSys, rs.Raw, "", rs.Dir.String())
+	}
+}
+
+// digestDir returns a digest of the names and contents of
+// the files in and below the given directory, leaving out
+// git metadata.
+func digestDir(fSys filesys.FileSystem, dir string) (string, error) {
+	var lines []string
+	err := fSys.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.Name() == ".git" {
+			// A submodule's link to its git directory.
+			return nil
+		}
+		content, err := fSys.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, fmt.Sprintf(
+			"%x  %s\n", sha256.Sum256(content), filepath.ToSlash(rel)))
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(lines)
+	return fmt.Sprintf(
+		"%s%x", digestPrefix, sha256.Sum256([]byte(strings.Join(lines, "")))), nil
+}
+
+// ReadLock returns the lock in the given directory,
+// or nil if the directory has no lock file.
+func ReadLock(
+	fSys filesys.FileSystem, dir string) (*types.KustomizationLock, error) {
+	path := filepath.Join(dir, konfig.KustomizationLockFileName)
+	if !fSys.Exists(path) {
+		return nil, nil
+	}
+	content, err := fSys.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var lock types.KustomizationLock
+	err = yaml.Unmarshal(content, &lock)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading %s", path)
+	}
+	if lock.Kind != types.KustomizationLockKind {
+		return nil, fmt.Errorf(
+			"%s has kind '%s'; expected %s",
+			path, lock.Kind, types.KustomizationLockKind)
+	}
+	return &lock, nil
+}
+
+// WriteLock writes the lock to the given directory.
+func WriteLock(
+	fSys filesys.FileSystem, dir string, lock *types.KustomizationLock) error {
+	content, err := yaml.Marshal(lock)
+	if err != nil {
+		return err
+	}
+	return fSys.WriteFile(
+		filepath.Join(dir, konfig.KustomizationLockFileName), content)
+}
@@ -0,0 +1,191 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package loader
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/internal/git"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+const (
+	lockTopDir    = "/app"
+	lockCloneRoot = "/clone"
+	lockRemote    = "github.com/someOrg/someRepo/foo/base"
+)
+
+func makeLockFs() filesys.FileSystem {
+	fSys := filesys.MakeFsInMemory()
+	fSys.MkdirAll(lockTopDir)
+	fSys.WriteFile(lockCloneRoot+"/foo/base/kustomization.yaml", []byte(`
+resources:
+- service.yaml
+`))
+	fSys.WriteFile(lockCloneRoot+"/foo/base/service.yaml", []byte(`
+kind: Service
+`))
+	fSys.WriteFile(lockCloneRoot+"/foo/base/.git/HEAD", []byte(`
+ref: refs/heads/main
+`))
+	return fSys
+}
+
+// committingCloner is like git.DoNothingCloner,
+// but also sets the commit of the clone.
+func committingCloner(commit string) git.Cloner {
+	return func(rs *git.RepoSpec) error {
+		rs.Dir = lockCloneRoot
+		rs.Commit = commit
+		return nil
+	}
+}
+
+func newLockingLoader(
+	t *testing.T, fSys filesys.FileSystem,
+	locker *Locker, commit string) *fileLoader {
+	root, err := demandDirectoryRoot(fSys, lockTopDir)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	return newLoaderAtConfirmedDir(
+		RestrictionRootOnly, root, fSys, nil,
+		locker.cloner(fSys, committingCloner(commit)),
+		locker.getter(fSys, getNothing))
+}
+
+func TestLockRecorder(t *testing.T) {
+	fSys := makeLockFs()
+	locker := NewLockRecorder()
+	ldr := newLockingLoader(t, fSys, locker, "abc")
+	for i := 0; i < 2; i++ {
+		if _, err := ldr.New(lockRemote); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	}
+	lock := locker.Lock()
+	if lock.Kind != types.KustomizationLockKind || len(lock.Remotes) != 1 {
+		t.Fatalf("unexpected lock: %v", lock)
+	}
+	r := lock.Remotes[0]
+	if r.URL != lockRemote || r.Commit != "abc" ||
+		!strings.HasPrefix(r.Digest, digestPrefix) {
+		t.Fatalf("unexpected remote: %v", r)
+	}
+}
+
+func TestLockChecker(t *testing.T) {
+	fSys := makeLockFs()
+	recorder := NewLockRecorder()
+	_, err := newLockingLoader(t, fSys, recorder, "abc").New(lockRemote)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	lock := recorder.Lock()
+
+	// The same content, at the same commit, is fine.
+	checker := NewLockChecker(lock)
+	_, err = newLockingLoader(t, fSys, checker, "abc").New(lockRemote)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	// Git metadata isn't part of the content.
+	fSys.WriteFile(lockCloneRoot+"/foo/base/.git/HEAD", []byte(`
+ref: refs/heads/other
+`))
+	_, err = newLockingLoader(t, fSys, checker, "").New(lockRemote)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	_, err = newLockingLoader(t, fSys, checker, "def").New(lockRemote)
+	if err == nil || !strings.Contains(err.Error(),
+		"is at commit def, but kustomization.lock locks it at commit abc") {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	// A failed check removes the clone, so make another.
+	fSys = makeLockFs()
+	fSys.WriteFile(lockCloneRoot+"/foo/base/service.yaml", []byte(`
+kind: Deployment
+`))
+	_, err = newLockingLoader(t, fSys, checker, "abc").New(lockRemote)
+	if err == nil || !strings.Contains(err.Error(),
+		"but kustomization.lock locks it at digest "+lock.Remotes[0].Digest) {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	fSys = makeLockFs()
+	checker = NewLockChecker(&types.KustomizationLock{})
+	_, err = newLockingLoader(t, fSys, checker, "abc").New(lockRemote)
+	if err == nil || !strings.Contains(err.Error(),
+		"remote root '"+lockRemote+"' isn't in kustomization.lock") {
+		t.Fatalf("unexpected err: %v", err)
+	}
+}
+
+func TestDigestDir(t *testing.T) {
+	fSys := makeLockFs()
+	dir := lockCloneRoot + "/foo/base"
+	d1, err := digestDir(fSys, dir)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	content, _ := fSys.ReadFile(dir + "/service.yaml")
+	fSys.RemoveAll(dir + "/service.yaml")
+	fSys.WriteFile(dir+"/renamed.yaml", content)
+	d2, err := digestDir(fSys, dir)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if d1 == d2 {
+		t.Fatalf("expected renaming a file to change the digest")
+	}
+}
+
+func TestReadAndWriteLock(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	fSys.MkdirAll(lockTopDir)
+	lock, err := ReadLock(fSys, lockTopDir)
+	if err != nil || lock != nil {
+		t.Fatalf("expected no lock, got %v, %v", lock, err)
+	}
+	expected := NewLockRecorder().Lock()
+	expected.Remotes = []types.RemoteLock{
+		{URL: lockRemote, Commit: "abc", Digest: "sha256:123"},
+	}
+	if err = WriteLock(fSys, lockTopDir, expected); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	content, _ := fSys.ReadFile(lockTopDir + "/kustomization.lock")
+	if string(content) != `apiVersion: kustomize.config.k8s.io/v1alpha1
+kind: KustomizationLock
+remotes:
+- commit: abc
+  digest: sha256:123
+  url: github.com/someOrg/someRepo/foo/base
+` {
+		t.Fatalf("unexpected lock file:\n%s", content)
+	}
+	lock, err = ReadLock(fSys, lockTopDir)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if !reflect.DeepEqual(lock, expected) {
+		t.Fatalf("expected %v, got %v", expected, lock)
+	}
+
+	fSys.WriteFile(lockTopDir+"/kustomization.lock", []byte(`
+kind: Kustomization
+`))
+	_, err = ReadLock(fSys, lockTopDir)
+	if err == nil || !strings.Contains(
+		err.Error(), "has kind 'Kustomization'; expected KustomizationLock") {
+		t.Fatalf("unexpected err: %v", err)
+	}
+}
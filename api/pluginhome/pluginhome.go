@@ -0,0 +1,317 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package pluginhome finds and describes the plugins
+// in a plugin home.  A plugin of a given group, version
+// and kind is housed in the directory
+//
+//	${home}/${group}/${version}/LOWERCASE(${kind})
+//
+// as an executable named ${kind}, or as a Go plugin
+// named ${kind}.so, built from ${kind}.go.
+package pluginhome
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/resid"
+)
+
+// SchemaFileName is the name of the optional file, in
+// a plugin's directory, holding the OpenAPI schema of
+// the plugin's config.
+const SchemaFileName = "schema.yaml"
+
+// Type is the type of a plugin.
+type Type string
+
+const (
+	// TypeExec is an executable.
+	TypeExec Type = "exec"
+	// TypeGo is a built Go plugin.
+	TypeGo Type = "Go"
+	// TypeGoSource is a Go plugin that isn't built yet,
+	// and so can't be loaded.
+	TypeGoSource Type = "Go (not built)"
+)
+
+// Plugin is a plugin in a plugin home.
+type Plugin struct {
+	Gvk  resid.Gvk
+	Type Type
+
+	// Path is the path of the executable, the
+	// object code or the source code of the plugin.
+	Path string
+
+	// Schema is the path of the schema of the
+	// plugin's config, or empty if it has none.
+	Schema string
+}
+
+// APIVersion returns the apiVersion
+// of the plugin's config.
+func (p *Plugin) APIVersion() string {
+	return APIVersion(p.Gvk)
+}
+
+// APIVersion returns the apiVersion of the given Gvk.
+func APIVersion(gvk resid.Gvk) string {
+	if gvk.Group == "" {
+		return gvk.Version
+	}
+	return gvk.Group + "/" + gvk.Version
+}
+
+// ParseGvk parses a plugin named as its apiVersion
+// and kind, joined by a slash, e.g.
+//
+//	someteam.example.com/v1/SedTransformer
+func ParseGvk(s string) (resid.Gvk, error) {
+	parts := strings.Split(s, "/")
+	for _, p := range parts {
+		if p == "" {
+			parts = nil
+			break
+		}
+	}
+	switch len(parts) {
+	case 2:
+		return resid.Gvk{Version: parts[0], Kind: parts[1]}, nil
+	case 3:
+		return resid.Gvk{
+			Group: parts[0], Version: parts[1], Kind: parts[2]}, nil
+	}
+	return resid.Gvk{}, fmt.Errorf(
+		"plugin '%s' isn't of the form [{group}/]{version}/{kind}", s)
+}
+
+// Dir returns the directory housing the plugin
+// with the given Gvk.
+func Dir(home string, gvk resid.Gvk) string {
+	return filepath.Join(
+		home, gvk.Group, gvk.Version, strings.ToLower(gvk.Kind))
+}
+
+// Path returns the path of the executable of the plugin
+// with the given Gvk; a Go plugin has the suffix ".so".
+func Path(home string, gvk resid.Gvk) string {
+	return filepath.Join(Dir(home, gvk), gvk.Kind)
+}
+
+// Get returns the plugin with the given Gvk, preferring
+// an executable to a Go plugin, as the loader does.
+func Get(
+	fSys filesys.FileSystem, home string, gvk resid.Gvk) (*Plugin, error) {
+	path := Path(home, gvk)
+	p := &Plugin{Gvk: gvk}
+	switch {
+	case isFile(fSys, path):
+		p.Type, p.Path = TypeExec, path
+	case isFile(fSys, path+".so"):
+		p.Type, p.Path = TypeGo, path+".so"
+	case isFile(fSys, path+".go"):
+		p.Type, p.Path = TypeGoSource, path+".go"
+	default:
+		return nil, fmt.Errorf(
+			"no plugin %s/%s in %s", APIVersion(gvk), gvk.Kind, home)
+	}
+	if schema := filepath.Join(Dir(home, gvk), SchemaFileName); isFile(fSys, schema) {
+		p.Schema = schema
+	}
+	return p, nil
+}
+
+func isFile(fSys filesys.FileSystem, path string) bool {
+	return fSys.Exists(path) && !fSys.IsDir(path)
+}
+
+// List returns the plugins in the given home,
+// sorted by apiVersion and kind.
+func List(fSys filesys.FileSystem, home string) ([]*Plugin, error) {
+	var result []*Plugin
+	seen := make(map[resid.Gvk]bool)
+	err := fSys.Walk(home, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if strings.HasPrefix(info.Name(), ".") && path != home {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		gvk, ok := gvkOf(home, path)
+		if !ok || seen[gvk] {
+			return nil
+		}
+		seen[gvk] = true
+		p, err := Get(fSys, home, gvk)
+		if err != nil {
+			return err
+		}
+		result = append(result, p)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.APIVersion() != b.APIVersion() {
+			return a.APIVersion() < b.APIVersion()
+		}
+		return a.Gvk.Kind < b.Gvk.Kind
+	})
+	return result, nil
+}
+
+// gvkOf returns the Gvk of the plugin for which the file
+// at the given path would be the executable, object code
+// or source code.
+func gvkOf(home, path string) (resid.Gvk, bool) {
+	rel, err := filepath.Rel(home, path)
+	if err != nil {
+		return resid.Gvk{}, false
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	kind := parts[len(parts)-1]
+	if strings.HasSuffix(kind, "_test.go") {
+		return resid.Gvk{}, false
+	}
+	kind = strings.TrimSuffix(strings.TrimSuffix(kind, ".so"), ".go")
+	if kind == "" || strings.Contains(kind, ".") {
+		return resid.Gvk{}, false
+	}
+	var gvk resid.Gvk
+	switch len(parts) {
+	case 3:
+		gvk = resid.Gvk{Version: parts[0], Kind: kind}
+	case 4:
+		gvk = resid.Gvk{Group: parts[0], Version: parts[1], Kind: kind}
+	default:
+		return resid.Gvk{}, false
+	}
+	if parts[len(parts)-2] != strings.ToLower(kind) {
+		return resid.Gvk{}, false
+	}
+	return gvk, true
+}
+
+// Description is what can be told about a plugin
+// without loading it.
+type Description struct {
+	// Doc is the comment heading an exec plugin, or
+	// the doc comment of the plugin type of a Go
+	// plugin's source.
+	Doc string
+
+	// Methods are the plugin methods found in a
+	// Go plugin's source, e.g. Generate, Transform.
+	Methods []string
+
+	// Schema is the content of the plugin's schema.
+	Schema string
+}
+
+// Describe returns the description of the given plugin.
+func Describe(fSys filesys.FileSystem, p *Plugin) (*Description, error) {
+	d := &Description{}
+	if p.Schema != "" {
+		content, err := fSys.ReadFile(p.Schema)
+		if err != nil {
+			return nil, err
+		}
+		d.Schema = string(content)
+	}
+	switch p.Type {
+	case TypeExec:
+		content, err := fSys.ReadFile(p.Path)
+		if err != nil {
+			return nil, err
+		}
+		d.Doc = scriptDoc(string(content))
+	case TypeGo:
+		src := strings.TrimSuffix(p.Path, ".so") + ".go"
+		if !isFile(fSys, src) {
+			break
+		}
+		if err := describeGoSource(fSys, src, d); err != nil {
+			return nil, err
+		}
+	case TypeGoSource:
+		if err := describeGoSource(fSys, p.Path, d); err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// scriptDoc returns the comment following the
+// "#!" line of a script, if any.
+func scriptDoc(content string) string {
+	if !strings.HasPrefix(content, "#!") {
+		return ""
+	}
+	var doc []string
+	for _, line := range strings.Split(content, "\n")[1:] {
+		if !strings.HasPrefix(line, "#") {
+			break
+		}
+		doc = append(doc, strings.TrimSpace(strings.TrimPrefix(line, "#")))
+	}
+	return strings.TrimSpace(strings.Join(doc, "\n"))
+}
+
+// pluginMethods are the methods of the
+// interfaces a plugin may implement.
+var pluginMethods = map[string]bool{
+	"Config":    true,
+	"Generate":  true,
+	"Transform": true,
+}
+
+// describeGoSource fills in the description from the
+// source of a Go plugin, whose type is named "plugin".
+func describeGoSource(
+	fSys filesys.FileSystem, path string, d *Description) error {
+	content, err := fSys.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	f, err := parser.ParseFile(
+		token.NewFileSet(), path, content, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				ts, ok := spec.(*ast.TypeSpec)
+				if !ok || ts.Name.Name != "plugin" {
+					continue
+				}
+				doc := ts.Doc
+				if doc == nil {
+					doc = decl.Doc
+				}
+				d.Doc = strings.TrimSpace(doc.Text())
+			}
+		case *ast.FuncDecl:
+			if decl.Recv != nil && pluginMethods[decl.Name.Name] {
+				d.Methods = append(d.Methods, decl.Name.Name)
+			}
+		}
+	}
+	sort.Strings(d.Methods)
+	return nil
+}
@@ -0,0 +1,152 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginhome_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+	. "sigs.k8s.io/kustomize/api/pluginhome"
+	"sigs.k8s.io/kustomize/api/resid"
+)
+
+const home = "/plugin"
+
+func makeHome() filesys.FileSystem {
+	fSys := filesys.MakeFsInMemory()
+	for path, content := range map[string]string{
+		"someteam.example.com/v1/sedtransformer/SedTransformer": `#!/bin/bash
+# Runs sed on the resources,
+# with the given args.
+
+sed "$@"
+`,
+		"someteam.example.com/v1/sedtransformer/SedTransformer_test.go": "",
+		"someteam.example.com/v1/sedtransformer/go.mod":                 "",
+		"someteam.example.com/v1/sedtransformer/schema.yaml": `type: object
+`,
+		"someteam.example.com/v1/stringprefixer/StringPrefixer.go": `package main
+
+// Adds a prefix to names.
+type plugin struct{}
+
+var KustomizePlugin plugin
+
+func (p *plugin) Transform(m resmap.ResMap) error { return nil }
+
+func (p *plugin) Config(h *resmap.PluginHelpers, c []byte) error { return nil }
+
+func (p *plugin) helper() {}
+`,
+		"someteam.example.com/v1/stringprefixer/StringPrefixer.so": "",
+		"v2/datedgenerator/DatedGenerator.go": `package main
+
+type plugin struct{}
+
+func (p *plugin) Generate() (resmap.ResMap, error) { return nil, nil }
+`,
+		"someteam.example.com/v1/misplaced/Other":         "",
+		"someteam.example.com/v1/.git/kind/Kind":          "",
+		"someteam.example.com/v1/notes.txt":               "",
+		"someteam.example.com/v1/deeper/than/usual/Usual": "",
+	} {
+		fSys.WriteFile(home+"/"+path, []byte(content))
+	}
+	return fSys
+}
+
+func TestList(t *testing.T) {
+	plugins, err := List(makeHome(), home)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []*Plugin{
+		{
+			Gvk:  resid.Gvk{Group: "someteam.example.com", Version: "v1", Kind: "SedTransformer"},
+			Type: TypeExec,
+			Path: "/plugin/someteam.example.com/v1/sedtransformer/SedTransformer",
+			Schema: "/plugin/someteam.example.com/v1/sedtransformer/" +
+				SchemaFileName,
+		},
+		{
+			Gvk:  resid.Gvk{Group: "someteam.example.com", Version: "v1", Kind: "StringPrefixer"},
+			Type: TypeGo,
+			Path: "/plugin/someteam.example.com/v1/stringprefixer/StringPrefixer.so",
+		},
+		{
+			Gvk:  resid.Gvk{Version: "v2", Kind: "DatedGenerator"},
+			Type: TypeGoSource,
+			Path: "/plugin/v2/datedgenerator/DatedGenerator.go",
+		},
+	}
+	if !reflect.DeepEqual(plugins, expected) {
+		for _, p := range plugins {
+			t.Logf("got %v", *p)
+		}
+		t.Fatalf("unexpected plugins")
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	fSys := makeHome()
+	testCases := map[string]Description{
+		"someteam.example.com/v1/SedTransformer": {
+			Doc:    "Runs sed on the resources,\nwith the given args.",
+			Schema: "type: object\n",
+		},
+		"someteam.example.com/v1/StringPrefixer": {
+			Doc:     "Adds a prefix to names.",
+			Methods: []string{"Config", "Transform"},
+		},
+		"v2/DatedGenerator": {
+			Methods: []string{"Generate"},
+		},
+	}
+	for name, expected := range testCases {
+		gvk, err := ParseGvk(name)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		p, err := Get(fSys, home, gvk)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		d, err := Describe(fSys, p)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !reflect.DeepEqual(*d, expected) {
+			t.Errorf("%s: expected %v, got %v", name, expected, *d)
+		}
+	}
+}
+
+func TestGetMissing(t *testing.T) {
+	_, err := Get(makeHome(), home,
+		resid.Gvk{Group: "someteam.example.com", Version: "v1", Kind: "Nope"})
+	if err == nil || !strings.Contains(err.Error(),
+		"no plugin someteam.example.com/v1/Nope in /plugin") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestParseGvk(t *testing.T) {
+	testCases := map[string]resid.Gvk{
+		"v1/Foo":                  {Version: "v1", Kind: "Foo"},
+		"example.com/v1beta1/Foo": {Group: "example.com", Version: "v1beta1", Kind: "Foo"},
+	}
+	for s, expected := range testCases {
+		gvk, err := ParseGvk(s)
+		if err != nil || gvk != expected {
+			t.Errorf("%s: expected %v, got %v, %v", s, expected, gvk, err)
+		}
+	}
+	for _, s := range []string{"Foo", "a/b/c/Foo", "example.com//Foo"} {
+		if _, err := ParseGvk(s); err == nil {
+			t.Errorf("%s: expected an error", s)
+		}
+	}
+}
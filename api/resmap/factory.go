@@ -4,6 +4,8 @@
 package resmap
 
 import (
+	"path/filepath"
+
 	"github.com/pkg/errors"
 	"sigs.k8s.io/kustomize/api/ifc"
 	"sigs.k8s.io/kustomize/api/internal/kusterr"
@@ -47,11 +49,16 @@ func (rmF *Factory) FromFile(
 	if err != nil {
 		return nil, err
 	}
-	m, err := rmF.NewResMapFromBytes(content)
+	resources, err := rmF.resF.SliceFromBytes(content)
 	if err != nil {
+		e := kusterr.SyntaxError(filepath.Join(loader.Root(), path), content)
+		if e != nil {
+			return nil, e
+		}
 		return nil, kusterr.Handler(err, path)
 	}
-	return m, nil
+	resource.SetFileOrigins(resources, content, path, loader.Root())
+	return newResMapFromResourceSlice(resources)
 }
 
 // NewResMapFromBytes decodes a list of objects in byte array format.
@@ -42,6 +42,21 @@ func NewPluginHelpers(ldr ifc.Loader, v ifc.Validator, rf *Factory) *PluginHelpe
 	return &PluginHelpers{ldr: ldr, v: v, rf: rf}
 }
 
+// NewPluginHelpersWithConfig makes an instance of PluginHelpers
+// for the plugins of a build with the given plugin config,
+// running executables with the given runner.
+func NewPluginHelpersWithConfig(
+	ldr ifc.Loader, v ifc.Validator, rf *Factory,
+	pc *types.PluginConfig, run CommandRunner) *PluginHelpers {
+	return &PluginHelpers{ldr: ldr, v: v, rf: rf, pc: pc, run: run}
+}
+
+// CommandRunner runs the executable at the given path with
+// the given args, for the plugin whose config has the given
+// Gvk, returning what it writes to stdout.
+type CommandRunner func(
+	h *PluginHelpers, gvk resid.Gvk, path string, args []string) ([]byte, error)
+
 // PluginHelpers holds things that any or all plugins might need.
 // This should be available to each plugin, in addition to
 // any plugin-specific configuration.
@@ -49,6 +64,8 @@ type PluginHelpers struct {
 	ldr ifc.Loader
 	v   ifc.Validator
 	rf  *Factory
+	pc  *types.PluginConfig
+	run CommandRunner
 }
 
 func (c *PluginHelpers) Loader() ifc.Loader {
@@ -63,6 +80,28 @@ func (c *PluginHelpers) Validator() ifc.Validator {
 	return c.v
 }
 
+// PluginConfig returns the plugin config of the build,
+// or an empty one, allowing nothing, if it's unknown.
+func (c *PluginHelpers) PluginConfig() *types.PluginConfig {
+	if c.pc == nil {
+		return &types.PluginConfig{}
+	}
+	return c.pc
+}
+
+// RunCommand runs the executable at the given path with the
+// given args, as an exec plugin of the build would be run, for
+// the plugin whose config has the given Gvk, returning what it
+// writes to stdout.  Builtin plugins run executables with it.
+func (c *PluginHelpers) RunCommand(
+	gvk resid.Gvk, path string, args []string) ([]byte, error) {
+	if c.run == nil {
+		return nil, fmt.Errorf(
+			"plugin %s can't run '%s'; no command runner", gvk, path)
+	}
+	return c.run(c, gvk, path, args)
+}
+
 type GeneratorPlugin interface {
 	Generator
 	Configurable
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"path/filepath"
 	"strings"
 
 	"sigs.k8s.io/kustomize/api/ifc"
@@ -87,8 +88,13 @@ func (rf *Factory) SliceFromPatches(
 		}
 		res, err := rf.SliceFromBytes(content)
 		if err != nil {
+			e := kusterr.SyntaxError(filepath.Join(ldr.Root(), string(path)), content)
+			if e != nil {
+				return nil, e
+			}
 			return nil, kusterr.Handler(err, string(path))
 		}
+		SetFileOrigins(res, content, string(path), ldr.Root())
 		result = append(result, res...)
 	}
 	return result, nil
@@ -196,6 +196,10 @@ kind: List
 				test.name, len(rs), len(test.expectedOut))
 		}
 		for i := range rs {
+			if o := rs[i].GetOrigin(); o == nil || o.Path == "" {
+				t.Fatalf("%s: expected an origin naming the patch file", test.name)
+			}
+			rs[i].SetOrigin(nil)
 			if !reflect.DeepEqual(test.expectedOut[i], rs[i]) {
 				t.Fatalf("%s: Got: %v\nexpected:%v",
 					test.name, test.expectedOut[i], rs[i])
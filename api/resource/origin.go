@@ -0,0 +1,147 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resource
+
+import (
+	"bytes"
+	"path/filepath"
+
+	"sigs.k8s.io/kustomize/api/internal/kusterr"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	// OriginAnnotation holds, in YAML, the Origin of a resource.
+	OriginAnnotation = "kustomize.config.k8s.io/origin"
+
+	// TransformationsAnnotation holds, in YAML, the ordered
+	// list of Transformations applied to a resource.
+	TransformationsAnnotation = "kustomize.config.k8s.io/transformations"
+)
+
+// Origin records where a resource came from.
+type Origin struct {
+	// Path is the path of the file the resource was read from,
+	// as written in the kustomization file (i.e. relative to Root).
+	// Empty for generated resources.
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+
+	// Root is the root of the kustomization that read
+	// or generated the resource.
+	Root string `json:"root,omitempty" yaml:"root,omitempty"`
+
+	// Line is the line of the file at which the resource's
+	// YAML document begins.  Zero if unknown.
+	Line int `json:"line,omitempty" yaml:"line,omitempty"`
+
+	// Generator names the generator that made the resource,
+	// e.g. ConfigMapGenerator, or the id of a generator plugin's
+	// config.  Empty for resources read from files.
+	Generator string `json:"generator,omitempty" yaml:"generator,omitempty"`
+}
+
+// WrapError returns err positioned at the file and line the
+// resource was read from, for errors in the resource itself,
+// e.g. a patch that matches nothing.  It returns err unchanged
+// if the origin is nil or doesn't name a file.
+func (o *Origin) WrapError(err error) error {
+	if o == nil || o.Path == "" || err == nil {
+		return err
+	}
+	return kusterr.At(kusterr.Position{
+		Path: filepath.Join(o.Root, o.Path),
+		Line: o.Line,
+	}, err)
+}
+
+// Transformation records a transformer that changed a resource.
+type Transformation struct {
+	// Transformer names the transformer, e.g. PrefixSuffixTransformer,
+	// or the id of a transformer plugin's config.
+	Transformer string `json:"transformer" yaml:"transformer"`
+
+	// Root is the root of the kustomization that
+	// configured the transformer.
+	Root string `json:"root,omitempty" yaml:"root,omitempty"`
+}
+
+// GetOrigin returns the origin of the resource, or nil if unknown.
+func (r *Resource) GetOrigin() *Origin {
+	return r.origin
+}
+
+// SetOrigin sets the origin of the resource.
+func (r *Resource) SetOrigin(o *Origin) {
+	r.origin = o
+}
+
+// GetTransformations returns the transformations that
+// changed the resource, in the order they were applied.
+func (r *Resource) GetTransformations() []Transformation {
+	return r.transformations
+}
+
+// AppendTransformation notes that a transformer changed the resource.
+func (r *Resource) AppendTransformation(t Transformation) {
+	r.transformations = append(r.transformations, t)
+}
+
+// SetFileOrigins sets the origin of each of the given resources,
+// read from content, the content of the file at path under root.
+// The line each resource's document begins at is noted too, unless
+// the documents can't be matched up with the resources, as when
+// content holds a List.
+func SetFileOrigins(resources []*Resource, content []byte, path, root string) {
+	lines := documentLines(content)
+	if len(lines) != len(resources) {
+		lines = make([]int, len(resources))
+	}
+	for i, r := range resources {
+		r.SetOrigin(&Origin{Path: path, Root: root, Line: lines[i]})
+	}
+}
+
+// ListItemOrigins returns the origins of the items of the
+// list that is the first YAML document of content, the content
+// of the file at path under root, e.g. the operations of a JSON
+// patch.  If content doesn't hold a list, there are none.
+func ListItemOrigins(content []byte, path, root string) []*Origin {
+	var n yaml.Node
+	if yaml.Unmarshal(content, &n) != nil || len(n.Content) == 0 ||
+		n.Content[0].Kind != yaml.SequenceNode {
+		return nil
+	}
+	var result []*Origin
+	for _, item := range n.Content[0].Content {
+		result = append(result,
+			&Origin{Path: path, Root: root, Line: item.Line})
+	}
+	return result
+}
+
+// documentLines returns the line of the first field of each
+// non-empty YAML document in content.
+func documentLines(content []byte) []int {
+	var result []int
+	d := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var n yaml.Node
+		if d.Decode(&n) != nil {
+			return result
+		}
+		if len(n.Content) == 0 || n.Content[0].Tag == "!!null" {
+			continue
+		}
+		result = append(result, n.Content[0].Line)
+	}
+}
+
+func (r *Resource) copyTransformations() []Transformation {
+	if r.transformations == nil {
+		return nil
+	}
+	s := make([]Transformation, len(r.transformations))
+	copy(s, r.transformations)
+	return s
+}
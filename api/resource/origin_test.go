@@ -0,0 +1,98 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resource_test
+
+import (
+	"fmt"
+	"testing"
+
+	. "sigs.k8s.io/kustomize/api/resource"
+)
+
+func TestSetFileOrigins(t *testing.T) {
+	content := []byte(`
+# a comment
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+---
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: b
+`)
+	resources, err := factory.SliceFromBytes(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetFileOrigins(resources, content, "maps.yaml", "/app")
+	for i, line := range []int{3, 9} {
+		o := resources[i].GetOrigin()
+		if o.Path != "maps.yaml" || o.Root != "/app" || o.Line != line {
+			t.Errorf("resource %d: unexpected origin %v", i, o)
+		}
+	}
+	err = resources[1].GetOrigin().WrapError(fmt.Errorf("bad"))
+	if err.Error() != "/app/maps.yaml:9: bad" {
+		t.Errorf("unexpected error %q", err)
+	}
+}
+
+func TestSetFileOriginsOfList(t *testing.T) {
+	content := []byte(`
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: a
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: b
+`)
+	resources, err := factory.SliceFromBytes(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetFileOrigins(resources, content, "maps.yaml", "/app")
+	for i := range resources {
+		o := resources[i].GetOrigin()
+		if o.Path != "maps.yaml" || o.Line != 0 {
+			t.Errorf("resource %d: unexpected origin %v", i, o)
+		}
+	}
+	var o *Origin
+	if err := o.WrapError(fmt.Errorf("bad")); err.Error() != "bad" {
+		t.Errorf("unexpected error %q", err)
+	}
+}
+
+func TestListItemOrigins(t *testing.T) {
+	content := []byte(`
+- op: add
+  path: /a
+  value: 1
+-   op: remove
+    path: /b
+`)
+	origins := ListItemOrigins(content, "ops.yaml", "/app")
+	if len(origins) != 2 {
+		t.Fatalf("expected 2 origins, got %v", origins)
+	}
+	for i, line := range []int{2, 5} {
+		if o := origins[i]; o.Path != "ops.yaml" || o.Root != "/app" || o.Line != line {
+			t.Errorf("item %d: unexpected origin %v", i, o)
+		}
+	}
+	if o := ListItemOrigins([]byte(`[{"op": "add"}, {"op": "remove"}]`), "ops.json", "/app"); len(o) != 2 || o[1].Line != 1 {
+		t.Errorf("unexpected origins of JSON %v", o)
+	}
+	if o := ListItemOrigins([]byte("a: b\n"), "ops.yaml", "/app"); o != nil {
+		t.Errorf("expected no origins, got %v", o)
+	}
+}
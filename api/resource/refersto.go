@@ -0,0 +1,50 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resource
+
+import (
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// RefersTo returns true if r holds the name of other in one
+// of the fields of fss selecting r's Gvk, the fields that may
+// hold the names of resources like other.  Namespaced
+// resources only refer to those in the same namespace.
+func (r *Resource) RefersTo(other *Resource, fss types.FsSlice) bool {
+	gvk := r.GetGvk()
+	if gvk.IsNamespaceableKind() &&
+		other.GetGvk().IsNamespaceableKind() &&
+		r.GetNamespace() != other.GetNamespace() {
+		return false
+	}
+	for _, fs := range fss {
+		if gvk.IsSelected(&fs.Gvk) &&
+			holdsString(r.Map(), fs.PathSlice(), other.GetName()) {
+			return true
+		}
+	}
+	return false
+}
+
+// holdsString returns true if the field at path in node, or
+// any field at path in the lists along the way, is s or is
+// a list holding s.
+func holdsString(node interface{}, path []string, s string) bool {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		if len(path) == 0 {
+			return false
+		}
+		return holdsString(n[path[0]], path[1:], s)
+	case []interface{}:
+		for _, e := range n {
+			if holdsString(e, path, s) {
+				return true
+			}
+		}
+	case string:
+		return len(path) == 0 && n == s
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package resource_test
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/resid"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+func TestRefersTo(t *testing.T) {
+	secret := factory.FromMap(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata": map[string]interface{}{
+			"name": "token",
+		},
+	})
+	pod := func(ns string, volumes ...interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Pod",
+			"metadata": map[string]interface{}{
+				"name":      "pod",
+				"namespace": ns,
+			},
+			"spec": map[string]interface{}{
+				"volumes": volumes,
+			},
+		}
+	}
+	volume := func(name string) interface{} {
+		return map[string]interface{}{
+			"secret": map[string]interface{}{"secretName": name},
+		}
+	}
+	fss := types.FsSlice{{
+		Gvk:  resid.Gvk{Kind: "Pod"},
+		Path: "spec/volumes/secret/secretName",
+	}}
+	testCases := map[string]struct {
+		pod      map[string]interface{}
+		fss      types.FsSlice
+		expected bool
+	}{
+		"in a list": {
+			pod:      pod("", volume("other"), volume("token")),
+			fss:      fss,
+			expected: true,
+		},
+		"not held": {
+			pod: pod("", volume("other")),
+			fss: fss,
+		},
+		"other namespace": {
+			pod: pod("elsewhere", volume("token")),
+			fss: fss,
+		},
+		"other kind": {
+			pod: pod("", volume("token")),
+			fss: types.FsSlice{{
+				Gvk:  resid.Gvk{Kind: "Deployment"},
+				Path: fss[0].Path,
+			}},
+		},
+	}
+	for name, tc := range testCases {
+		actual := factory.FromMap(tc.pod).RefersTo(secret, tc.fss)
+		if actual != tc.expected {
+			t.Errorf("%s: expected %v", name, tc.expected)
+		}
+	}
+}
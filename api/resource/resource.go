@@ -18,13 +18,16 @@ import (
 // paired with a GenerationBehavior.
 type Resource struct {
 	ifc.Kunstructured
-	originalName string
-	originalNs   string
-	options      *types.GenArgs
-	refBy        []resid.ResId
-	refVarNames  []string
-	namePrefixes []string
-	nameSuffixes []string
+	originalName    string
+	originalNs      string
+	options         *types.GenArgs
+	refBy           []resid.ResId
+	refVarNames     []string
+	namePrefixes    []string
+	nameSuffixes    []string
+	origin          *Origin
+	transformations []Transformation
+	configHash      *types.ConfigHash
 }
 
 // ResCtx is an interface describing the contextual added
@@ -70,6 +73,9 @@ func (r *Resource) copyOtherFields(other *Resource) {
 	r.refVarNames = copyStringSlice(other.refVarNames)
 	r.namePrefixes = copyStringSlice(other.namePrefixes)
 	r.nameSuffixes = copyStringSlice(other.nameSuffixes)
+	r.origin = other.origin
+	r.transformations = other.copyTransformations()
+	r.configHash = other.configHash
 }
 
 func (r *Resource) Equals(o *Resource) bool {
@@ -203,6 +209,19 @@ func (r *Resource) InSameKustomizeCtx(rctxm ResCtxMatcher) bool {
 	return rctxm(r)
 }
 
+// SetConfigHash asks for the pod templates of the resource
+// to be annotated with the hash of the ConfigMaps and Secrets
+// they refer to, as c says; nil asks for no annotation.
+func (r *Resource) SetConfigHash(c *types.ConfigHash) {
+	r.configHash = c
+}
+
+// ConfigHash returns how to annotate the pod templates
+// of the resource, or nil if they aren't to be.
+func (r *Resource) ConfigHash() *types.ConfigHash {
+	return r.configHash
+}
+
 func (r *Resource) GetOriginalName() string {
 	return r.originalName
 }
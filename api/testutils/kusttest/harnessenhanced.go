@@ -19,6 +19,7 @@ import (
 	"sigs.k8s.io/kustomize/api/resmap"
 	"sigs.k8s.io/kustomize/api/resource"
 	valtest_test "sigs.k8s.io/kustomize/api/testutils/valtest"
+	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
@@ -29,6 +30,7 @@ type HarnessEnhanced struct {
 	pte *pluginTestEnv
 	rf  *resmap.Factory
 	ldr ifc.Loader
+	pc  *types.PluginConfig
 	pl  *pLdr.Loader
 }
 
@@ -50,6 +52,7 @@ func MakeEnhancedHarness(t *testing.T) *HarnessEnhanced {
 		Harness: Harness{t: t, fSys: fSys},
 		pte:     pte,
 		rf:      rf,
+		pc:      pc,
 		pl:      pLdr.NewLoader(pc, rf)}
 	result.ResetLoaderRoot(filesys.Separator)
 
@@ -60,6 +63,12 @@ func (th *HarnessEnhanced) Reset() {
 	th.pte.reset()
 }
 
+// PluginConfig returns the plugin config the
+// plugins run with, for tests to change.
+func (th *HarnessEnhanced) PluginConfig() *types.PluginConfig {
+	return th.pc
+}
+
 func (th *HarnessEnhanced) BuildGoPlugin(g, v, k string) *HarnessEnhanced {
 	th.pte.buildGoPlugin(g, v, k)
 	return th
@@ -89,20 +98,31 @@ func (th *HarnessEnhanced) ResetLoaderRoot(root string) {
 
 func (th *HarnessEnhanced) LoadAndRunGenerator(
 	config string) resmap.ResMap {
-	res, err := th.rf.RF().FromBytes([]byte(config))
+	rm, err := th.RunGenerator(config)
 	if err != nil {
 		th.t.Fatalf("Err: %v", err)
 	}
-	g, err := th.pl.LoadGenerator(
-		th.ldr, valtest_test.MakeFakeValidator(), res)
+	return rm
+}
+
+func (th *HarnessEnhanced) ErrorFromLoadAndRunGenerator(
+	config string) error {
+	_, err := th.RunGenerator(config)
+	return err
+}
+
+func (th *HarnessEnhanced) RunGenerator(
+	config string) (resmap.ResMap, error) {
+	res, err := th.rf.RF().FromBytes([]byte(config))
 	if err != nil {
 		th.t.Fatalf("Err: %v", err)
 	}
-	rm, err := g.Generate()
+	g, err := th.pl.LoadGenerator(
+		th.ldr, valtest_test.MakeFakeValidator(), res)
 	if err != nil {
-		th.t.Fatalf("Err: %v", err)
+		return nil, err
 	}
-	return rm
+	return g.Generate()
 }
 
 func (th *HarnessEnhanced) LoadAndRunTransformer(
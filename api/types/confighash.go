@@ -0,0 +1,19 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// DefaultConfigHashAnnotation is the annotation that
+// holds the hash of the ConfigMaps and Secrets a pod
+// template refers to, unless another is configured.
+const DefaultConfigHashAnnotation = "kustomize.config.k8s.io/config-hash"
+
+// ConfigHash asks for each pod template to be annotated
+// with a hash of the contents of the ConfigMaps and Secrets
+// it refers to, so that changing their contents rolls out
+// new pods even though their names stay the same.
+type ConfigHash struct {
+	// Annotation is the key of the annotation.
+	// The default is DefaultConfigHashAnnotation.
+	Annotation string `json:"annotation,omitempty" yaml:"annotation,omitempty"`
+}
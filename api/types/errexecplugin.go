@@ -0,0 +1,55 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/resid"
+)
+
+// ErrExecPlugin is the failure of an exec plugin
+// to run to completion.
+type ErrExecPlugin struct {
+	// Gvk is that of the plugin's config.
+	Gvk resid.Gvk
+
+	// Path is the path of the plugin's executable.
+	Path string
+
+	// Reason says how the plugin failed, e.g.
+	// "timed out after 30s", "exit status 1".
+	Reason string
+
+	// Stderr is the tail of what the plugin
+	// wrote to stderr.
+	Stderr string
+}
+
+func (e *ErrExecPlugin) Error() string {
+	apiVersion := e.Gvk.Version
+	if e.Gvk.Group != "" {
+		apiVersion = e.Gvk.Group + "/" + apiVersion
+	}
+	msg := fmt.Sprintf(
+		"exec plugin %s/%s (%s) failed: %s",
+		apiVersion, e.Gvk.Kind, e.Path, e.Reason)
+	if e.Stderr != "" {
+		msg += "; stderr ends with:\n" + e.Stderr
+	}
+	return msg
+}
+
+// AsErrExecPlugin returns the ErrExecPlugin
+// the given error is, or wraps, if any.
+func AsErrExecPlugin(err error) (*ErrExecPlugin, bool) {
+	e, ok := errors.Cause(err).(*ErrExecPlugin)
+	return e, ok
+}
+
+func IsErrExecPlugin(err error) bool {
+	_, ok := AsErrExecPlugin(err)
+	return ok
+}
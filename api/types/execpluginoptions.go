@@ -0,0 +1,42 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import "time"
+
+// ExecPluginOptions say how exec plugins run.
+type ExecPluginOptions struct {
+	// Timeout is how long an exec plugin may run before
+	// it's killed; zero means no limit.  A plugin's config
+	// may ask for less, but not more.
+	Timeout time.Duration
+
+	// EnvAllowList names the environment variables exec
+	// plugins get from kustomize's environment; a name
+	// ending in '*' names every variable with that prefix.
+	// If nil, DefaultExecPluginEnvAllowList is used.
+	EnvAllowList []string
+
+	// MemoryLimit is the most virtual memory, in bytes,
+	// an exec plugin may use; zero means no limit.
+	MemoryLimit int64
+
+	// CPULimit is the most CPU time an exec plugin
+	// may use; zero means no limit.
+	CPULimit time.Duration
+}
+
+// DefaultExecPluginEnvAllowList names the environment
+// variables exec plugins get by default.
+var DefaultExecPluginEnvAllowList = []string{
+	"HOME",
+	"KUSTOMIZE_*",
+	"LANG",
+	"LC_*",
+	"PATH",
+	"TMPDIR",
+	"TZ",
+	"USER",
+	"XDG_*",
+}
@@ -0,0 +1,31 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// FnPluginLoadingOptions say which function plugins may run,
+// and how.  A function plugin is a generator or transformer
+// config with a config.kubernetes.io/function annotation,
+// naming a container image or starlark script to run it
+// with, as 'kustomize config run' would.
+type FnPluginLoadingOptions struct {
+	// EnableContainers allows running container functions.
+	EnableContainers bool
+
+	// EnableStar allows running starlark functions.
+	EnableStar bool
+
+	// Network gives network access to the container
+	// functions asking for it; they fail otherwise.
+	Network bool
+
+	// NetworkName is the docker network they get,
+	// "bridge" if empty.
+	NetworkName string
+
+	// Mounts are storage mounts, in the syntax of
+	// docker's --mount flag, given to every container
+	// function.  Mounts named in function configs are
+	// ignored.
+	Mounts []string
+}
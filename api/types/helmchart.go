@@ -0,0 +1,39 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// DefaultHelmReleaseName is the release name
+// of a chart that doesn't specify one.
+const DefaultHelmReleaseName = "release-name"
+
+// HelmChart specifies a local Helm chart to render
+// with `helm template`, adding its resources to
+// those of the kustomization.
+type HelmChart struct {
+	// Chart is the path of the chart, a directory
+	// or a .tgz archive, relative to the kustomization.
+	Chart string `json:"chart,omitempty" yaml:"chart,omitempty"`
+
+	// ReleaseName is the name of the release,
+	// DefaultHelmReleaseName if unspecified.
+	ReleaseName string `json:"releaseName,omitempty" yaml:"releaseName,omitempty"`
+
+	// Namespace is the namespace of the release.
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+
+	// ValuesFile is the path of a file holding values
+	// for the chart, relative to the kustomization.
+	ValuesFile string `json:"valuesFile,omitempty" yaml:"valuesFile,omitempty"`
+}
+
+// HelmConfig says whether helm charts may be
+// inflated, and with which helm binary.
+type HelmConfig struct {
+	// Enabled allows inflating helm charts.
+	Enabled bool
+
+	// Command is the helm binary to run,
+	// "helm" if empty.
+	Command string
+}
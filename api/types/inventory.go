@@ -3,10 +3,48 @@
 
 package types
 
+// The types of object that may hold an inventory.
+const (
+	InventoryTypeConfigMap = "ConfigMap"
+	InventoryTypeSecret    = "Secret"
+	InventoryTypeCustom    = "Custom"
+)
+
 // Inventory records all objects touched in a build operation.
 type Inventory struct {
+	// Type is the type of object holding the inventory:
+	// ConfigMap, Secret or Custom.
 	Type      string   `json:"type,omitempty" yaml:"type,omitempty"`
 	ConfigMap NameArgs `json:"configMap,omitempty" yaml:"configMap,omitempty"`
+	Secret    NameArgs `json:"secret,omitempty" yaml:"secret,omitempty"`
+
+	// Custom is the object of a Custom inventory,
+	// typically a custom resource.
+	Custom InventoryObject `json:"custom,omitempty" yaml:"custom,omitempty"`
+
+	// AllowCrossNamespace allows the inventory object
+	// to be in a namespace other than the kustomization's.
+	AllowCrossNamespace bool `json:"allowCrossNamespace,omitempty" yaml:"allowCrossNamespace,omitempty"`
+}
+
+// Object returns the name and namespace of the
+// inventory object, per the type of the inventory.
+func (inv *Inventory) Object() NameArgs {
+	switch inv.Type {
+	case InventoryTypeSecret:
+		return inv.Secret
+	case InventoryTypeCustom:
+		return inv.Custom.NameArgs
+	default:
+		return inv.ConfigMap
+	}
+}
+
+// InventoryObject holds the apiVersion, kind,
+// name and namespace of an inventory object.
+type InventoryObject struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+	NameArgs `json:",inline" yaml:",inline"`
 }
 
 // NameArgs holds both namespace and name.
@@ -6,9 +6,13 @@ package types
 const (
 	KustomizationVersion = "kustomize.config.k8s.io/v1beta1"
 	KustomizationKind    = "Kustomization"
+	ComponentVersion     = "kustomize.config.k8s.io/v1alpha1"
+	ComponentKind        = "Component"
 )
 
 // Kustomization holds the information needed to generate customized k8s api resources.
+// A Kustomization of kind Component can't be built on its own as a base;
+// it's mixed into the kustomizations that list it under Components.
 type Kustomization struct {
 	TypeMeta `json:",inline" yaml:",inline"`
 
@@ -30,6 +34,10 @@ type Kustomization struct {
 	// CommonLabels to add to all objects and selectors.
 	CommonLabels map[string]string `json:"commonLabels,omitempty" yaml:"commonLabels,omitempty"`
 
+	// Labels to add to all objects, with options
+	// for adding them to selectors and templates.
+	Labels []Label `json:"labels,omitempty" yaml:"labels,omitempty"`
+
 	// CommonAnnotations to add to all objects.
 	CommonAnnotations map[string]string `json:"commonAnnotations,omitempty" yaml:"commonAnnotations,omitempty"`
 
@@ -58,6 +66,16 @@ type Kustomization struct {
 	// specification. This can also be done with a patch.
 	Replicas []Replica `json:"replicas,omitempty" yaml:"replicas,omitempty"`
 
+	// Replacements is a list of replacements, each of which copies
+	// a value - from a field of one resource, or a literal - into
+	// fields of the resources matching a selector.
+	Replacements []Replacement `json:"replacements,omitempty" yaml:"replacements,omitempty"`
+
+	// Exclude is a list of selectors of resources to remove,
+	// e.g. resources inherited from a base that an overlay
+	// doesn't want.
+	Exclude []Selector `json:"exclude,omitempty" yaml:"exclude,omitempty"`
+
 	// Vars allow things modified by kustomize to be injected into a
 	// kubernetes object specification. A var is a name (e.g. FOO) associated
 	// with a field in a specific resource instance.  The field must
@@ -82,11 +100,23 @@ type Kustomization struct {
 	// CRDs themselves are not modified.
 	Crds []string `json:"crds,omitempty" yaml:"crds,omitempty"`
 
+	// OpenAPI specifies relative paths to files of OpenAPI
+	// definitions, whose merge keys and patch strategies
+	// apply to strategic merge patches of custom resources.
+	OpenAPI []string `json:"openapi,omitempty" yaml:"openapi,omitempty"`
+
 	// Deprecated.
 	// Anything that would have been specified here should
 	// be specified in the Resources field instead.
 	Bases []string `json:"bases,omitempty" yaml:"bases,omitempty"`
 
+	// Components specifies relative paths, absolute paths or URLs
+	// of kustomizations of kind Component.  Unlike a resource, a
+	// component isn't built in isolation; its resources, generators,
+	// transformers etc. are applied, in the order listed, to the
+	// resources accumulated so far by the including kustomization.
+	Components []string `json:"components,omitempty" yaml:"components,omitempty"`
+
 	//
 	// Generators (operators that create operands)
 	//
@@ -108,6 +138,11 @@ type Kustomization struct {
 	// GeneratorOptions modify behavior of all ConfigMap and Secret generators.
 	GeneratorOptions *GeneratorOptions `json:"generatorOptions,omitempty" yaml:"generatorOptions,omitempty"`
 
+	// HelmCharts is a list of local Helm charts to render
+	// with `helm template`, whose resources are added to
+	// those generated by the kustomization.
+	HelmCharts []HelmChart `json:"helmCharts,omitempty" yaml:"helmCharts,omitempty"`
+
 	// Configurations is a list of transformer configuration files
 	Configurations []string `json:"configurations,omitempty" yaml:"configurations,omitempty"`
 
@@ -120,6 +155,14 @@ type Kustomization struct {
 	// Inventory appends an object that contains the record
 	// of all other objects, which can be used in apply, prune and delete
 	Inventory *Inventory `json:"inventory,omitempty" yaml:"inventory,omitempty"`
+
+	// ConfigHash, if set, annotates the pod templates of the
+	// resources of this kustomization, and of its bases, with
+	// a hash of the ConfigMaps and Secrets they refer to.
+	ConfigHash *ConfigHash `json:"configHash,omitempty" yaml:"configHash,omitempty"`
+
+	// SortOptions say how to order the output.
+	SortOptions *SortOptions `json:"sortOptions,omitempty" yaml:"sortOptions,omitempty"`
 }
 
 // FixKustomizationPostUnmarshalling fixes things
@@ -127,23 +170,31 @@ type Kustomization struct {
 // moving content of deprecated fields to newer
 // fields.
 func (k *Kustomization) FixKustomizationPostUnmarshalling() {
-	if k.APIVersion == "" {
-		k.APIVersion = KustomizationVersion
-	}
 	if k.Kind == "" {
 		k.Kind = KustomizationKind
 	}
+	if k.APIVersion == "" {
+		if k.Kind == ComponentKind {
+			k.APIVersion = ComponentVersion
+		} else {
+			k.APIVersion = KustomizationVersion
+		}
+	}
 	k.Resources = append(k.Resources, k.Bases...)
 	k.Bases = nil
 }
 
 func (k *Kustomization) EnforceFields() []string {
 	var errs []string
-	if k.APIVersion != "" && k.APIVersion != KustomizationVersion {
-		errs = append(errs, "apiVersion should be "+KustomizationVersion)
+	if k.Kind != "" && k.Kind != KustomizationKind && k.Kind != ComponentKind {
+		errs = append(errs, "kind should be "+KustomizationKind+" or "+ComponentKind)
+	}
+	requiredVersion := KustomizationVersion
+	if k.Kind == ComponentKind {
+		requiredVersion = ComponentVersion
 	}
-	if k.Kind != "" && k.Kind != KustomizationKind {
-		errs = append(errs, "kind should be "+KustomizationKind)
+	if k.APIVersion != "" && k.APIVersion != requiredVersion {
+		errs = append(errs, "apiVersion for "+k.Kind+" should be "+requiredVersion)
 	}
 	return errs
 }
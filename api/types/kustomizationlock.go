@@ -0,0 +1,33 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+const (
+	KustomizationLockVersion = "kustomize.config.k8s.io/v1alpha1"
+	KustomizationLockKind    = "KustomizationLock"
+)
+
+// KustomizationLock records the remote roots, e.g. remote
+// bases, that a kustomization loads, directly or via other
+// kustomizations, so that a build can tell if they change.
+type KustomizationLock struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+
+	// Remotes are the remote roots, sorted by URL.
+	Remotes []RemoteLock `json:"remotes,omitempty" yaml:"remotes,omitempty"`
+}
+
+// RemoteLock records a remote root.
+type RemoteLock struct {
+	// URL of the root, as a kustomization refers to it,
+	// e.g. github.com/org/repo//dir?ref=v1.
+	URL string `json:"url" yaml:"url"`
+
+	// Commit that the ref of the URL resolved to,
+	// if the root is in a git repo.
+	Commit string `json:"commit,omitempty" yaml:"commit,omitempty"`
+
+	// Digest of the content of the root.
+	Digest string `json:"digest" yaml:"digest"`
+}
@@ -0,0 +1,23 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// Label holds labels to add to all objects, and options
+// saying where else to add them.  Unlike CommonLabels,
+// by default a Label's pairs are added to metadata only,
+// so adding one to a running app leaves its immutable
+// selectors alone.
+type Label struct {
+	// Pairs to add to the labels of all objects.
+	Pairs map[string]string `json:"pairs,omitempty" yaml:"pairs,omitempty"`
+
+	// IncludeSelectors adds the pairs to label selectors too,
+	// and to the pod templates they select, as CommonLabels does.
+	IncludeSelectors bool `json:"includeSelectors,omitempty" yaml:"includeSelectors,omitempty"`
+
+	// IncludeTemplates adds the pairs to the metadata
+	// of templates, e.g. the pod template of a Deployment,
+	// without adding them to selectors.
+	IncludeTemplates bool `json:"includeTemplates,omitempty" yaml:"includeTemplates,omitempty"`
+}
@@ -21,4 +21,9 @@ const (
 	// relative paths to patch or resources files outside
 	// its own tree.
 	LoadRestrictionsNone
+
+	// Files referenced by a kustomization file must be in
+	// or under the directory holding the kustomization
+	// file itself, or one of a list of allowed directories.
+	LoadRestrictionsAllowList
 )
@@ -11,11 +11,12 @@ func _() {
 	_ = x[LoadRestrictionsUnknown-0]
 	_ = x[LoadRestrictionsRootOnly-1]
 	_ = x[LoadRestrictionsNone-2]
+	_ = x[LoadRestrictionsAllowList-3]
 }
 
-const _LoadRestrictions_name = "LoadRestrictionsUnknownLoadRestrictionsRootOnlyLoadRestrictionsNone"
+const _LoadRestrictions_name = "LoadRestrictionsUnknownLoadRestrictionsRootOnlyLoadRestrictionsNoneLoadRestrictionsAllowList"
 
-var _LoadRestrictions_index = [...]uint8{0, 23, 47, 67}
+var _LoadRestrictions_index = [...]uint8{0, 23, 47, 67, 92}
 
 func (i LoadRestrictions) String() string {
 	if i < 0 || i >= LoadRestrictions(len(_LoadRestrictions_index)-1) {
@@ -3,8 +3,36 @@
 
 package types
 
-// Patch represent either a Strategic Merge Patch or a JSON patch
-// and its targets.
+// PatchType is the kind of a patch, saying how it's applied.
+type PatchType string
+
+const (
+	// UnspecifiedPatchType leaves the kind of the patch to be
+	// detected from its content, as either a strategic merge
+	// patch or a JSON patch.
+	UnspecifiedPatchType PatchType = ""
+
+	// StrategicMergePatchType is a strategic merge patch.
+	StrategicMergePatchType PatchType = "strategicMerge"
+
+	// Json6902PatchType is a JSON patch, per RFC 6902.
+	Json6902PatchType PatchType = "json6902"
+
+	// JsonMergePatchType is a JSON merge patch, per RFC 7386.
+	// Unlike a strategic merge patch, it replaces lists
+	// whole, whatever the schema of the patched resource.
+	JsonMergePatchType PatchType = "jsonMerge"
+)
+
+// PatchTypes are the kinds of patches that may be specified.
+var PatchTypes = []PatchType{
+	StrategicMergePatchType,
+	Json6902PatchType,
+	JsonMergePatchType,
+}
+
+// Patch represent either a Strategic Merge Patch, a JSON patch
+// or a JSON merge patch, and its targets.
 // The content of the patch can either be from a file
 // or from an inline string.
 type Patch struct {
@@ -16,4 +44,8 @@ type Patch struct {
 
 	// Target points to the resources that the patch is applied to
 	Target *Selector `json:"target,omitempty" yaml:"target,omitempty"`
+
+	// Type is the kind of the patch.  If unspecified,
+	// it's detected as a strategic merge or JSON patch.
+	Type PatchType `json:"type,omitempty" yaml:"type,omitempty"`
 }
@@ -28,4 +28,18 @@ type PluginConfig struct {
 	// PluginRestrictions defines the plugin restriction state.
 	// See type for more information.
 	PluginRestrictions PluginRestrictions
+
+	// FnpLoadingOptions say which function plugins may run,
+	// regardless of PluginRestrictions, and how.
+	FnpLoadingOptions FnPluginLoadingOptions
+
+	// ExecpOptions say how exec plugins run.
+	// The executables builtin plugins run,
+	// e.g. helm, run with them too.
+	ExecpOptions ExecPluginOptions
+
+	// HelmConfig says whether helm charts may be
+	// inflated, regardless of PluginRestrictions,
+	// and how.
+	HelmConfig HelmConfig
 }
@@ -16,7 +16,7 @@ type Replacement struct {
 //  - from a string
 type ReplSource struct {
 	ObjRef   *Target `json:"objref,omitempty" yaml:"objref,omitempty"`
-	FieldRef string  `json:"fieldref,omitempty" yaml:"fiedldref,omitempty"`
+	FieldRef string  `json:"fieldref,omitempty" yaml:"fieldref,omitempty"`
 	Value    string  `json:"value,omitempty" yaml:"value,omitempty"`
 }
 
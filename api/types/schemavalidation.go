@@ -0,0 +1,24 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// Whether, and how strictly, to check the output of
+// a build against the OpenAPI schemas of its resources.
+//
+//go:generate stringer -type=SchemaValidation
+type SchemaValidation int
+
+const (
+	SchemaValidationUnknown SchemaValidation = iota
+
+	// Don't check resources against their schemas.
+	SchemaValidationOff
+
+	// Log each problem found as a warning,
+	// and carry on with the build.
+	SchemaValidationWarn
+
+	// Fail the build if any problem is found.
+	SchemaValidationError
+)
@@ -0,0 +1,91 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"sigs.k8s.io/kustomize/api/resid"
+)
+
+// SortOrder names a way to order the resources of a build
+// just before output.
+type SortOrder string
+
+const (
+	// Order resources by kind, then by name,
+	// per the kind order of the Gvk class.
+	LegacySortOrder SortOrder = "legacy"
+
+	// Leave resources in the depth-first order
+	// in which the kustomizations list them.
+	NoSortOrder SortOrder = "none"
+
+	// Put each resource after the resources it refers
+	// to, e.g. a ConfigMap before the Deployments that
+	// mount it, and a CustomResourceDefinition before
+	// its custom resources.  Webhook configurations
+	// come last.  Otherwise resources are ordered as
+	// they are by the legacy order.
+	TopologicalSortOrder SortOrder = "topological"
+)
+
+// SortOptions say how to order the resources of a build.
+// Only the sortOptions of the kustomization being built
+// count; those of its bases and components are ignored.
+type SortOptions struct {
+	// Order is legacy, none or topological.
+	// The default is legacy.
+	Order SortOrder `json:"order,omitempty" yaml:"order,omitempty"`
+
+	// KindOrder replaces the kind order of the
+	// legacy and topological orders.
+	KindOrder *KindOrder `json:"kindOrder,omitempty" yaml:"kindOrder,omitempty"`
+}
+
+// KindOrder ranks kinds for sorting.  Kinds listed in First
+// come first, in the order listed, kinds listed in Last come
+// last, in the order listed, and other kinds come between,
+// in the legacy order.
+type KindOrder struct {
+	First []string `json:"first,omitempty" yaml:"first,omitempty"`
+	Last  []string `json:"last,omitempty" yaml:"last,omitempty"`
+}
+
+// IsEmpty returns true if the order lists no kinds.
+func (o *KindOrder) IsEmpty() bool {
+	return o == nil || (len(o.First) == 0 && len(o.Last) == 0)
+}
+
+// rank returns the place of a kind in the order;
+// negative for kinds in First, positive for kinds
+// in Last and zero for the rest.
+func (o *KindOrder) rank(kind string) int {
+	for i, k := range o.First {
+		if k == kind {
+			return -len(o.First) + i
+		}
+	}
+	for i, k := range o.Last {
+		if k == kind {
+			return 1 + i
+		}
+	}
+	return 0
+}
+
+// IsLessThan returns true if a resource with id x comes
+// before a resource with id y.  Resources are compared by
+// kind rank, then as Gvk.IsLessThan does, then by the rest
+// of their ids.
+func (o *KindOrder) IsLessThan(x, y resid.ResId) bool {
+	if x.Gvk.Equals(y.Gvk) {
+		return x.String() < y.String()
+	}
+	if !o.IsEmpty() {
+		rx, ry := o.rank(x.Kind), o.rank(y.Kind)
+		if rx != ry {
+			return rx < ry
+		}
+	}
+	return x.Gvk.IsLessThan(y.Gvk)
+}
@@ -0,0 +1,45 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/resid"
+)
+
+func TestKindOrderIsLessThan(t *testing.T) {
+	id := func(kind, name string) resid.ResId {
+		return resid.NewResId(resid.Gvk{Version: "v1", Kind: kind}, name)
+	}
+	order := &KindOrder{
+		First: []string{"Secret", "Service"},
+		Last:  []string{"Namespace"},
+	}
+	testCases := []struct {
+		order    *KindOrder
+		x, y     resid.ResId
+		expected bool
+	}{
+		{order, id("Secret", "a"), id("Service", "a"), true},
+		{order, id("Service", "a"), id("Secret", "a"), false},
+		{order, id("Service", "a"), id("ConfigMap", "a"), true},
+		{order, id("Namespace", "a"), id("ConfigMap", "a"), false},
+		{order, id("Pod", "a"), id("ConfigMap", "a"), false},
+		{order, id("Secret", "a"), id("Secret", "b"), true},
+		// Unlisted kinds are in the legacy order.
+		{order, id("ConfigMap", "a"), id("Pod", "a"), true},
+		{order, id("Pod", "a"), id("ConfigMap", "a"), false},
+		{order, id("ValidatingWebhookConfiguration", "a"), id("Pod", "a"), false},
+		{&KindOrder{Last: []string{"Pod"}}, id("Namespace", "a"), id("ConfigMap", "a"), true},
+		// An empty order is the legacy order.
+		{nil, id("Namespace", "a"), id("ConfigMap", "a"), true},
+		{&KindOrder{}, id("Service", "a"), id("Secret", "a"), false},
+	}
+	for i, tc := range testCases {
+		if actual := tc.order.IsLessThan(tc.x, tc.y); actual != tc.expected {
+			t.Errorf("case %d: expected %v for %s < %s", i, tc.expected, tc.x, tc.y)
+		}
+	}
+}
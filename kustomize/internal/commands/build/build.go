@@ -16,7 +16,6 @@ import (
 	"sigs.k8s.io/kustomize/api/krusty"
 	"sigs.k8s.io/kustomize/api/resmap"
 	"sigs.k8s.io/kustomize/api/resource"
-	"sigs.k8s.io/yaml"
 )
 
 // Options contain the options for running a build
@@ -65,7 +64,7 @@ func NewCmdBuild(out io.Writer) *cobra.Command {
 			if err != nil {
 				return err
 			}
-			return o.RunBuild(out)
+			return krusty.LocateError(o.RunBuild(out))
 		},
 	}
 
@@ -73,9 +72,19 @@ func NewCmdBuild(out io.Writer) *cobra.Command {
 		&o.outputPath,
 		"output", "o", "",
 		"If specified, write the build output to this path.")
-	addFlagLoadRestrictor(cmd.Flags())
+	AddFlagLoadRestrictor(cmd.Flags())
 	addFlagEnablePlugins(cmd.Flags())
 	addFlagReorderOutput(cmd.Flags())
+	addFlagAddProvenance(cmd.Flags())
+	addFlagExplain(cmd.Flags())
+	addFlagValidate(cmd.Flags())
+	addFlagStrict(cmd.Flags())
+	addFlagOutputFormat(cmd.Flags())
+	addFlagOutputPathTemplate(cmd.Flags())
+	addFlagRemoteCache(cmd.Flags())
+	AddFlagFnPlugins(cmd.Flags())
+	AddFlagExecPlugins(cmd.Flags())
+	AddFlagHelm(cmd.Flags())
 	cmd.AddCommand(NewCmdBuildPrune(out))
 	return cmd
 }
@@ -92,7 +101,27 @@ func (o *Options) Validate(args []string) (err error) {
 	} else {
 		o.kustomizationPath = args[0]
 	}
-	err = validateFlagLoadRestrictor()
+	err = ValidateFlagLoadRestrictor()
+	if err != nil {
+		return err
+	}
+	err = validateFlagExplain()
+	if err != nil {
+		return err
+	}
+	err = validateFlagValidate()
+	if err != nil {
+		return err
+	}
+	err = validateFlagOutputFormat()
+	if err != nil {
+		return err
+	}
+	err = validateFlagOutputPathTemplate(o.outputPath)
+	if err != nil {
+		return err
+	}
+	err = validateFlagRemoteCache()
 	if err != nil {
 		return err
 	}
@@ -100,11 +129,20 @@ func (o *Options) Validate(args []string) (err error) {
 	return
 }
 
-func (o *Options) makeOptions() *krusty.Options {
+func (o *Options) makeOptions(fSys filesys.FileSystem) (*krusty.Options, error) {
 	opts := &krusty.Options{
-		DoLegacyResourceSort: o.outOrder == legacy,
-		LoadRestrictions:     getFlagLoadRestrictorValue(),
-		DoPrune:              false,
+		DoLegacyResourceSort:     true,
+		SortOptions:              getSortOptions(o.outOrder),
+		DoPrune:                  false,
+		AddProvenanceAnnotations: isFlagAddProvenanceSet(),
+		SchemaValidation:         getFlagValidateValue(),
+		Strict:                   isFlagStrictSet(),
+		RemoteCacheDir:           getFlagRemoteCacheDirValue(),
+		Offline:                  isFlagOfflineSet(),
+	}
+	err := SetFlagLoadRestrictorOptions(fSys, opts)
+	if err != nil {
+		return nil, err
 	}
 	if isFlagEnablePluginsSet() {
 		c, err := konfig.EnabledPluginConfig()
@@ -115,12 +153,27 @@ func (o *Options) makeOptions() *krusty.Options {
 	} else {
 		opts.PluginConfig = konfig.DisabledPluginConfig()
 	}
-	return opts
+	err = SetFlagPluginOptions(opts.PluginConfig)
+	if err != nil {
+		return nil, err
+	}
+	return opts, nil
 }
 
 func (o *Options) RunBuild(out io.Writer) error {
 	fSys := filesys.MakeFsOnDisk()
-	k := krusty.MakeKustomizer(fSys, o.makeOptions())
+	opts, err := o.makeOptions(fSys)
+	if err != nil {
+		return err
+	}
+	k := krusty.MakeKustomizer(fSys, opts)
+	if isFlagExplainSet() {
+		_, trace, err := k.Explain(o.kustomizationPath)
+		if err != nil {
+			return err
+		}
+		return writeTrace(out, trace)
+	}
 	m, err := k.Run(o.kustomizationPath)
 	if err != nil {
 		return err
@@ -130,7 +183,10 @@ func (o *Options) RunBuild(out io.Writer) error {
 
 func (o *Options) RunBuildPrune(out io.Writer) error {
 	fSys := filesys.MakeFsOnDisk()
-	opts := o.makeOptions()
+	opts, err := o.makeOptions(fSys)
+	if err != nil {
+		return err
+	}
 	opts.DoPrune = true
 	k := krusty.MakeKustomizer(fSys, opts)
 	m, err := k.Run(o.kustomizationPath)
@@ -142,10 +198,13 @@ func (o *Options) RunBuildPrune(out io.Writer) error {
 
 func (o *Options) emitResources(
 	out io.Writer, fSys filesys.FileSystem, m resmap.ResMap) error {
+	if isFlagOutputPathTemplateSet() {
+		return writeFilesByTemplate(fSys, o.outputPath, m)
+	}
 	if o.outputPath != "" && fSys.IsDir(o.outputPath) {
 		return writeIndividualFiles(fSys, o.outputPath, m)
 	}
-	res, err := m.AsYaml()
+	res, err := encodeResources(m)
 	if err != nil {
 		return err
 	}
@@ -168,7 +227,7 @@ func NewCmdBuildPrune(out io.Writer) *cobra.Command {
 			if err != nil {
 				return err
 			}
-			return o.RunBuildPrune(out)
+			return krusty.LocateError(o.RunBuildPrune(out))
 		},
 	}
 	return cmd
@@ -200,12 +259,16 @@ func writeIndividualFiles(
 
 func fileName(res *resource.Resource) string {
 	return strings.ToLower(res.GetGvk().String()) +
-		"_" + strings.ToLower(res.GetName()) + ".yaml"
+		"_" + strings.ToLower(res.GetName()) + outputFileExtension()
 }
 
 func writeFile(
 	fSys filesys.FileSystem, path, fName string, res *resource.Resource) error {
-	out, err := yaml.Marshal(res.Map())
+	m, err := resMapOf([]*resource.Resource{res})
+	if err != nil {
+		return err
+	}
+	out, err := encodeResources(m)
 	if err != nil {
 		return err
 	}
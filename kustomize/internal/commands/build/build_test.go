@@ -4,10 +4,19 @@
 package build
 
 import (
+	"io/ioutil"
+	"reflect"
+	"strings"
 	"testing"
 
 	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/gitcache"
+	"sigs.k8s.io/kustomize/api/k8sdeps/kunstruct"
 	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	resmaptest_test "sigs.k8s.io/kustomize/api/testutils/resmaptest"
+	"sigs.k8s.io/kustomize/api/types"
 )
 
 func TestNewOptionsToSilenceCodeInspectionError(t *testing.T) {
@@ -52,3 +61,289 @@ func TestBuildValidate(t *testing.T) {
 		}
 	}
 }
+
+func makeTestResMap(t *testing.T) resmap.ResMap {
+	rf := resource.NewFactory(kunstruct.NewKunstructuredFactoryImpl())
+	return resmaptest_test.NewRmBuilder(t, rf).
+		Add(map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]interface{}{
+				"name":      "storefront",
+				"namespace": "shop",
+			},
+		}).
+		Add(map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Service",
+			"metadata": map[string]interface{}{
+				"name":      "storefront",
+				"namespace": "shop",
+			},
+		}).
+		Add(map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]interface{}{
+				"name":      "checkout",
+				"namespace": "shop",
+			},
+		}).
+		Add(map[string]interface{}{
+			"apiVersion": "rbac.authorization.k8s.io/v1",
+			"kind":       "ClusterRole",
+			"metadata": map[string]interface{}{
+				"name": "reader",
+			},
+		}).ResMap()
+}
+
+func TestEncodeResources(t *testing.T) {
+	defer func() { flagOutputFormatValue = formatYaml }()
+	m, err := resMapOf(makeTestResMap(t).Resources()[1:3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCases := map[string]string{
+		formatYaml: `apiVersion: v1
+kind: Service
+metadata:
+  name: storefront
+  namespace: shop
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: checkout
+  namespace: shop
+`,
+		formatList: `apiVersion: v1
+items:
+- apiVersion: v1
+  kind: Service
+  metadata:
+    name: storefront
+    namespace: shop
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    name: checkout
+    namespace: shop
+kind: List
+`,
+		formatJson: `{
+  "apiVersion": "v1",
+  "items": [
+    {
+      "apiVersion": "v1",
+      "kind": "Service",
+      "metadata": {
+        "name": "storefront",
+        "namespace": "shop"
+      }
+    },
+    {
+      "apiVersion": "apps/v1",
+      "kind": "Deployment",
+      "metadata": {
+        "name": "checkout",
+        "namespace": "shop"
+      }
+    }
+  ],
+  "kind": "List"
+}
+`,
+		formatNdJson: `{"apiVersion":"v1","kind":"Service","metadata":{"name":"storefront","namespace":"shop"}}
+{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"checkout","namespace":"shop"}}
+`,
+		formatResourceList: `apiVersion: config.kubernetes.io/v1alpha1
+items:
+- apiVersion: v1
+  kind: Service
+  metadata:
+    name: storefront
+    namespace: shop
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    name: checkout
+    namespace: shop
+kind: ResourceList
+`,
+	}
+	for format, expected := range testCases {
+		flagOutputFormatValue = format
+		if err := validateFlagOutputFormat(); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		actual, err := encodeResources(m)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if string(actual) != expected {
+			t.Errorf("%s: expected\n%s\nbut got\n%s", format, expected, actual)
+		}
+	}
+	flagOutputFormatValue = "xml"
+	if validateFlagOutputFormat() == nil {
+		t.Fatalf("expected an error for an unknown format")
+	}
+}
+
+func TestWriteFilesByTemplate(t *testing.T) {
+	defer func() {
+		flagOutputFormatValue = formatYaml
+		flagOutputPathTemplateValue = ""
+	}()
+	flagOutputFormatValue = formatNdJson
+	flagOutputPathTemplateValue =
+		`{{or .Namespace "_cluster"}}/{{lower .Kind}}.json`
+	if err := validateFlagOutputPathTemplate("out"); err != nil {
+		t.Fatal(err)
+	}
+	fSys := filesys.MakeFsInMemory()
+	err := writeFilesByTemplate(fSys, "/out", makeTestResMap(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"/out/shop/deployment.json": `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"storefront","namespace":"shop"}}
+{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"checkout","namespace":"shop"}}
+`,
+		"/out/shop/service.json": `{"apiVersion":"v1","kind":"Service","metadata":{"name":"storefront","namespace":"shop"}}
+`,
+		"/out/_cluster/clusterrole.json": `{"apiVersion":"rbac.authorization.k8s.io/v1","kind":"ClusterRole","metadata":{"name":"reader"}}
+`,
+	}
+	for path, content := range expected {
+		actual, err := fSys.ReadFile(path)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		if string(actual) != content {
+			t.Errorf("%s: expected\n%s\nbut got\n%s", path, content, actual)
+		}
+	}
+}
+
+func TestOutputPathTemplateErrors(t *testing.T) {
+	defer func() { flagOutputPathTemplateValue = "" }()
+	flagOutputPathTemplateValue = "{{.Kind}}.yaml"
+	if validateFlagOutputPathTemplate("") == nil {
+		t.Fatalf("expected an error for a missing output directory")
+	}
+	flagOutputPathTemplateValue = "{{.Kind"
+	if validateFlagOutputPathTemplate("out") == nil {
+		t.Fatalf("expected an error for a bad template")
+	}
+	flagOutputPathTemplateValue = "../{{.Kind}}.yaml"
+	err := writeFilesByTemplate(
+		filesys.MakeFsInMemory(), "/out", makeTestResMap(t))
+	if err == nil || !strings.Contains(err.Error(), "isn't in the output directory") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestGetSortOptions(t *testing.T) {
+	defer func() {
+		flagReorderOutputValue = ""
+		flagReorderFirstValue = nil
+		flagReorderLastValue = nil
+	}()
+	flagReorderOutputValue = "alphabetical"
+	if _, err := validateFlagReorderOutput(); err == nil {
+		t.Fatalf("expected an error for an unknown reordering")
+	}
+	flagReorderOutputValue = ""
+	r, err := validateFlagReorderOutput()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if so := getSortOptions(r); so != nil {
+		t.Fatalf("expected no sort options, got %v", so)
+	}
+	flagReorderFirstValue = []string{"Secret"}
+	so := getSortOptions(r)
+	if so == nil || so.Order != types.LegacySortOrder ||
+		so.KindOrder == nil || so.KindOrder.First[0] != "Secret" {
+		t.Fatalf("unexpected sort options %v", so)
+	}
+	flagReorderOutputValue = "topological"
+	r, err = validateFlagReorderOutput()
+	if err != nil {
+		t.Fatal(err)
+	}
+	so = getSortOptions(r)
+	if so == nil || so.Order != types.TopologicalSortOrder || so.KindOrder == nil {
+		t.Fatalf("unexpected sort options %v", so)
+	}
+}
+
+func TestGetFlagAllowedLoadRoots(t *testing.T) {
+	defer func() {
+		flagAllowedRootValue = nil
+		flagAllowedRootsFileValue = ""
+	}()
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile("/repo/allowed-roots", []byte(`
+# shared by all apps
+/common
+vendor
+`))
+	flagAllowedRootValue = []string{"/extra"}
+	flagAllowedRootsFileValue = "/repo/allowed-roots"
+	roots, err := getFlagAllowedLoadRoots(fSys)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"/extra", "/common", "/repo/vendor"}
+	if !reflect.DeepEqual(roots, expected) {
+		t.Fatalf("expected %v, got %v", expected, roots)
+	}
+}
+
+func TestValidateFlagLoadRestrictorAllowList(t *testing.T) {
+	defer func() {
+		flagLrValue = types.LoadRestrictionsRootOnly.String()
+		flagAllowedRootValue = nil
+	}()
+	flagLrValue = types.LoadRestrictionsAllowList.String()
+	err := ValidateFlagLoadRestrictor()
+	if err == nil || !strings.Contains(err.Error(), "requires --load_allowed_root") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	flagAllowedRootValue = []string{"/common"}
+	if err = ValidateFlagLoadRestrictor(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	flagLrValue = types.LoadRestrictionsRootOnly.String()
+	err = ValidateFlagLoadRestrictor()
+	if err == nil || !strings.Contains(err.Error(), "require --load_restrictor") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestFlagRemoteCacheDefault(t *testing.T) {
+	f := NewCmdBuild(ioutil.Discard).Flags().Lookup(flagRemoteCacheDirName)
+	if f == nil || f.DefValue != "" || getFlagRemoteCacheDirValue() != "" {
+		t.Fatalf("expected the remote cache to be off by default")
+	}
+}
+
+func TestValidateFlagRemoteCache(t *testing.T) {
+	defer func() {
+		flagRemoteCacheDirValue = ""
+		flagOfflineValue = false
+	}()
+	flagOfflineValue = true
+	flagRemoteCacheDirValue = gitcache.DefaultDir()
+	if err := validateFlagRemoteCache(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	flagRemoteCacheDirValue = ""
+	err := validateFlagRemoteCache()
+	if err == nil || !strings.Contains(err.Error(), "requires a --remote-cache-dir") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
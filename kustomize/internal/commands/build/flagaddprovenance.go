@@ -0,0 +1,28 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+)
+
+const (
+	flagAddProvenanceName = "add_provenance_annotations"
+	flagAddProvenanceHelp = `annotate each resource with the file, base or generator
+it came from, and with the transformers that changed it.`
+)
+
+var (
+	flagAddProvenanceValue = false
+)
+
+func addFlagAddProvenance(set *pflag.FlagSet) {
+	set.BoolVar(
+		&flagAddProvenanceValue, flagAddProvenanceName,
+		false, flagAddProvenanceHelp)
+}
+
+func isFlagAddProvenanceSet() bool {
+	return flagAddProvenanceValue
+}
@@ -5,6 +5,7 @@ package build
 
 import (
 	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/types"
 )
 
 const (
@@ -27,3 +28,14 @@ func addFlagEnablePlugins(set *pflag.FlagSet) {
 func isFlagEnablePluginsSet() bool {
 	return flagPluginsEnabledValue
 }
+
+// SetFlagPluginOptions sets the function plugin, helm
+// and exec plugin options of the given plugin config as
+// the flags added by AddFlagFnPlugins, AddFlagHelm and
+// AddFlagExecPlugins say.
+func SetFlagPluginOptions(pc *types.PluginConfig) (err error) {
+	pc.FnpLoadingOptions = getFlagFnpLoadingOptions()
+	pc.HelmConfig = getFlagHelmConfig()
+	pc.ExecpOptions, err = getFlagExecpOptions()
+	return err
+}
@@ -0,0 +1,68 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+const (
+	flagExecTimeoutName = "exec-plugin-timeout"
+	flagExecTimeoutHelp = `how long an exec plugin may run before it's
+killed, e.g. 30s; zero means no limit.  A plugin's config may
+ask for less with the kustomize.config.k8s.io/exec-timeout annotation.`
+	flagExecEnvName = "exec-plugin-env"
+	flagExecEnvHelp = `an environment variable exec plugins get; a name
+ending in '*' names every variable with that prefix.  May be repeated.
+If not given, exec plugins get a default list, including PATH, HOME
+and KUSTOMIZE_*.`
+	flagExecMemoryLimitName = "exec-plugin-memory-limit"
+	flagExecMemoryLimitHelp = `the most virtual memory an exec plugin
+may use, e.g. 512Mi; empty means no limit.`
+	flagExecCPULimitName = "exec-plugin-cpu-limit"
+	flagExecCPULimitHelp = `the most CPU time an exec plugin may use,
+e.g. 10s; zero means no limit.`
+)
+
+var (
+	flagExecpOptionsValue    types.ExecPluginOptions
+	flagExecMemoryLimitValue string
+)
+
+// AddFlagExecPlugins adds the flags limiting exec
+// plugins to the given set, so that commands building
+// kustomizations may share them with build.
+func AddFlagExecPlugins(set *pflag.FlagSet) {
+	set.DurationVar(
+		&flagExecpOptionsValue.Timeout, flagExecTimeoutName,
+		0, flagExecTimeoutHelp)
+	set.StringArrayVar(
+		&flagExecpOptionsValue.EnvAllowList, flagExecEnvName,
+		nil, flagExecEnvHelp)
+	set.StringVar(
+		&flagExecMemoryLimitValue, flagExecMemoryLimitName,
+		"", flagExecMemoryLimitHelp)
+	set.DurationVar(
+		&flagExecpOptionsValue.CPULimit, flagExecCPULimitName,
+		0, flagExecCPULimitHelp)
+}
+
+func getFlagExecpOptions() (types.ExecPluginOptions, error) {
+	opts := flagExecpOptionsValue
+	if flagExecMemoryLimitValue == "" {
+		return opts, nil
+	}
+	q, err := resource.ParseQuantity(flagExecMemoryLimitValue)
+	if err != nil || q.Sign() < 0 {
+		return opts, fmt.Errorf(
+			"illegal flag value --%s %s; expected a quantity of bytes, e.g. 512Mi",
+			flagExecMemoryLimitName, flagExecMemoryLimitValue)
+	}
+	opts.MemoryLimit = q.Value()
+	return opts, nil
+}
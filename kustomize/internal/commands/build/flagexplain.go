@@ -0,0 +1,55 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/explain"
+)
+
+const (
+	flagExplainName = "explain"
+	explainText     = "text"
+	explainJson     = "json"
+)
+
+var (
+	flagExplainValue = ""
+	flagExplainHelp  = "Instead of the resources, print a trace of each " +
+		"generator and transformer run, showing the fields it changed. " +
+		"Use '" + explainText + "' (the default) or '" + explainJson + "'."
+)
+
+func addFlagExplain(set *pflag.FlagSet) {
+	set.StringVar(
+		&flagExplainValue, flagExplainName,
+		"", flagExplainHelp)
+	set.Lookup(flagExplainName).NoOptDefVal = explainText
+}
+
+func validateFlagExplain() error {
+	switch flagExplainValue {
+	case "", explainText, explainJson:
+		return nil
+	default:
+		return fmt.Errorf(
+			"illegal flag value --%s %s; legal values: %v",
+			flagExplainName, flagExplainValue,
+			[]string{explainText, explainJson})
+	}
+}
+
+func isFlagExplainSet() bool {
+	return flagExplainValue != ""
+}
+
+func writeTrace(out io.Writer, trace *explain.Trace) error {
+	if flagExplainValue == explainJson {
+		return trace.WriteJSON(out)
+	}
+	return trace.WriteText(out)
+}
@@ -0,0 +1,59 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+const (
+	flagEnableContainersName = "enable-container-functions"
+	flagEnableContainersHelp = `run the container functions named by the
+config.kubernetes.io/function annotation of generator and
+transformer configs, using docker; requires
+--enable_alpha_plugins.`
+	flagEnableStarName = "enable-star"
+	flagEnableStarHelp = `run the starlark functions named by the
+config.kubernetes.io/function annotation of generator and
+transformer configs; requires --enable_alpha_plugins.`
+	flagNetworkName = "network"
+	flagNetworkHelp = `give network access to the container
+functions asking for it.`
+	flagNetworkNameName = "network-name"
+	flagNetworkNameHelp = `the docker network container functions
+get, if given network access.`
+	flagMountName = "mount"
+	flagMountHelp = `a storage mount, in the syntax of docker's
+--mount flag, given to every container function; may be repeated.`
+)
+
+var (
+	flagFnpLoadingOptionsValue types.FnPluginLoadingOptions
+)
+
+// AddFlagFnPlugins adds the flags running function
+// plugins to the given set, so that commands building
+// kustomizations may share them with build.
+func AddFlagFnPlugins(set *pflag.FlagSet) {
+	set.BoolVar(
+		&flagFnpLoadingOptionsValue.EnableContainers, flagEnableContainersName,
+		false, flagEnableContainersHelp)
+	set.BoolVar(
+		&flagFnpLoadingOptionsValue.EnableStar, flagEnableStarName,
+		false, flagEnableStarHelp)
+	set.BoolVar(
+		&flagFnpLoadingOptionsValue.Network, flagNetworkName,
+		false, flagNetworkHelp)
+	set.StringVar(
+		&flagFnpLoadingOptionsValue.NetworkName, flagNetworkNameName,
+		"bridge", flagNetworkNameHelp)
+	set.StringArrayVar(
+		&flagFnpLoadingOptionsValue.Mounts, flagMountName,
+		nil, flagMountHelp)
+}
+
+func getFlagFnpLoadingOptions() types.FnPluginLoadingOptions {
+	return flagFnpLoadingOptionsValue
+}
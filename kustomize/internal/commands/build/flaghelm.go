@@ -0,0 +1,37 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+const (
+	flagEnableHelmName = "enable-helm"
+	flagEnableHelmHelp = `inflate the charts named by the helmCharts field
+of kustomizations, by running helm as exec plugins are run.`
+	flagHelmCommandName = "helm-command"
+	flagHelmCommandHelp = `the helm binary to run.`
+)
+
+var (
+	flagHelmConfigValue types.HelmConfig
+)
+
+// AddFlagHelm adds the flags inflating helm charts
+// to the given set, so that commands building
+// kustomizations may share them with build.
+func AddFlagHelm(set *pflag.FlagSet) {
+	set.BoolVar(
+		&flagHelmConfigValue.Enabled, flagEnableHelmName,
+		false, flagEnableHelmHelp)
+	set.StringVar(
+		&flagHelmConfigValue.Command, flagHelmCommandName,
+		"helm", flagHelmCommandHelp)
+}
+
+func getFlagHelmConfig() types.HelmConfig {
+	return flagHelmConfigValue
+}
@@ -5,38 +5,96 @@ package build
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/krusty"
 	"sigs.k8s.io/kustomize/api/types"
 )
 
 const (
-	flagName = "load_restrictor"
+	flagName                 = "load_restrictor"
+	flagAllowedRootName      = "load_allowed_root"
+	flagAllowedRootsFileName = "load_allowed_roots_file"
 )
 
 var (
 	flagLrValue = types.LoadRestrictionsRootOnly.String()
 	flagLrHelp  = "if set to '" + types.LoadRestrictionsNone.String() +
 		"', local kustomizations may load files from outside their root. " +
+		"If set to '" + types.LoadRestrictionsAllowList.String() +
+		"', they may also load files from the allowed roots. " +
 		"This does, however, break the relocatability of the kustomization."
+	flagAllowedRootValue      []string
+	flagAllowedRootHelp       = "a directory from which files may be loaded"
+	flagAllowedRootsFileValue string
+	flagAllowedRootsFileHelp  = "a file listing directories from which " +
+		"files may be loaded, one per line; relative directories are " +
+		"relative to the file"
 )
 
-func addFlagLoadRestrictor(set *pflag.FlagSet) {
+// AddFlagLoadRestrictor adds the flags restricting the
+// loading of files to the given set, so that commands
+// building kustomizations may share them with build.
+func AddFlagLoadRestrictor(set *pflag.FlagSet) {
 	set.StringVar(
 		&flagLrValue, flagName,
 		types.LoadRestrictionsRootOnly.String(), flagLrHelp)
+	set.StringSliceVar(
+		&flagAllowedRootValue, flagAllowedRootName,
+		nil, flagAllowedRootHelp)
+	set.StringVar(
+		&flagAllowedRootsFileValue, flagAllowedRootsFileName,
+		"", flagAllowedRootsFileHelp)
 }
 
-func validateFlagLoadRestrictor() error {
+// ValidateFlagLoadRestrictor validates the
+// flags added by AddFlagLoadRestrictor.
+func ValidateFlagLoadRestrictor() error {
+	hasRoots := len(flagAllowedRootValue) > 0 ||
+		flagAllowedRootsFileValue != ""
 	switch getFlagLoadRestrictorValue() {
 	case types.LoadRestrictionsRootOnly, types.LoadRestrictionsNone:
+		if hasRoots {
+			return fmt.Errorf(
+				"flags --%s and --%s require --%s %s",
+				flagAllowedRootName, flagAllowedRootsFileName,
+				flagName, types.LoadRestrictionsAllowList)
+		}
+		return nil
+	case types.LoadRestrictionsAllowList:
+		if !hasRoots {
+			return fmt.Errorf(
+				"flag --%s %s requires --%s or --%s",
+				flagName, flagLrValue,
+				flagAllowedRootName, flagAllowedRootsFileName)
+		}
 		return nil
 	default:
 		return fmt.Errorf(
 			"illegal flag value --%s %s; legal values: %v",
 			flagName, flagLrValue,
-			[]string{types.LoadRestrictionsRootOnly.String(), types.LoadRestrictionsNone.String()})
+			[]string{types.LoadRestrictionsRootOnly.String(),
+				types.LoadRestrictionsNone.String(),
+				types.LoadRestrictionsAllowList.String()})
+	}
+}
+
+// SetFlagLoadRestrictorOptions sets the load
+// restrictions of the given options as the
+// flags added by AddFlagLoadRestrictor say.
+func SetFlagLoadRestrictorOptions(
+	fSys filesys.FileSystem, opts *krusty.Options) error {
+	roots, err := getFlagAllowedLoadRoots(fSys)
+	if err != nil {
+		return err
 	}
+	opts.LoadRestrictions = getFlagLoadRestrictorValue()
+	opts.AllowedLoadRoots = roots
+	return nil
 }
 
 func getFlagLoadRestrictorValue() types.LoadRestrictions {
@@ -45,7 +103,35 @@ func getFlagLoadRestrictorValue() types.LoadRestrictions {
 		return types.LoadRestrictionsRootOnly
 	case types.LoadRestrictionsNone.String(), "none":
 		return types.LoadRestrictionsNone
+	case types.LoadRestrictionsAllowList.String(), "allowList":
+		return types.LoadRestrictionsAllowList
 	default:
 		return types.LoadRestrictionsUnknown
 	}
 }
+
+// getFlagAllowedLoadRoots returns the allowed roots given
+// by flag, followed by those listed in the roots file.
+func getFlagAllowedLoadRoots(fSys filesys.FileSystem) ([]string, error) {
+	roots := append([]string{}, flagAllowedRootValue...)
+	if flagAllowedRootsFileValue == "" {
+		return roots, nil
+	}
+	content, err := fSys.ReadFile(flagAllowedRootsFileValue)
+	if err != nil {
+		return nil, errors.Wrapf(
+			err, "reading --%s", flagAllowedRootsFileName)
+	}
+	dir := filepath.Dir(flagAllowedRootsFileValue)
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !filepath.IsAbs(line) {
+			line = filepath.Join(dir, line)
+		}
+		roots = append(roots, line)
+	}
+	return roots, nil
+}
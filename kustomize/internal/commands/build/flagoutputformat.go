@@ -0,0 +1,126 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	flagOutputFormatName = "output-format"
+
+	// Multi-document YAML, one document per resource.
+	formatYaml = "yaml"
+	// A Kubernetes v1 List holding the resources, as YAML.
+	formatList = "list"
+	// A Kubernetes v1 List holding the resources, as JSON.
+	formatJson = "json"
+	// One JSON object per line, one line per resource.
+	formatNdJson = "ndjson"
+	// A KRM functions ResourceList holding the resources, as YAML.
+	formatResourceList = "resourcelist"
+)
+
+var (
+	outputFormats = []string{
+		formatYaml, formatList, formatJson, formatNdJson, formatResourceList}
+	flagOutputFormatValue = formatYaml
+	flagOutputFormatHelp  = fmt.Sprintf(
+		"The shape of the output, one of %v. 'list' and 'json' "+
+			"write a v1 List, 'ndjson' writes one resource per line, "+
+			"and 'resourcelist' writes a KRM functions ResourceList.",
+		outputFormats)
+)
+
+func addFlagOutputFormat(set *pflag.FlagSet) {
+	set.StringVar(
+		&flagOutputFormatValue, flagOutputFormatName,
+		formatYaml, flagOutputFormatHelp)
+}
+
+func validateFlagOutputFormat() error {
+	for _, f := range outputFormats {
+		if flagOutputFormatValue == f {
+			return nil
+		}
+	}
+	return fmt.Errorf(
+		"illegal flag value --%s %s; legal values: %v",
+		flagOutputFormatName, flagOutputFormatValue, outputFormats)
+}
+
+// outputFileExtension returns the extension of
+// files in the format named by the output format flag.
+func outputFileExtension() string {
+	switch flagOutputFormatValue {
+	case formatJson, formatNdJson:
+		return ".json"
+	default:
+		return ".yaml"
+	}
+}
+
+// encodeResources returns the resources of the given ResMap
+// in the format named by the output format flag.  YAML is
+// written by the ResMap itself, as Run's callers do.
+func encodeResources(m resmap.ResMap) ([]byte, error) {
+	resources := m.Resources()
+	items := make([]interface{}, len(resources))
+	for i, r := range resources {
+		items[i] = r.Map()
+	}
+	switch flagOutputFormatValue {
+	case formatList:
+		return yaml.Marshal(makeList("v1", "List", items))
+	case formatJson:
+		b, err := json.MarshalIndent(makeList("v1", "List", items), "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(b, '\n'), nil
+	case formatNdJson:
+		var buf bytes.Buffer
+		for _, item := range items {
+			b, err := json.Marshal(item)
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(b)
+			buf.WriteString("\n")
+		}
+		return buf.Bytes(), nil
+	case formatResourceList:
+		return yaml.Marshal(makeList(
+			"config.kubernetes.io/v1alpha1", "ResourceList", items))
+	default:
+		return m.AsYaml()
+	}
+}
+
+// resMapOf returns a ResMap of the given resources,
+// in order, e.g. to encode a part of a build's output.
+func resMapOf(resources []*resource.Resource) (resmap.ResMap, error) {
+	m := resmap.New()
+	for _, r := range resources {
+		if err := m.Append(r); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func makeList(apiVersion, kind string, items []interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"items":      items,
+	}
+}
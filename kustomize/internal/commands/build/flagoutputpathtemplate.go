@@ -0,0 +1,140 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+const (
+	flagOutputPathTemplateName = "output-path-template"
+	flagOutputPathTemplateHelp = `write the output to files in the directory given by --output,
+at the paths given by this Go template, e.g.
+'{{or .Namespace "_cluster"}}/{{lower .Kind}}.yaml'.  The template
+sees the Group, Version, Kind, Namespace and Name of each resource,
+and may use the function lower.  Resources given the same path are
+written to the same file, in the format given by --output-format.`
+)
+
+var (
+	flagOutputPathTemplateValue = ""
+)
+
+func addFlagOutputPathTemplate(set *pflag.FlagSet) {
+	set.StringVar(
+		&flagOutputPathTemplateValue, flagOutputPathTemplateName,
+		"", flagOutputPathTemplateHelp)
+}
+
+func isFlagOutputPathTemplateSet() bool {
+	return flagOutputPathTemplateValue != ""
+}
+
+func validateFlagOutputPathTemplate(outputPath string) error {
+	if !isFlagOutputPathTemplateSet() {
+		return nil
+	}
+	if outputPath == "" {
+		return fmt.Errorf(
+			"--%s requires an output directory", flagOutputPathTemplateName)
+	}
+	_, err := parseOutputPathTemplate()
+	return err
+}
+
+func parseOutputPathTemplate() (*template.Template, error) {
+	t, err := template.New(flagOutputPathTemplateName).
+		Funcs(template.FuncMap{"lower": strings.ToLower}).
+		Option("missingkey=error").
+		Parse(flagOutputPathTemplateValue)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"illegal flag value --%s: %v", flagOutputPathTemplateName, err)
+	}
+	return t, nil
+}
+
+// pathTemplateData is what the output path template sees.
+type pathTemplateData struct {
+	Group     string
+	Version   string
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// writeFilesByTemplate writes the resources to files under dir,
+// at paths given by the output path template.  Files are written
+// in the order their first resource appears in m.
+func writeFilesByTemplate(
+	fSys filesys.FileSystem, dir string, m resmap.ResMap) error {
+	t, err := parseOutputPathTemplate()
+	if err != nil {
+		return err
+	}
+	var paths []string
+	groups := make(map[string][]*resource.Resource)
+	for _, r := range m.Resources() {
+		p, err := executePathTemplate(t, r)
+		if err != nil {
+			return err
+		}
+		if _, ok := groups[p]; !ok {
+			paths = append(paths, p)
+		}
+		groups[p] = append(groups[p], r)
+	}
+	for _, p := range paths {
+		group, err := resMapOf(groups[p])
+		if err != nil {
+			return err
+		}
+		out, err := encodeResources(group)
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(dir, p)
+		err = fSys.MkdirAll(filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+		err = fSys.WriteFile(path, out)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func executePathTemplate(
+	t *template.Template, r *resource.Resource) (string, error) {
+	gvk := r.GetGvk()
+	var buf bytes.Buffer
+	err := t.Execute(&buf, pathTemplateData{
+		Group:     gvk.Group,
+		Version:   gvk.Version,
+		Kind:      gvk.Kind,
+		Namespace: r.GetNamespace(),
+		Name:      r.GetName(),
+	})
+	if err != nil {
+		return "", err
+	}
+	p := filepath.Clean(buf.String())
+	if p == "." || filepath.IsAbs(p) || strings.HasPrefix(p, "..") {
+		return "", fmt.Errorf(
+			"--%s gives path '%s' for %s, which isn't in the output directory",
+			flagOutputPathTemplateName, buf.String(), r.CurId())
+	}
+	return p, nil
+}
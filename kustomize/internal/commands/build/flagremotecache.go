@@ -0,0 +1,52 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	flagRemoteCacheDirName = "remote-cache-dir"
+	flagRemoteCacheDirHelp = `where to cache clones of remote bases, by repo URL
+and commit, e.g. ~/.cache/kustomize/git, where the cache
+command looks by default; if empty, the default, remote
+bases are cloned afresh for each build.`
+	flagOfflineName = "offline"
+	flagOfflineHelp = `only take remote bases from the cache, never
+fetching them.`
+)
+
+var (
+	flagRemoteCacheDirValue = ""
+	flagOfflineValue        = false
+)
+
+func addFlagRemoteCache(set *pflag.FlagSet) {
+	set.StringVar(
+		&flagRemoteCacheDirValue, flagRemoteCacheDirName,
+		"", flagRemoteCacheDirHelp)
+	set.BoolVar(
+		&flagOfflineValue, flagOfflineName,
+		false, flagOfflineHelp)
+}
+
+func validateFlagRemoteCache() error {
+	if flagOfflineValue && flagRemoteCacheDirValue == "" {
+		return fmt.Errorf(
+			"flag --%s requires a --%s",
+			flagOfflineName, flagRemoteCacheDirName)
+	}
+	return nil
+}
+
+func getFlagRemoteCacheDirValue() string {
+	return flagRemoteCacheDirValue
+}
+
+func isFlagOfflineSet() bool {
+	return flagOfflineValue
+}
@@ -0,0 +1,28 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+)
+
+const (
+	flagStrictName = "strict"
+	flagStrictHelp = `fail when a patch, replicas, images or vars entry
+matches no resources or fields, rather than ignoring it.`
+)
+
+var (
+	flagStrictValue = false
+)
+
+func addFlagStrict(set *pflag.FlagSet) {
+	set.BoolVar(
+		&flagStrictValue, flagStrictName,
+		false, flagStrictHelp)
+}
+
+func isFlagStrictSet() bool {
+	return flagStrictValue
+}
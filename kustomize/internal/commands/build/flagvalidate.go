@@ -0,0 +1,51 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+const (
+	flagValidateName = "validate"
+	flagValidateHelp = `check the output against the OpenAPI schemas of its resources,
+including those of CRDs listed in the crds field, reporting unknown
+fields, mistyped values and missing required fields.  One of
+'off', 'warn' (log problems) or 'error' (fail on problems).`
+)
+
+var (
+	flagValidateValue = "off"
+)
+
+func addFlagValidate(set *pflag.FlagSet) {
+	set.StringVar(
+		&flagValidateValue, flagValidateName, "off", flagValidateHelp)
+}
+
+func validateFlagValidate() error {
+	if getFlagValidateValue() == types.SchemaValidationUnknown {
+		return fmt.Errorf(
+			"illegal flag value --%s %s; legal values: %v",
+			flagValidateName, flagValidateValue,
+			[]string{"off", "warn", "error"})
+	}
+	return nil
+}
+
+func getFlagValidateValue() types.SchemaValidation {
+	switch flagValidateValue {
+	case "off", types.SchemaValidationOff.String():
+		return types.SchemaValidationOff
+	case "warn", types.SchemaValidationWarn.String():
+		return types.SchemaValidationWarn
+	case "error", types.SchemaValidationError.String():
+		return types.SchemaValidationError
+	default:
+		return types.SchemaValidationUnknown
+	}
+}
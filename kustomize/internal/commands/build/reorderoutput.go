@@ -7,6 +7,7 @@ import (
 	"fmt"
 
 	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/types"
 )
 
 //go:generate stringer -type=reorderOutput
@@ -16,35 +17,83 @@ const (
 	unspecified reorderOutput = iota
 	none
 	legacy
+	topological
 )
 
 const (
 	flagReorderOutputName = "reorder"
+	flagReorderFirstName  = "reorder-first"
+	flagReorderLastName   = "reorder-last"
 )
 
 var (
-	flagReorderOutputValue = legacy.String()
+	flagReorderOutputValue = ""
 	flagReorderOutputHelp  = "Reorder the resources just before output. " +
 		"Use '" + legacy.String() + "' to apply a legacy reordering (Namespaces first, Webhooks last, etc). " +
-		"Use '" + none.String() + "' to suppress a final reordering."
+		"Use '" + topological.String() + "' to put resources after those they refer to " +
+		"(ConfigMaps before the Deployments that mount them, CRDs before their resources, Webhooks last). " +
+		"Use '" + none.String() + "' to suppress a final reordering. " +
+		"If unspecified, the sortOptions of the kustomization apply, else '" + legacy.String() + "'."
+	flagReorderFirstValue []string
+	flagReorderFirstHelp  = "Kinds to put first, in this order, " +
+		"replacing the kind order of the legacy and topological reorderings."
+	flagReorderLastValue []string
+	flagReorderLastHelp  = "Kinds to put last, in this order, " +
+		"replacing the kind order of the legacy and topological reorderings."
 )
 
 func addFlagReorderOutput(set *pflag.FlagSet) {
 	set.StringVar(
 		&flagReorderOutputValue, flagReorderOutputName,
-		legacy.String(), flagReorderOutputHelp)
+		"", flagReorderOutputHelp)
+	set.StringSliceVar(
+		&flagReorderFirstValue, flagReorderFirstName,
+		nil, flagReorderFirstHelp)
+	set.StringSliceVar(
+		&flagReorderLastValue, flagReorderLastName,
+		nil, flagReorderLastHelp)
 }
 
 func validateFlagReorderOutput() (reorderOutput, error) {
 	switch flagReorderOutputValue {
+	case "":
+		return unspecified, nil
 	case none.String():
 		return none, nil
 	case legacy.String():
 		return legacy, nil
+	case topological.String():
+		return topological, nil
 	default:
 		return unspecified, fmt.Errorf(
 			"illegal flag value --%s %s; legal values: %v",
 			flagReorderOutputName, flagReorderOutputValue,
-			[]string{legacy.String(), none.String()})
+			[]string{legacy.String(), topological.String(), none.String()})
 	}
 }
+
+// getSortOptions returns the sort options given by the flags,
+// or nil if none were given, leaving the choice to the
+// kustomization.  Kinds given without a reordering
+// apply to the legacy reordering.
+func getSortOptions(r reorderOutput) *types.SortOptions {
+	var kinds *types.KindOrder
+	if len(flagReorderFirstValue) > 0 || len(flagReorderLastValue) > 0 {
+		kinds = &types.KindOrder{
+			First: flagReorderFirstValue,
+			Last:  flagReorderLastValue,
+		}
+	}
+	switch r {
+	case none:
+		return &types.SortOptions{Order: types.NoSortOrder}
+	case legacy:
+		return &types.SortOptions{Order: types.LegacySortOrder, KindOrder: kinds}
+	case topological:
+		return &types.SortOptions{Order: types.TopologicalSortOrder, KindOrder: kinds}
+	}
+	if kinds != nil {
+		return &types.SortOptions{Order: types.LegacySortOrder, KindOrder: kinds}
+	}
+	return nil
+}
@@ -11,11 +11,12 @@ func _() {
 	_ = x[unspecified-0]
 	_ = x[none-1]
 	_ = x[legacy-2]
+	_ = x[topological-3]
 }
 
-const _reorderOutput_name = "unspecifiednonelegacy"
+const _reorderOutput_name = "unspecifiednonelegacytopological"
 
-var _reorderOutput_index = [...]uint8{0, 11, 15, 21}
+var _reorderOutput_index = [...]uint8{0, 11, 15, 21, 32}
 
 func (i reorderOutput) String() string {
 	if i < 0 || i >= reorderOutput(len(_reorderOutput_index)-1) {
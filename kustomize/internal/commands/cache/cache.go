@@ -0,0 +1,114 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package cache holds the commands managing the cache
+// of remote bases used by build.
+package cache
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/gitcache"
+)
+
+type cacheOptions struct {
+	dir string
+}
+
+// NewCmdCache returns an instance of 'cache' subcommand.
+func NewCmdCache(out io.Writer) *cobra.Command {
+	var o cacheOptions
+	c := &cobra.Command{
+		Use:   "cache",
+		Short: "Manages the cache of remote bases",
+		Long: `Manages the cache of remote bases.
+
+Given a --remote-cache-dir, the build command keeps the clones
+of the git repos holding remote bases in a cache, by repo URL
+and commit, so that later builds needn't fetch them again, and
+can be run with --offline.
+`,
+		Example: `
+	# List the cached clones
+	kustomize cache list
+
+	# Remove the clones not used in the last week
+	kustomize cache prune --older-than 168h
+`,
+		Args: cobra.MinimumNArgs(1),
+	}
+	c.PersistentFlags().StringVar(
+		&o.dir, "remote-cache-dir", gitcache.DefaultDir(),
+		"the directory holding the cache")
+	c.AddCommand(
+		newCmdList(out, &o),
+		newCmdPrune(out, &o),
+	)
+	return c
+}
+
+func newCmdList(out io.Writer, o *cacheOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:     "list",
+		Short:   "Lists the cached clones of remote bases",
+		Example: `kustomize cache list`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunList(out, gitcache.New(o.dir))
+		},
+	}
+}
+
+// RunList lists the clones in the cache.
+func RunList(out io.Writer, cache *gitcache.Cache) error {
+	entries, err := cache.List()
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "URL\tCOMMIT\tLAST USED\tDIRECTORY")
+	for _, e := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+			e.URL, e.Commit, e.LastUsed.Format(time.RFC3339), e.Dir)
+	}
+	return w.Flush()
+}
+
+func newCmdPrune(out io.Writer, o *cacheOptions) *cobra.Command {
+	var olderThan time.Duration
+	c := &cobra.Command{
+		Use:   "prune",
+		Short: "Removes cached clones of remote bases",
+		Example: `
+	# Remove all the cached clones
+	kustomize cache prune
+
+	# Remove the clones not used in the last day
+	kustomize cache prune --older-than 24h
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var before time.Time
+			if olderThan > 0 {
+				before = time.Now().Add(-olderThan)
+			}
+			return RunPrune(out, gitcache.New(o.dir), before)
+		},
+	}
+	c.Flags().DurationVar(
+		&olderThan, "older-than", 0,
+		"only remove the clones not used for this long")
+	return c
+}
+
+// RunPrune removes the clones in the cache last used
+// before the given time, or all of them, if it's zero.
+func RunPrune(out io.Writer, cache *gitcache.Cache, before time.Time) error {
+	removed, err := cache.Prune(before)
+	for _, e := range removed {
+		fmt.Fprintf(out, "removed %s at %s\n", e.URL, e.Commit)
+	}
+	return err
+}
@@ -0,0 +1,67 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package cache_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kustomize/api/gitcache"
+	. "sigs.k8s.io/kustomize/kustomize/v3/internal/commands/cache"
+)
+
+const (
+	url    = "https://github.com/example/repo.git"
+	commit = "1111111111111111111111111111111111111111"
+)
+
+func TestListAndPrune(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kustomize-cache-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	c := gitcache.New(dir)
+	clone, err := c.TempDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := c.Add(url, commit, clone)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err = RunList(&out, c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 ||
+		!strings.HasPrefix(lines[0], "URL") ||
+		!strings.HasPrefix(lines[1], url+"  "+commit+"  ") ||
+		!strings.HasSuffix(lines[1], e.Dir) {
+		t.Fatalf("unexpected list:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err = RunPrune(&out, c, time.Now().Add(-time.Hour)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.String() != "" {
+		t.Fatalf("expected nothing pruned, got:\n%s", out.String())
+	}
+	if err = RunPrune(&out, c, time.Time{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.String() != "removed "+url+" at "+commit+"\n" {
+		t.Fatalf("unexpected prune output:\n%s", out.String())
+	}
+	if _, ok := c.Lookup(url, commit); ok {
+		t.Fatalf("expected the clone to be pruned")
+	}
+}
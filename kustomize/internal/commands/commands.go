@@ -16,9 +16,11 @@ import (
 	shell_complete "sigs.k8s.io/kustomize/cmd/config/complete"
 	"sigs.k8s.io/kustomize/cmd/kubectl/kubectlcobra"
 	"sigs.k8s.io/kustomize/kustomize/v3/internal/commands/build"
+	"sigs.k8s.io/kustomize/kustomize/v3/internal/commands/cache"
 	"sigs.k8s.io/kustomize/kustomize/v3/internal/commands/config"
 	"sigs.k8s.io/kustomize/kustomize/v3/internal/commands/create"
 	"sigs.k8s.io/kustomize/kustomize/v3/internal/commands/edit"
+	"sigs.k8s.io/kustomize/kustomize/v3/internal/commands/plugin"
 	"sigs.k8s.io/kustomize/kustomize/v3/internal/commands/status"
 	"sigs.k8s.io/kustomize/kustomize/v3/internal/commands/version"
 )
@@ -41,8 +43,10 @@ See https://sigs.k8s.io/kustomize
 	c.AddCommand(
 		shell_complete.NewCommand(),
 		build.NewCmdBuild(stdOut),
+		cache.NewCmdCache(stdOut),
 		edit.NewCmdEdit(fSys, v, uf),
 		create.NewCmdCreate(fSys, uf),
+		plugin.NewCmdPlugin(fSys, stdOut),
 		version.NewCmdVersion(stdOut),
 		status.NewCmdStatus(),
 	)
@@ -11,6 +11,7 @@ import (
 	"sigs.k8s.io/kustomize/api/loader"
 	"sigs.k8s.io/kustomize/kustomize/v3/internal/commands/edit/add"
 	"sigs.k8s.io/kustomize/kustomize/v3/internal/commands/edit/fix"
+	"sigs.k8s.io/kustomize/kustomize/v3/internal/commands/edit/lock"
 	"sigs.k8s.io/kustomize/kustomize/v3/internal/commands/edit/remove"
 	"sigs.k8s.io/kustomize/kustomize/v3/internal/commands/edit/set"
 )
@@ -42,6 +43,7 @@ func NewCmdEdit(
 			kf),
 		set.NewCmdSet(fSys, v),
 		fix.NewCmdFix(fSys),
+		lock.NewCmdLock(fSys),
 		remove.NewCmdRemove(fSys, v),
 	)
 	return c
@@ -4,40 +4,160 @@
 package fix
 
 import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kustomize/v3/internal/commands/kustfile"
 )
 
+type fixOptions struct {
+	dryRun bool
+}
+
 // NewCmdFix returns an instance of 'fix' subcommand.
 func NewCmdFix(fSys filesys.FileSystem) *cobra.Command {
+	var o fixOptions
 	cmd := &cobra.Command{
 		Use:   "fix",
-		Short: "Fix the missing fields in kustomization file",
-		Long:  "",
+		Short: "Fix the missing and deprecated fields in kustomization file",
+		Long: `Fix the missing and deprecated fields in kustomization file.
+
+The deprecated fields are rewritten into their modern equivalents:
+  bases                  into resources
+  commonLabels           into labels
+  patchesStrategicMerge  into patches
+  patchesJson6902        into patches
+  vars                   into replacements
+and the apiVersion is set to the latest.  Parts of a field that
+can't be rewritten without changing the output of the build are
+left in place, with a warning: vars naming names, namespaces or
+resources not in the resource files, which may change after
+replacements run, and patchesJson6902 entries changing fields
+that the namespace, namePrefix, nameSuffix, labels or
+commonAnnotations of the kustomization set before them.
+`,
 		Example: `
 	# Fix the missing and deprecated fields in kustomization file
 	kustomize edit fix
 
+	# Show the changes fix would make, without making them
+	kustomize edit fix --dry-run
 `,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return RunFix(fSys)
+			return RunFix(fSys, o.dryRun, cmd.OutOrStdout(), cmd.ErrOrStderr())
 		},
 	}
+	cmd.Flags().BoolVar(
+		&o.dryRun, "dry-run", false,
+		"print a diff of the changes, rather than writing them")
 	return cmd
 }
 
-// RunFix runs `fix` command
-func RunFix(fSys filesys.FileSystem) error {
+// RunFix runs `fix` command.  Warnings about fields that
+// can't be fixed go to errOut.  When dryRun is true, the
+// kustomization file is left alone, and a diff of the
+// changes goes to out.
+func RunFix(
+	fSys filesys.FileSystem, dryRun bool, out, errOut io.Writer) error {
 	mf, err := kustfile.NewKustomizationFile(fSys)
 	if err != nil {
 		return err
 	}
 
+	original, err := fSys.ReadFile(mf.Path())
+	if err != nil {
+		return err
+	}
+
 	m, err := mf.Read()
 	if err != nil {
 		return err
 	}
 
-	return mf.Write(m)
+	apiVersion := m.APIVersion
+	for _, mig := range migrations {
+		if !mig.applies(apiVersion) {
+			continue
+		}
+		warnings, err := mig.migrate(fSys, m)
+		if err != nil {
+			return err
+		}
+		for _, w := range warnings {
+			fmt.Fprintln(errOut, "warning: "+w)
+		}
+		if isEmptyField(m, mig.from) {
+			mf.MoveFieldComments(mig.from, mig.to)
+		}
+	}
+	if m.Kind == types.KustomizationKind {
+		m.APIVersion = kustomizationVersions[len(kustomizationVersions)-1]
+	}
+
+	if !dryRun {
+		return mf.Write(m)
+	}
+	fixed, err := mf.Marshal(m)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(
+		out, lineDiff(mf.Path(), string(original), string(fixed)))
+	return err
+}
+
+// isEmptyField returns true if the kustomization
+// field with the given name holds nothing.
+func isEmptyField(k *types.Kustomization, name string) bool {
+	switch name {
+	case "Bases":
+		return len(k.Bases) == 0
+	case "CommonLabels":
+		return len(k.CommonLabels) == 0
+	case "PatchesStrategicMerge":
+		return len(k.PatchesStrategicMerge) == 0
+	case "PatchesJson6902":
+		return len(k.PatchesJson6902) == 0
+	case "Vars":
+		return len(k.Vars) == 0
+	}
+	return false
+}
+
+// lineDiff returns a diff of the lines of the original and
+// fixed content of the named file, or nothing if they're
+// the same.
+func lineDiff(name, original, fixed string) string {
+	if original == fixed {
+		return ""
+	}
+	dmp := diffmatchpatch.New()
+	a, b, lines := dmp.DiffLinesToChars(original, fixed)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMain(a, b, false), lines)
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", name, name)
+	for _, d := range diffs {
+		prefix := " "
+		switch d.Type {
+		case diffmatchpatch.DiffDelete:
+			prefix = "-"
+		case diffmatchpatch.DiffInsert:
+			prefix = "+"
+		}
+		for _, line := range strings.SplitAfter(d.Text, "\n") {
+			if line == "" {
+				continue
+			}
+			sb.WriteString(prefix + line)
+			if !strings.HasSuffix(line, "\n") {
+				sb.WriteString("\n")
+			}
+		}
+	}
+	return sb.String()
 }
@@ -4,10 +4,12 @@
 package fix
 
 import (
+	"bytes"
 	"strings"
 	"testing"
 
 	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/krusty"
 	testutils_test "sigs.k8s.io/kustomize/kustomize/v3/internal/commands/testutils"
 )
 
@@ -31,3 +33,430 @@ func TestFix(t *testing.T) {
 		t.Errorf("expected kind in kustomization")
 	}
 }
+
+func TestFixDeprecatedFields(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	testutils_test.WriteTestKustomizationWith(fSys, []byte(`
+resources:
+- deployment.yaml
+- service.yaml
+# the labels of the app
+commonLabels:
+  app: web
+# patches to apply
+patchesStrategicMerge:
+- patch.yaml
+- |-
+  apiVersion: v1
+  kind: Service
+  metadata:
+    name: web
+  spec:
+    type: NodePort
+patchesJson6902:
+- target:
+    group: apps
+    version: v1
+    kind: Deployment
+    name: web
+  path: json-patch.yaml
+vars:
+- name: PORT
+  objref:
+    apiVersion: v1
+    kind: Service
+    name: web
+  fieldref:
+    fieldpath: spec.ports[0].name
+`))
+	fSys.WriteFile("deployment.yaml", []byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        image: web
+        env:
+        - name: PORT
+          value: $(PORT)
+`))
+	fSys.WriteFile("service.yaml", []byte(`
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  ports:
+  - name: http
+    port: 80
+`))
+	fSys.WriteFile("json-patch.yaml", []byte(`
+- op: replace
+  path: /spec/replicas
+  value: 3
+`))
+	fSys.WriteFile("patch.yaml", []byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 2
+`))
+
+	cmd := NewCmdFix(fSys)
+	err := cmd.RunE(cmd, nil)
+	if err != nil {
+		t.Fatalf("unexpected cmd error: %v", err)
+	}
+	content, err := testutils_test.ReadTestKustomization(fSys)
+	if err != nil {
+		t.Fatalf("unexpected read error: %v", err)
+	}
+	expected := `
+resources:
+- deployment.yaml
+- service.yaml
+# the labels of the app
+labels:
+- includeSelectors: true
+  pairs:
+    app: web
+# patches to apply
+patches:
+- path: patch.yaml
+- patch: |-
+    apiVersion: v1
+    kind: Service
+    metadata:
+      name: web
+    spec:
+      type: NodePort
+- path: json-patch.yaml
+  target:
+    group: apps
+    kind: Deployment
+    name: web
+    version: v1
+replacements:
+- source:
+    fieldref: spec.ports.0.name
+    objref:
+      kind: Service
+      name: web
+      version: v1
+  target:
+    fieldrefs:
+    - spec.template.spec.containers[name=web].env[name=PORT].value
+    objref:
+      group: apps
+      kind: Deployment
+      name: web
+      version: v1
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+`
+	if string(content) != expected {
+		t.Fatalf("expected:\n%s\nactual:\n%s", expected, string(content))
+	}
+}
+
+func TestFixVarsLeftInPlace(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	testutils_test.WriteTestKustomizationWith(fSys, []byte(`
+resources:
+- deployment.yaml
+- ../base
+vars:
+- name: SERVICE
+  objref:
+    apiVersion: v1
+    kind: Service
+    name: web
+- name: UNUSED
+  objref:
+    apiVersion: v1
+    kind: Service
+    name: web
+`))
+	fSys.WriteFile("deployment.yaml", []byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        args:
+        - --service=$(SERVICE)
+`))
+
+	var errOut bytes.Buffer
+	err := RunFix(fSys, false, &bytes.Buffer{}, &errOut)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	content, err := testutils_test.ReadTestKustomization(fSys)
+	if err != nil {
+		t.Fatalf("unexpected read error: %v", err)
+	}
+	if !strings.Contains(string(content), "name: SERVICE") ||
+		!strings.Contains(string(content), "name: UNUSED") {
+		t.Fatalf("expected vars to be left in place:\n%s", string(content))
+	}
+	if strings.Contains(string(content), "replacements:") {
+		t.Fatalf("unexpected replacements:\n%s", string(content))
+	}
+	expected := `warning: vars: $(SERVICE) is used within a larger string, so var SERVICE is left in vars
+warning: vars: $(UNUSED) isn't used in the resources of this kustomization, so var UNUSED is left in vars
+`
+	if errOut.String() != expected {
+		t.Fatalf("expected:\n%s\nactual:\n%s", expected, errOut.String())
+	}
+}
+
+func TestFixDryRun(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	original := []byte(`
+# keep me
+commonLabels:
+  app: web
+`)
+	testutils_test.WriteTestKustomizationWith(fSys, original)
+
+	var out bytes.Buffer
+	err := RunFix(fSys, true, &out, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	content, err := testutils_test.ReadTestKustomization(fSys)
+	if err != nil {
+		t.Fatalf("unexpected read error: %v", err)
+	}
+	if string(content) != string(original) {
+		t.Fatalf("dry run changed the kustomization:\n%s", string(content))
+	}
+	expected := `--- kustomization.yaml
++++ kustomization.yaml
+ 
+ # keep me
+-commonLabels:
+-  app: web
++labels:
++- includeSelectors: true
++  pairs:
++    app: web
++apiVersion: kustomize.config.k8s.io/v1beta1
++kind: Kustomization
+`
+	if out.String() != expected {
+		t.Fatalf("expected:\n%s\nactual:\n%s", expected, out.String())
+	}
+}
+
+const fixDeployment = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - name: web
+        image: web
+        env:
+        - name: CONFIG
+          value: $(CONFIG)
+        - name: PORT
+          value: $(PORT)
+`
+
+const fixService = `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  ports:
+  - name: http
+    port: 80
+`
+
+// Fixing a kustomization doesn't change what it builds.
+func TestFixKeepsBuildOutput(t *testing.T) {
+	testCases := map[string]struct {
+		kustomization string
+		// Strings the fixed kustomization holds.
+		fixed []string
+		// Warnings about what's left in place.
+		warnings []string
+	}{
+		"vars": {
+			kustomization: `
+namePrefix: p-
+resources:
+- deployment.yaml
+- service.yaml
+configMapGenerator:
+- name: cm
+  literals:
+  - a=b
+vars:
+- name: CONFIG
+  objref:
+    apiVersion: v1
+    kind: ConfigMap
+    name: cm
+- name: PORT
+  objref:
+    apiVersion: v1
+    kind: Service
+    name: web
+  fieldref:
+    fieldpath: spec.ports[0].name
+`,
+			fixed: []string{"replacements:", "name: CONFIG"},
+			warnings: []string{
+				"var CONFIG refers to metadata.name, which may change " +
+					"after replacements run, so var CONFIG is left in vars",
+			},
+		},
+		"vars of generated resources": {
+			kustomization: `
+resources:
+- deployment.yaml
+configMapGenerator:
+- name: cm
+  literals:
+  - a=b
+vars:
+- name: CONFIG
+  objref:
+    apiVersion: v1
+    kind: ConfigMap
+    name: cm
+  fieldref:
+    fieldpath: data.a
+- name: PORT
+  objref:
+    apiVersion: v1
+    kind: ConfigMap
+    name: cm
+  fieldref:
+    fieldpath: data.a
+`,
+			fixed: []string{"name: CONFIG", "name: PORT"},
+			warnings: []string{
+				"the source of var CONFIG isn't in the resources",
+				"the source of var PORT isn't in the resources",
+			},
+		},
+		"patchesJson6902": {
+			kustomization: `
+namespace: prod
+namePrefix: p-
+commonLabels:
+  app: web
+resources:
+- service.yaml
+patchesJson6902:
+- target:
+    version: v1
+    kind: Service
+    name: web
+  patch: |-
+    - op: replace
+      path: /metadata/labels
+      value:
+        app: api
+- target:
+    version: v1
+    kind: Service
+    name: web
+  patch: |-
+    - op: add
+      path: /metadata/namespace
+      value: staging
+- target:
+    version: v1
+    kind: Service
+    name: web
+  patch: |-
+    - op: copy
+      from: /metadata/name
+      path: /spec/ports/0/name
+- target:
+    version: v1
+    kind: Service
+    name: web
+  patch: |-
+    - op: add
+      path: /spec/type
+      value: NodePort
+`,
+			fixed: []string{"patchesJson6902:", "patches:", "value: NodePort"},
+			warnings: []string{
+				"the patch of web changes metadata/labels",
+				"the patch of web changes metadata/namespace",
+				"the patch of web changes metadata/name",
+			},
+		},
+	}
+	for name, tc := range testCases {
+		fSys := filesys.MakeFsInMemory()
+		testutils_test.WriteTestKustomizationWith(fSys, []byte(tc.kustomization))
+		fSys.WriteFile("deployment.yaml", []byte(fixDeployment))
+		fSys.WriteFile("service.yaml", []byte(fixService))
+		before := buildYaml(t, fSys)
+
+		var errOut bytes.Buffer
+		err := RunFix(fSys, false, &bytes.Buffer{}, &errOut)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if after := buildYaml(t, fSys); after != before {
+			t.Errorf("%s: fix changed the output from:\n%s\nto:\n%s",
+				name, before, after)
+		}
+		content, err := testutils_test.ReadTestKustomization(fSys)
+		if err != nil {
+			t.Fatalf("%s: unexpected read error: %v", name, err)
+		}
+		for _, f := range tc.fixed {
+			if !strings.Contains(string(content), f) {
+				t.Errorf("%s: expected '%s' in:\n%s", name, f, content)
+			}
+		}
+		if n := strings.Count(errOut.String(), "warning: "); n != len(tc.warnings) {
+			t.Errorf("%s: expected %d warnings, got:\n%s",
+				name, len(tc.warnings), errOut.String())
+		}
+		for _, w := range tc.warnings {
+			if !strings.Contains(errOut.String(), w) {
+				t.Errorf("%s: expected warning '%s', got:\n%s",
+					name, w, errOut.String())
+			}
+		}
+	}
+}
+
+func buildYaml(t *testing.T, fSys filesys.FileSystem) string {
+	m, err := krusty.MakeKustomizer(
+		fSys, krusty.MakeDefaultOptions()).Run(".")
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	yml, err := m.AsYaml()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(yml)
+}
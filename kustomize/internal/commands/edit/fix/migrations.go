@@ -0,0 +1,323 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package fix
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/konfig/builtinpluginconsts"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+)
+
+// A migration rewrites a deprecated kustomization
+// field into its modern equivalent.
+type migration struct {
+	// apiVersion is the kustomization apiVersion the
+	// migration brings a kustomization up to.  The
+	// migration only runs on kustomizations whose
+	// apiVersion is no newer than this.
+	apiVersion string
+
+	// from is the deprecated field, and to the
+	// field it's rewritten into.  They're named as in
+	// the Kustomization type, so that their comments
+	// can follow the rewrite.
+	from, to string
+
+	// migrate rewrites the field, returning a warning
+	// for each part of it that can't be rewritten,
+	// which is left in place.
+	migrate func(
+		fSys filesys.FileSystem, k *types.Kustomization) ([]string, error)
+}
+
+// kustomizationVersions lists the apiVersions of
+// the Kustomization kind, oldest first.
+var kustomizationVersions = []string{
+	types.KustomizationVersion,
+}
+
+// migrations lists all migrations, in the order
+// in which they run.
+var migrations = []migration{
+	{
+		apiVersion: types.KustomizationVersion,
+		from:       "Bases",
+		to:         "Resources",
+		// Reading the kustomization file already
+		// moves bases into resources.
+		migrate: func(
+			filesys.FileSystem, *types.Kustomization) ([]string, error) {
+			return nil, nil
+		},
+	},
+	{
+		apiVersion: types.KustomizationVersion,
+		from:       "CommonLabels",
+		to:         "Labels",
+		migrate:    migrateCommonLabels,
+	},
+	{
+		apiVersion: types.KustomizationVersion,
+		from:       "PatchesStrategicMerge",
+		to:         "Patches",
+		migrate:    migratePatchesStrategicMerge,
+	},
+	{
+		apiVersion: types.KustomizationVersion,
+		from:       "PatchesJson6902",
+		to:         "Patches",
+		migrate:    migratePatchesJson6902,
+	},
+	{
+		apiVersion: types.KustomizationVersion,
+		from:       "Vars",
+		to:         "Replacements",
+		migrate:    migrateVars,
+	},
+}
+
+// versionIndex returns the index of the given apiVersion
+// in kustomizationVersions, or -1 if it isn't there.
+func versionIndex(apiVersion string) int {
+	for i, v := range kustomizationVersions {
+		if v == apiVersion {
+			return i
+		}
+	}
+	return -1
+}
+
+// applies returns true if the migration
+// should run on a kustomization of the
+// given apiVersion.
+func (m *migration) applies(apiVersion string) bool {
+	return versionIndex(apiVersion) <= versionIndex(m.apiVersion)
+}
+
+// migrateCommonLabels rewrites commonLabels into an
+// entry of labels that, like commonLabels, also
+// applies to selectors and templates.  The entry comes
+// first, as commonLabels are applied before labels.
+func migrateCommonLabels(
+	_ filesys.FileSystem, k *types.Kustomization) ([]string, error) {
+	if len(k.CommonLabels) == 0 {
+		return nil, nil
+	}
+	k.Labels = append([]types.Label{{
+		Pairs:            k.CommonLabels,
+		IncludeSelectors: true,
+	}}, k.Labels...)
+	k.CommonLabels = nil
+	return nil, nil
+}
+
+// migratePatchesStrategicMerge rewrites the entries of
+// patchesStrategicMerge into entries of patches, which come
+// first, as strategic merge patches are applied before
+// patches.  A file holding more than one patch is left
+// in place, as an entry of patches holds only one.
+func migratePatchesStrategicMerge(
+	fSys filesys.FileSystem, k *types.Kustomization) ([]string, error) {
+	var patches []types.Patch
+	var kept []types.PatchStrategicMerge
+	var warnings []string
+	for _, psm := range k.PatchesStrategicMerge {
+		s := string(psm)
+		if isInlinePatch(s) {
+			patches = append(patches, types.Patch{Patch: s})
+			continue
+		}
+		content, err := fSys.ReadFile(s)
+		if err != nil {
+			return nil, err
+		}
+		docs, err := splitDocuments(content)
+		if err != nil {
+			return nil, err
+		}
+		if len(docs) > 1 {
+			kept = append(kept, psm)
+			warnings = append(warnings, fmt.Sprintf(
+				"patchesStrategicMerge: '%s' holds %d patches, "+
+					"so it's left in patchesStrategicMerge", s, len(docs)))
+			continue
+		}
+		patches = append(patches, types.Patch{Path: s})
+	}
+	k.Patches = append(patches, k.Patches...)
+	k.PatchesStrategicMerge = kept
+	return warnings, nil
+}
+
+// isInlinePatch returns true if the entry of
+// patchesStrategicMerge is a patch, rather
+// than the path of a file holding one.
+func isInlinePatch(s string) bool {
+	var m map[string]interface{}
+	return yaml.Unmarshal([]byte(s), &m) == nil && len(m) > 0
+}
+
+// migratePatchesJson6902 rewrites the entries of
+// patchesJson6902 into entries of patches.  Patches are
+// applied before the namespace, name prefix and suffix,
+// labels and annotations of the kustomization, while
+// patchesJson6902 are applied after them, so an entry whose
+// operations touch the fields those set is left in place.
+func migratePatchesJson6902(
+	fSys filesys.FileSystem, k *types.Kustomization) ([]string, error) {
+	fss, err := patchedAfterFieldSpecs(k)
+	if err != nil {
+		return nil, err
+	}
+	var kept []types.PatchJson6902
+	var warnings []string
+	for _, p := range k.PatchesJson6902 {
+		field, err := fieldSetBefore(fSys, p, fss)
+		if err != nil {
+			return nil, err
+		}
+		if field != "" {
+			kept = append(kept, p)
+			warnings = append(warnings, fmt.Sprintf(
+				"patchesJson6902: the patch of %s changes %s, which "+
+					"this kustomization sets before patchesJson6902 "+
+					"are applied, but after patches are, so it's left "+
+					"in patchesJson6902", p.Target.Name, field))
+			continue
+		}
+		patch := types.Patch{Path: p.Path, Patch: p.Patch}
+		if p.Target != nil {
+			patch.Target = &types.Selector{
+				Gvk:       p.Target.Gvk,
+				Namespace: p.Target.Namespace,
+				Name:      p.Target.Name,
+			}
+		}
+		k.Patches = append(k.Patches, patch)
+	}
+	k.PatchesJson6902 = kept
+	return warnings, nil
+}
+
+// patchedAfterFieldSpecs returns the fields the
+// kustomization sets after patches are applied, but
+// before patchesJson6902 are.
+func patchedAfterFieldSpecs(k *types.Kustomization) ([]types.FieldSpec, error) {
+	var names []string
+	if k.Namespace != "" {
+		names = append(names, "namespace")
+	}
+	if k.NamePrefix != "" || k.NameSuffix != "" {
+		names = append(names, "namePrefix")
+	}
+	if len(k.CommonLabels) > 0 || len(k.Labels) > 0 {
+		names = append(names, "commonLabels")
+	}
+	if len(k.CommonAnnotations) > 0 {
+		names = append(names, "commonAnnotations")
+	}
+	defaults := builtinpluginconsts.GetDefaultFieldSpecsAsMap()
+	var result []types.FieldSpec
+	for _, name := range names {
+		var c map[string][]types.FieldSpec
+		err := yaml.Unmarshal(
+			[]byte(defaults[strings.ToLower(name)]), &c)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, c[name]...)
+	}
+	return result, nil
+}
+
+// fieldSetBefore returns the path of the first field of the
+// given fields that an operation of the JSON patch changes,
+// or reads, in its target, or "" if there's none.
+func fieldSetBefore(fSys filesys.FileSystem,
+	p types.PatchJson6902, fss []types.FieldSpec) (string, error) {
+	if len(fss) == 0 || p.Target == nil {
+		return "", nil
+	}
+	content := []byte(p.Patch)
+	if p.Path != "" {
+		var err error
+		content, err = fSys.ReadFile(p.Path)
+		if err != nil {
+			return "", err
+		}
+	}
+	var ops []struct {
+		Path string `json:"path"`
+		From string `json:"from"`
+	}
+	err := yaml.Unmarshal(content, &ops)
+	if err != nil {
+		return "", err
+	}
+	for _, op := range ops {
+		for _, pointer := range []string{op.Path, op.From} {
+			if pointer == "" {
+				continue
+			}
+			for _, fs := range fss {
+				if p.Target.Gvk.IsSelected(&fs.Gvk) &&
+					overlaps(pointerSegments(pointer), fieldSpecSegments(fs.Path)) {
+					return fs.Path, nil
+				}
+			}
+		}
+	}
+	return "", nil
+}
+
+// pointerSegments returns the field names of the given
+// JSON pointer, without the indices of list elements.
+func pointerSegments(pointer string) []string {
+	var result []string
+	for _, s := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		if s == "-" || isIndex(s) {
+			continue
+		}
+		s = strings.ReplaceAll(s, "~1", "/")
+		result = append(result, strings.ReplaceAll(s, "~0", "~"))
+	}
+	return result
+}
+
+func isIndex(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// fieldSpecSegments returns the field names
+// of the given field spec path.
+func fieldSpecSegments(path string) []string {
+	var result []string
+	for _, s := range strings.Split(path, "/") {
+		result = append(result, strings.TrimSuffix(s, "[]"))
+	}
+	return result
+}
+
+// overlaps returns true if either path is the other,
+// or holds it.
+func overlaps(a, b []string) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,267 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package fix
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/resid"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+)
+
+// varTarget is a resource, and the paths of
+// its fields, holding a reference to a var.
+type varTarget struct {
+	selector types.Selector
+	paths    []string
+}
+
+// varUse records where a var is referred to.
+type varUse struct {
+	targets []*varTarget
+
+	// embedded is true if the var is referred
+	// to within a larger string, which a
+	// replacement can't do.
+	embedded bool
+}
+
+// migrateVars rewrites vars into replacements.  A var is
+// referred to as $(NAME) in the fields of resources, while a
+// replacement names those fields, so the resource files of
+// the kustomization are searched for references to each var.
+// A var referred to within a larger string, or not referred
+// to in those files at all, e.g. because it's only used in a
+// base, is left in place.
+//
+// Vars are resolved at the end of the build, while the
+// replacements of a kustomization run along with its other
+// transformers, before names are suffixed with hashes, and
+// before the kustomizations including it transform its
+// resources.  So a var whose value may change in between, as
+// names and namespaces do, is left in place too, as is one
+// whose source isn't in the resource files, e.g. because it's
+// generated.
+func migrateVars(
+	fSys filesys.FileSystem, k *types.Kustomization) ([]string, error) {
+	if len(k.Vars) == 0 {
+		return nil, nil
+	}
+	uses := make(map[string]*varUse)
+	for _, v := range k.Vars {
+		uses[v.Name] = &varUse{}
+	}
+	var sources []resid.ResId
+	for _, path := range k.Resources {
+		if !fSys.Exists(path) || fSys.IsDir(path) {
+			continue
+		}
+		content, err := fSys.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		docs, err := splitDocuments(content)
+		if err != nil {
+			return nil, err
+		}
+		for _, doc := range docs {
+			findVarUses(doc, uses)
+			sources = append(sources, docId(doc))
+		}
+	}
+	var kept []types.Var
+	var warnings []string
+	for _, v := range k.Vars {
+		use := uses[v.Name]
+		switch {
+		case use.embedded:
+			warnings = append(warnings, fmt.Sprintf(
+				"vars: $(%s) is used within a larger string, "+
+					"so var %s is left in vars", v.Name, v.Name))
+		case len(use.targets) == 0:
+			warnings = append(warnings, fmt.Sprintf(
+				"vars: $(%s) isn't used in the resources of this "+
+					"kustomization, so var %s is left in vars", v.Name, v.Name))
+		case isRenamedField(v.FieldRef.FieldPath):
+			warnings = append(warnings, fmt.Sprintf(
+				"vars: var %s refers to %s, which may change after "+
+					"replacements run, so var %s is left in vars",
+				v.Name, varFieldPath(v), v.Name))
+		case !hasSource(sources, v.ObjRef):
+			warnings = append(warnings, fmt.Sprintf(
+				"vars: the source of var %s isn't in the resources of "+
+					"this kustomization, so var %s is left in vars", v.Name, v.Name))
+		default:
+			k.Replacements = append(
+				k.Replacements, varReplacements(v, use)...)
+			continue
+		}
+		kept = append(kept, v)
+	}
+	k.Vars = kept
+	return warnings, nil
+}
+
+// varReplacements returns a replacement for each resource
+// referring to the var, copying the value of the var's field.
+func varReplacements(v types.Var, use *varUse) []types.Replacement {
+	objRef := v.ObjRef
+	objRef.Gvk = objRef.GVK()
+	objRef.APIVersion = ""
+	var result []types.Replacement
+	for _, t := range use.targets {
+		source := &types.ReplSource{ObjRef: &objRef}
+		if v.FieldRef.FieldPath != "" {
+			source.FieldRef = toReplacementPath(v.FieldRef.FieldPath)
+		}
+		selector := t.selector
+		result = append(result, types.Replacement{
+			Source: source,
+			Target: &types.ReplTarget{
+				ObjRef:    &selector,
+				FieldRefs: t.paths,
+			},
+		})
+	}
+	return result
+}
+
+// isRenamedField returns true if the field at the given
+// path of a var is one kustomize renames, i.e. the name or
+// the namespace, the former being the default.
+func isRenamedField(p string) bool {
+	return p == "" || p == "metadata.name" || p == "metadata.namespace"
+}
+
+// varFieldPath returns the field path of the var,
+// or its default if it has none.
+func varFieldPath(v types.Var) string {
+	v.Defaulting()
+	return v.FieldRef.FieldPath
+}
+
+// hasSource returns true if one of the given
+// resources is the one the var's objref names.
+func hasSource(sources []resid.ResId, objRef types.Target) bool {
+	gvk := objRef.GVK()
+	for _, id := range sources {
+		if id.Name == objRef.Name && id.Gvk.IsSelected(&gvk) {
+			return true
+		}
+	}
+	return false
+}
+
+// docId returns the id of the given resource.
+func docId(doc map[string]interface{}) resid.ResId {
+	apiVersion, _ := doc["apiVersion"].(string)
+	kind, _ := doc["kind"].(string)
+	meta, _ := doc["metadata"].(map[string]interface{})
+	name, _ := meta["name"].(string)
+	namespace, _ := meta["namespace"].(string)
+	ref := types.Target{APIVersion: apiVersion, Gvk: resid.Gvk{Kind: kind}}
+	return resid.NewResIdWithNamespace(ref.GVK(), name, namespace)
+}
+
+var listIndex = regexp.MustCompile(`\[([0-9]+)\]`)
+
+// toReplacementPath converts the list indices of a var's field
+// path, e.g. spec.ports[0].port, into the path segments of a
+// replacement, e.g. spec.ports.0.port.
+func toReplacementPath(p string) string {
+	return listIndex.ReplaceAllString(p, ".$1")
+}
+
+var varReference = regexp.MustCompile(`\$\(([^)]+)\)`)
+
+// findVarUses records, for each var referred to in the
+// given resource, where the resource refers to it.
+func findVarUses(doc map[string]interface{}, uses map[string]*varUse) {
+	id := docId(doc)
+	selector := types.Selector{
+		Gvk:       id.Gvk,
+		Namespace: id.Namespace,
+		Name:      id.Name,
+	}
+	targets := make(map[string]*varTarget)
+	walkStrings(doc, nil, func(path []string, s string) {
+		for _, match := range varReference.FindAllStringSubmatch(s, -1) {
+			use, ok := uses[match[1]]
+			if !ok {
+				continue
+			}
+			if match[0] != s {
+				use.embedded = true
+				continue
+			}
+			t, ok := targets[match[1]]
+			if !ok {
+				t = &varTarget{selector: selector}
+				targets[match[1]] = t
+				use.targets = append(use.targets, t)
+			}
+			t.paths = append(t.paths, strings.Join(path, "."))
+		}
+	})
+}
+
+// walkStrings calls f with each string in node, along with
+// its path.  Elements of lists are selected by their name
+// field, if they have one, else by their index.
+func walkStrings(
+	node interface{}, path []string, f func([]string, string)) {
+	switch n := node.(type) {
+	case string:
+		f(path, n)
+	case map[string]interface{}:
+		var keys []string
+		for k := range n {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			walkStrings(n[k], append(path, k), f)
+		}
+	case []interface{}:
+		last := path[len(path)-1]
+		for i, e := range n {
+			segment := last + "." + strconv.Itoa(i)
+			if m, ok := e.(map[string]interface{}); ok {
+				if name, ok := m["name"].(string); ok {
+					segment = last + "[name=" + name + "]"
+				}
+			}
+			walkStrings(
+				e, append(append([]string{}, path[:len(path)-1]...), segment), f)
+		}
+	}
+}
+
+var documentSeparator = regexp.MustCompile(`(?m)^---\s*$`)
+
+// splitDocuments returns the non-empty YAML
+// documents in the given content.
+func splitDocuments(content []byte) ([]map[string]interface{}, error) {
+	var result []map[string]interface{}
+	for _, doc := range documentSeparator.Split(string(content), -1) {
+		if strings.TrimSpace(doc) == "" {
+			continue
+		}
+		var m map[string]interface{}
+		err := yaml.Unmarshal([]byte(doc), &m)
+		if err != nil {
+			return nil, err
+		}
+		if len(m) > 0 {
+			result = append(result, m)
+		}
+	}
+	return result, nil
+}
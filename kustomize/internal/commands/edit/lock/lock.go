@@ -0,0 +1,98 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package lock
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/loader"
+	"sigs.k8s.io/kustomize/kustomize/v3/internal/commands/build"
+)
+
+type lockOptions struct {
+	remoteCacheDir string
+	enablePlugins  bool
+}
+
+// NewCmdLock returns an instance of 'lock' subcommand.
+func NewCmdLock(fSys filesys.FileSystem) *cobra.Command {
+	var o lockOptions
+	cmd := &cobra.Command{
+		Use:   "lock",
+		Short: "Records the remote roots of the kustomization in " + konfig.KustomizationLockFileName,
+		Long: `Records the remote roots of the kustomization, e.g. remote
+bases, in ` + konfig.KustomizationLockFileName + `, beside the kustomization file.
+
+For each remote root, loaded directly or via other kustomizations,
+the lock records the commit its ref resolved to, if it's in a git
+repo, and a digest of its content.  Builds of the kustomization
+then fail if a remote root is missing from the lock, or differs
+from it.  Run lock again to accept a change.
+`,
+		Example: `
+	# Record the remote roots of the kustomization
+	kustomize edit lock
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := build.ValidateFlagLoadRestrictor()
+			if err != nil {
+				return err
+			}
+			return RunLock(fSys, o, cmd.OutOrStdout())
+		},
+	}
+	build.AddFlagLoadRestrictor(cmd.Flags())
+	build.AddFlagFnPlugins(cmd.Flags())
+	build.AddFlagExecPlugins(cmd.Flags())
+	build.AddFlagHelm(cmd.Flags())
+	cmd.Flags().StringVar(
+		&o.remoteCacheDir, "remote-cache-dir", "",
+		"where to cache clones of remote bases, as build does; if empty, they're not cached")
+	cmd.Flags().BoolVar(
+		&o.enablePlugins, "enable_alpha_plugins", false,
+		"enable plugins, an alpha feature, as build does")
+	return cmd
+}
+
+// RunLock builds the kustomization in the current directory,
+// with the load restrictions and plugin options of the flags,
+// and writes a lock of the remote roots it loads.
+func RunLock(fSys filesys.FileSystem, o lockOptions, out io.Writer) error {
+	opts := &krusty.Options{
+		PluginConfig:   konfig.DisabledPluginConfig(),
+		RemoteCacheDir: o.remoteCacheDir,
+	}
+	err := build.SetFlagLoadRestrictorOptions(fSys, opts)
+	if err != nil {
+		return err
+	}
+	if o.enablePlugins {
+		c, err := konfig.EnabledPluginConfig()
+		if err != nil {
+			return err
+		}
+		opts.PluginConfig = c
+	}
+	err = build.SetFlagPluginOptions(opts.PluginConfig)
+	if err != nil {
+		return err
+	}
+	lock, err := krusty.MakeKustomizer(fSys, opts).Lock(filesys.SelfDir)
+	if err != nil {
+		return err
+	}
+	err = loader.WriteLock(fSys, filesys.SelfDir, lock)
+	if err != nil {
+		return err
+	}
+	for _, r := range lock.Remotes {
+		fmt.Fprintf(out, "locked %s\n", r.URL)
+	}
+	return nil
+}
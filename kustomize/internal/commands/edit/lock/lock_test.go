@@ -0,0 +1,144 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package lock
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+	testutils_test "sigs.k8s.io/kustomize/kustomize/v3/internal/commands/testutils"
+)
+
+func TestLock(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	testutils_test.WriteTestKustomizationWith(fSys, []byte(`
+resources:
+- service.yaml
+`))
+	fSys.WriteFile("service.yaml", []byte(`
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`))
+
+	var out bytes.Buffer
+	cmd := NewCmdLock(fSys)
+	cmd.SetOutput(&out)
+	cmd.SetArgs([]string{})
+	err := cmd.Execute()
+	if err != nil {
+		t.Fatalf("unexpected cmd error: %v", err)
+	}
+	content, err := fSys.ReadFile("kustomization.lock")
+	if err != nil {
+		t.Fatalf("unexpected read error: %v", err)
+	}
+	expected := `apiVersion: kustomize.config.k8s.io/v1alpha1
+kind: KustomizationLock
+`
+	if string(content) != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, content)
+	}
+	if out.String() != "" {
+		t.Fatalf("unexpected output: %s", out.String())
+	}
+}
+
+func TestLockWithoutKustomization(t *testing.T) {
+	cmd := NewCmdLock(filesys.MakeFsInMemory())
+	cmd.SetArgs([]string{})
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	if err := cmd.Execute(); err == nil {
+		t.Fatalf("expected an error")
+	}
+}
+
+func TestLockLoadRestrictions(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	testutils_test.WriteTestKustomizationWith(fSys, []byte(`
+resources:
+- service.yaml
+`))
+	fSys.WriteFile("service.yaml", []byte(`
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`))
+	fSys.Mkdir("/common")
+	for _, args := range [][]string{
+		{"--load_restrictor", "LoadRestrictionsNone"},
+		{"--load_restrictor", "LoadRestrictionsAllowList",
+			"--load_allowed_root", "/common"},
+	} {
+		cmd := NewCmdLock(fSys)
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("%v: unexpected cmd error: %v", args, err)
+		}
+	}
+
+	cmd := NewCmdLock(fSys)
+	cmd.SetArgs([]string{"--load_allowed_root", "/common"})
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "require --load_restrictor") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestLockHelmCharts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kustomize-lock-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	helm := filepath.Join(dir, "helm")
+	err = ioutil.WriteFile(helm, []byte(`#!/bin/sh
+cat <<EOF
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: $2
+EOF
+`), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fSys := filesys.MakeFsInMemory()
+	testutils_test.WriteTestKustomizationWith(fSys, []byte(`
+helmCharts:
+- chart: charts/minecraft
+  releaseName: moria
+`))
+	fSys.WriteFile("charts/minecraft/Chart.yaml", []byte(`
+name: minecraft
+`))
+
+	cmd := NewCmdLock(fSys)
+	cmd.SetArgs([]string{})
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	err = cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "helm charts aren't enabled") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cmd = NewCmdLock(fSys)
+	cmd.SetArgs([]string{"--enable-helm", "--helm-command", helm})
+	if err = cmd.Execute(); err != nil {
+		t.Fatalf("unexpected cmd error: %v", err)
+	}
+	if _, err = fSys.ReadFile("kustomization.lock"); err != nil {
+		t.Fatalf("unexpected read error: %v", err)
+	}
+}
@@ -40,11 +40,14 @@ func determineFieldOrder() []string {
 	ordered := []string{
 		"Resources",
 		"Bases",
+		"Components",
 		"NamePrefix",
 		"NameSuffix",
 		"Namespace",
 		"Crds",
+		"OpenAPI",
 		"CommonLabels",
+		"Labels",
 		"CommonAnnotations",
 		"PatchesStrategicMerge",
 		"PatchesJson6902",
@@ -52,6 +55,9 @@ func determineFieldOrder() []string {
 		"ConfigMapGenerator",
 		"SecretGenerator",
 		"GeneratorOptions",
+		"HelmCharts",
+		"Replacements",
+		"Exclude",
 		"Vars",
 		"Images",
 		"Replicas",
@@ -59,6 +65,8 @@ func determineFieldOrder() []string {
 		"Generators",
 		"Transformers",
 		"Inventory",
+		"ConfigHash",
+		"SortOptions",
 	}
 
 	// Add deprecated fields here.
@@ -163,16 +171,58 @@ func (mf *kustomizationFile) Read() (*types.Kustomization, error) {
 }
 
 func (mf *kustomizationFile) Write(kustomization *types.Kustomization) error {
-	if kustomization == nil {
-		return errors.New("util: kustomization file arg is nil")
-	}
-	data, err := mf.marshal(kustomization)
+	data, err := mf.Marshal(kustomization)
 	if err != nil {
 		return err
 	}
 	return mf.fSys.WriteFile(mf.path, data)
 }
 
+// Path returns the path of the kustomization file.
+func (mf *kustomizationFile) Path() string {
+	return mf.path
+}
+
+// Marshal returns the content that Write would write
+// for the given kustomization.
+func (mf *kustomizationFile) Marshal(
+	kustomization *types.Kustomization) ([]byte, error) {
+	if kustomization == nil {
+		return nil, errors.New("util: kustomization file arg is nil")
+	}
+	return mf.marshal(kustomization)
+}
+
+// MoveFieldComments moves the comments of field from,
+// as read from the file, onto field to, e.g. when the
+// deprecated field from has been rewritten into field to.
+func (mf *kustomizationFile) MoveFieldComments(from, to string) {
+	var src, dst *commentedField
+	var fields []*commentedField
+	for _, f := range mf.originalFields {
+		switch f.field {
+		case from:
+			src = f
+		case to:
+			dst = f
+		}
+	}
+	if src == nil {
+		return
+	}
+	if dst == nil {
+		src.field = to
+		return
+	}
+	dst.comment = append(src.comment, dst.comment...)
+	for _, f := range mf.originalFields {
+		if f != src {
+			fields = append(fields, f)
+		}
+	}
+	mf.originalFields = fields
+}
+
 // StringInSlice returns true if the string is in the slice.
 func StringInSlice(str string, list []string) bool {
 	for _, v := range list {
@@ -20,11 +20,14 @@ func TestFieldOrder(t *testing.T) {
 		"Kind",
 		"Resources",
 		"Bases",
+		"Components",
 		"NamePrefix",
 		"NameSuffix",
 		"Namespace",
 		"Crds",
+		"OpenAPI",
 		"CommonLabels",
+		"Labels",
 		"CommonAnnotations",
 		"PatchesStrategicMerge",
 		"PatchesJson6902",
@@ -32,6 +35,9 @@ func TestFieldOrder(t *testing.T) {
 		"ConfigMapGenerator",
 		"SecretGenerator",
 		"GeneratorOptions",
+		"HelmCharts",
+		"Replacements",
+		"Exclude",
 		"Vars",
 		"Images",
 		"Replicas",
@@ -39,6 +45,8 @@ func TestFieldOrder(t *testing.T) {
 		"Generators",
 		"Transformers",
 		"Inventory",
+		"ConfigHash",
+		"SortOptions",
 	}
 	actual := determineFieldOrder()
 	if len(expected) != len(actual) {
@@ -340,3 +348,62 @@ kind: Kustomization
 			string(expected), string(bytes))
 	}
 }
+
+func TestMoveFieldComments(t *testing.T) {
+	kustomizationContentWithComments := []byte(`
+# the labels
+commonLabels:
+  app: web
+# the patches
+patchesStrategicMerge:
+- service.yaml
+# more patches
+patches:
+- path: deployment.yaml
+`)
+
+	expected := []byte(`
+# the labels
+labels:
+- includeSelectors: true
+  pairs:
+    app: web
+# the patches
+# more patches
+patches:
+- path: service.yaml
+- path: deployment.yaml
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+`)
+	fSys := filesys.MakeFsInMemory()
+	testutils_test.WriteTestKustomizationWith(
+		fSys, kustomizationContentWithComments)
+	mf, err := NewKustomizationFile(fSys)
+	if err != nil {
+		t.Fatalf("Unexpected Error: %v", err)
+	}
+
+	kustomization, err := mf.Read()
+	if err != nil {
+		t.Fatalf("Unexpected Error: %v", err)
+	}
+	kustomization.Labels = []types.Label{{
+		Pairs: kustomization.CommonLabels, IncludeSelectors: true}}
+	kustomization.CommonLabels = nil
+	mf.MoveFieldComments("CommonLabels", "Labels")
+	kustomization.Patches = append(
+		[]types.Patch{{Path: "service.yaml"}}, kustomization.Patches...)
+	kustomization.PatchesStrategicMerge = nil
+	mf.MoveFieldComments("PatchesStrategicMerge", "Patches")
+	if err = mf.Write(kustomization); err != nil {
+		t.Fatalf("Unexpected Error: %v", err)
+	}
+	bytes, _ := fSys.ReadFile(mf.Path())
+
+	if string(expected) != string(bytes) {
+		t.Fatalf(
+			"expected =\n%s\n\nactual =\n%s\n",
+			string(expected), string(bytes))
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package plugin
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/pluginhome"
+	"sigs.k8s.io/kustomize/api/resid"
+)
+
+const (
+	typeExec = "exec"
+	typeGo   = "go"
+
+	apiModule = "sigs.k8s.io/kustomize/api"
+)
+
+// InitOptions say what plugin to scaffold.
+type InitOptions struct {
+	// Type is typeExec or typeGo.
+	Type string
+
+	// Generator, if true, scaffolds a generator,
+	// rather than a transformer.
+	Generator bool
+
+	// APIVersion is the version of the kustomize API
+	// module the plugin requires; if empty, go.mod has
+	// no requirement, to be added by 'go mod tidy'.
+	APIVersion string
+
+	// Chmod makes the executable of an exec plugin
+	// executable; it's os.Chmod, but for tests.
+	Chmod func(name string, mode os.FileMode) error
+}
+
+func newCmdInit(
+	fSys filesys.FileSystem, out io.Writer, o *pluginOptions) *cobra.Command {
+	opts := InitOptions{
+		APIVersion: apiModuleVersion(),
+		Chmod:      os.Chmod,
+	}
+	c := &cobra.Command{
+		Use:   "init {apiVersion}/{kind}",
+		Short: "Scaffolds a new plugin in the plugin home",
+		Long: `Scaffolds a new plugin in the plugin home.
+
+Makes the plugin's directory, holding the plugin, an example
+schema of its config, a test using the kustomize test harness,
+and a go.mod for the test.  The plugin is a transformer, or,
+given --generator, a generator.  It's an exec plugin written
+in bash, or, given --type go, a Go plugin, to be built with
+
+  go build -buildmode plugin -o {kind}.so {kind}.go
+`,
+		Example: `
+	# Scaffold an exec transformer
+	kustomize plugin init someteam.example.com/v1/MyTransformer
+
+	# Scaffold a Go generator
+	kustomize plugin init someteam.example.com/v1/MyGenerator --type go --generator
+`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return RunInit(fSys, out, o.homeToCreate(), args[0], opts)
+		},
+	}
+	c.Flags().StringVar(
+		&opts.Type, "type", typeExec,
+		"the type of plugin, "+typeExec+" or "+typeGo)
+	c.Flags().BoolVar(
+		&opts.Generator, "generator", false,
+		"scaffold a generator, rather than a transformer")
+	return c
+}
+
+// apiModuleVersion returns the version of the kustomize
+// API module this binary was built with, if known.
+func apiModuleVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, m := range info.Deps {
+		if m.Path == apiModule && strings.HasPrefix(m.Version, "v") {
+			return m.Version
+		}
+	}
+	return ""
+}
+
+// scaffold holds the values the templates use.
+type scaffold struct {
+	resid.Gvk
+	APIVersion       string
+	Module           string
+	APIModule        string
+	APIModuleVersion string
+	Exec             bool
+	Generator        bool
+}
+
+var templateFuncs = template.FuncMap{
+	"bq": func() string { return "`" },
+}
+
+// RunInit scaffolds the named plugin in the given home.
+func RunInit(
+	fSys filesys.FileSystem, out io.Writer,
+	home, name string, o InitOptions) error {
+	gvk, err := pluginhome.ParseGvk(name)
+	if err != nil {
+		return err
+	}
+	dir := pluginhome.Dir(home, gvk)
+	if fSys.Exists(dir) {
+		return fmt.Errorf("plugin directory %s already exists", dir)
+	}
+	s := scaffold{
+		Gvk:              gvk,
+		APIVersion:       pluginhome.APIVersion(gvk),
+		Module:           path.Join(gvk.Group, gvk.Version, strings.ToLower(gvk.Kind)),
+		APIModule:        apiModule,
+		APIModuleVersion: o.APIVersion,
+		Exec:             o.Type == typeExec,
+		Generator:        o.Generator,
+	}
+	files := map[string]string{
+		gvk.Kind + "_test.go":     testTemplate,
+		pluginhome.SchemaFileName: schemaTemplate,
+		"go.mod":                  goModTemplate,
+	}
+	switch o.Type {
+	case typeExec:
+		files[gvk.Kind] = execPluginTemplate
+	case typeGo:
+		files[gvk.Kind+".go"] = goPluginTemplate
+	default:
+		return fmt.Errorf(
+			"unknown plugin type '%s'; expected %s or %s",
+			o.Type, typeExec, typeGo)
+	}
+	var names []string
+	for n := range files {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	if err = fSys.MkdirAll(dir); err != nil {
+		return err
+	}
+	for _, n := range names {
+		var b bytes.Buffer
+		t := template.Must(template.New(n).Funcs(templateFuncs).Parse(files[n]))
+		if err = t.Execute(&b, s); err != nil {
+			return err
+		}
+		p := filepath.Join(dir, n)
+		if err = fSys.WriteFile(p, b.Bytes()); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "created %s\n", p)
+	}
+	if s.Exec {
+		err = o.Chmod(filepath.Join(dir, gvk.Kind), 0755)
+		if err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(out, "run 'go mod tidy' and 'go test' in %s to test it\n", dir)
+	return nil
+}
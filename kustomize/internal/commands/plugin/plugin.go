@@ -0,0 +1,191 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package plugin holds the commands finding, describing
+// and scaffolding the plugins in the plugin home.
+package plugin
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/pluginhome"
+)
+
+type pluginOptions struct {
+	home string
+}
+
+// NewCmdPlugin returns an instance of 'plugin' subcommand.
+func NewCmdPlugin(fSys filesys.FileSystem, out io.Writer) *cobra.Command {
+	var o pluginOptions
+	c := &cobra.Command{
+		Use:   "plugin",
+		Short: "Finds, describes and scaffolds plugins",
+		Long: `Finds, describes and scaffolds the plugins in the plugin home.
+
+A plugin configured with apiVersion {group}/{version} and
+kind {kind} is housed in the plugin home in the directory
+{group}/{version}/{lowercase kind}, as an executable named
+{kind}, or as a Go plugin named {kind}.so.
+See https://github.com/kubernetes-sigs/kustomize/blob/master/docs/plugins/README.md
+`,
+		Example: `
+	# List the plugins
+	kustomize plugin list
+
+	# Describe a plugin
+	kustomize plugin describe someteam.example.com/v1/SedTransformer
+
+	# Scaffold an exec transformer
+	kustomize plugin init someteam.example.com/v1/MyTransformer
+`,
+		Args: cobra.MinimumNArgs(1),
+	}
+	c.PersistentFlags().StringVar(
+		&o.home, "plugin-home", "",
+		"the plugin home; if empty, found as build finds it")
+	c.AddCommand(
+		newCmdList(fSys, out, &o),
+		newCmdDescribe(fSys, out, &o),
+		newCmdInit(fSys, out, &o),
+	)
+	return c
+}
+
+// findHome returns the plugin home, which must exist.
+func (o *pluginOptions) findHome(fSys filesys.FileSystem) (string, error) {
+	if o.home != "" {
+		if !fSys.IsDir(o.home) {
+			return "", fmt.Errorf("plugin home %s isn't a directory", o.home)
+		}
+		return o.home, nil
+	}
+	return konfig.DefaultAbsPluginHome(fSys)
+}
+
+// homeToCreate returns the plugin home, for making plugins
+// in, which needn't exist.  It's the first home build would
+// look in.
+func (o *pluginOptions) homeToCreate() string {
+	if o.home != "" {
+		return o.home
+	}
+	if home := os.Getenv(konfig.KustomizePluginHomeEnv); home != "" {
+		return home
+	}
+	if xdg := os.Getenv(konfig.XdgConfigHomeEnv); xdg != "" {
+		return filepath.Join(xdg, konfig.ProgramName, konfig.RelPluginHome)
+	}
+	return filepath.Join(
+		konfig.HomeDir(), konfig.XdgConfigHomeEnvDefault,
+		konfig.ProgramName, konfig.RelPluginHome)
+}
+
+func newCmdList(
+	fSys filesys.FileSystem, out io.Writer, o *pluginOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:     "list",
+		Short:   "Lists the plugins in the plugin home",
+		Example: `kustomize plugin list`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			home, err := o.findHome(fSys)
+			if err != nil {
+				return err
+			}
+			return RunList(fSys, out, home)
+		},
+	}
+}
+
+// RunList lists the plugins in the given home.
+func RunList(fSys filesys.FileSystem, out io.Writer, home string) error {
+	plugins, err := pluginhome.List(fSys, home)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "APIVERSION\tKIND\tTYPE\tPATH")
+	for _, p := range plugins {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+			p.APIVersion(), p.Gvk.Kind, p.Type, p.Path)
+	}
+	return w.Flush()
+}
+
+func newCmdDescribe(
+	fSys filesys.FileSystem, out io.Writer, o *pluginOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "describe {apiVersion}/{kind}",
+		Short: "Describes a plugin in the plugin home",
+		Long: `Describes a plugin in the plugin home.
+
+Shows the type and location of the plugin, the comment
+heading an exec plugin or the doc comment of a Go plugin,
+and the schema of its config, if its directory holds one
+in a file named ` + pluginhome.SchemaFileName + `.
+`,
+		Example: `kustomize plugin describe someteam.example.com/v1/SedTransformer`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			home, err := o.findHome(fSys)
+			if err != nil {
+				return err
+			}
+			return RunDescribe(fSys, out, home, args[0])
+		},
+	}
+}
+
+// RunDescribe describes the named plugin in the given home.
+func RunDescribe(
+	fSys filesys.FileSystem, out io.Writer, home, name string) error {
+	gvk, err := pluginhome.ParseGvk(name)
+	if err != nil {
+		return err
+	}
+	p, err := pluginhome.Get(fSys, home, gvk)
+	if err != nil {
+		return err
+	}
+	d, err := pluginhome.Describe(fSys, p)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(out, 0, 4, 1, ' ', 0)
+	fmt.Fprintf(w, "APIVersion:\t%s\n", p.APIVersion())
+	fmt.Fprintf(w, "Kind:\t%s\n", p.Gvk.Kind)
+	fmt.Fprintf(w, "Type:\t%s\n", p.Type)
+	fmt.Fprintf(w, "Path:\t%s\n", p.Path)
+	if len(d.Methods) > 0 {
+		fmt.Fprintf(w, "Methods:\t%s\n", strings.Join(d.Methods, ", "))
+	}
+	if err = w.Flush(); err != nil {
+		return err
+	}
+	if d.Doc != "" {
+		fmt.Fprintf(out, "\n%s\n", d.Doc)
+	}
+	if d.Schema != "" {
+		fmt.Fprintf(out, "\nSchema:\n%s", indent(d.Schema))
+	}
+	return nil
+}
+
+func indent(s string) string {
+	var sb strings.Builder
+	for _, line := range strings.SplitAfter(s, "\n") {
+		if strings.TrimSpace(line) != "" {
+			sb.WriteString("  ")
+		}
+		sb.WriteString(line)
+	}
+	return sb.String()
+}
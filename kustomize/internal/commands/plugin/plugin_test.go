@@ -0,0 +1,115 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package plugin_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+	. "sigs.k8s.io/kustomize/kustomize/v3/internal/commands/plugin"
+)
+
+const home = "/plugin"
+
+func noChmod(string, os.FileMode) error { return nil }
+
+func TestInitListAndDescribe(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	var out bytes.Buffer
+	err := RunInit(fSys, &out, home, "someteam.example.com/v1/Valuer",
+		InitOptions{Type: "exec", APIVersion: "v0.3.2", Chmod: noChmod})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = RunInit(fSys, &out, home, "v1/MapMaker",
+		InitOptions{Type: "go", Generator: true, Chmod: noChmod})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(),
+		"created /plugin/someteam.example.com/v1/valuer/Valuer\n") {
+		t.Fatalf("unexpected output:\n%s", out.String())
+	}
+	content, _ := fSys.ReadFile("/plugin/someteam.example.com/v1/valuer/go.mod")
+	if string(content) != `module someteam.example.com/v1/valuer
+
+go 1.13
+
+require sigs.k8s.io/kustomize/api v0.3.2
+` {
+		t.Fatalf("unexpected go.mod:\n%s", content)
+	}
+
+	out.Reset()
+	if err = RunList(fSys, &out, home); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `APIVERSION               KIND      TYPE            PATH
+someteam.example.com/v1  Valuer    exec            /plugin/someteam.example.com/v1/valuer/Valuer
+v1                       MapMaker  Go (not built)  /plugin/v1/mapmaker/MapMaker.go
+`
+	if out.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+
+	out.Reset()
+	if err = RunDescribe(fSys, &out, home, "v1/MapMaker"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected = `APIVersion: v1
+Kind:       MapMaker
+Type:       Go (not built)
+Path:       /plugin/v1/mapmaker/MapMaker.go
+Methods:    Config, Generate
+
+MapMaker is a kustomize generator, making a
+ConfigMap holding the value in its config.
+
+Schema:
+  # The OpenAPI schema of the config of the MapMaker
+  # plugin, shown by 'kustomize plugin describe'.
+  type: object
+  properties:
+    apiVersion:
+      type: string
+      enum:
+      - v1
+    kind:
+      type: string
+      enum:
+      - MapMaker
+    metadata:
+      type: object
+    value:
+      type: string
+      description: |
+        The value the generated ConfigMap holds.
+`
+	if out.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestInitErrors(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	var out bytes.Buffer
+	opts := InitOptions{Type: "exec", Chmod: noChmod}
+	if err := RunInit(fSys, &out, home, "v1/Valuer", opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err := RunInit(fSys, &out, home, "v1/Valuer", opts)
+	if err == nil || !strings.Contains(err.Error(),
+		"plugin directory /plugin/v1/valuer already exists") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	opts.Type = "python"
+	err = RunInit(fSys, &out, home, "v1/Other", opts)
+	if err == nil || !strings.Contains(err.Error(),
+		"unknown plugin type 'python'") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
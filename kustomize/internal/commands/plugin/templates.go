@@ -0,0 +1,197 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package plugin
+
+// The templates of the files of a scaffolded plugin.
+// The example plugins use a 'value' field of their config;
+// a transformer puts it in the resources, and a generator
+// makes a ConfigMap holding it.  In Go source, {{bq}} is
+// a backquote.
+
+const execPluginTemplate = `#!/bin/bash
+{{- if .Generator}}
+# {{.Kind}} is a kustomize generator.  It's given the path
+# of its config as its first argument, and writes the
+# resources it generates to stdout.  This one generates
+# a ConfigMap holding the value in its config.
+
+name=$(sed -n 's/^  name: //p' "$1")
+value=$(sed -n 's/^value: //p' "$1")
+cat <<EOF
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: $name
+data:
+  value: $value
+EOF
+{{- else}}
+# {{.Kind}} is a kustomize transformer.  It's given the path
+# of its config as its first argument, and the resources on
+# stdin, and writes the transformed resources to stdout.
+# This one replaces $VALUE with the value in its config.
+
+value=$(sed -n 's/^value: //p' "$1")
+sed "s/\$VALUE/$value/g"
+{{- end}}
+`
+
+const goPluginTemplate = `package main
+
+import (
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+)
+{{if .Generator}}
+// {{.Kind}} is a kustomize generator, making a
+// ConfigMap holding the value in its config.
+type plugin struct {
+	h                *resmap.PluginHelpers
+	types.ObjectMeta {{bq}}json:"metadata,omitempty" yaml:"metadata,omitempty"{{bq}}
+	Value            string {{bq}}json:"value,omitempty" yaml:"value,omitempty"{{bq}}
+}
+{{else}}
+// {{.Kind}} is a kustomize transformer, annotating
+// the resources with the value in its config.
+type plugin struct {
+	types.ObjectMeta {{bq}}json:"metadata,omitempty" yaml:"metadata,omitempty"{{bq}}
+	Value            string {{bq}}json:"value,omitempty" yaml:"value,omitempty"{{bq}}
+}
+{{end}}
+//nolint: golint
+//noinspection GoUnusedGlobalVariable
+var KustomizePlugin plugin
+
+func (p *plugin) Config(h *resmap.PluginHelpers, c []byte) error {
+{{- if .Generator}}
+	p.h = h
+{{- end}}
+	return yaml.Unmarshal(c, p)
+}
+{{if .Generator}}
+func (p *plugin) Generate() (resmap.ResMap, error) {
+	rm := resmap.New()
+	err := rm.Append(p.h.ResmapFactory().RF().FromMap(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name": p.Name,
+		},
+		"data": map[string]interface{}{
+			"value": p.Value,
+		},
+	}))
+	return rm, err
+}
+{{- else}}
+func (p *plugin) Transform(m resmap.ResMap) error {
+	for _, r := range m.Resources() {
+		annotations := r.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		annotations["value"] = p.Value
+		r.SetAnnotations(annotations)
+	}
+	return nil
+}
+{{- end}}
+`
+
+const testTemplate = `package main_test
+
+import (
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func Test{{.Kind}}(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		{{if .Exec}}PrepExecPlugin{{else}}BuildGoPlugin{{end}}("{{.Group}}", "{{.Version}}", "{{.Kind}}")
+	defer th.Reset()
+{{if .Generator}}
+	m := th.LoadAndRunGenerator({{bq}}
+apiVersion: {{.APIVersion}}
+kind: {{.Kind}}
+metadata:
+  name: example
+value: hello
+{{bq}})
+
+	th.AssertActualEqualsExpected(m, {{bq}}
+apiVersion: v1
+data:
+  value: hello
+kind: ConfigMap
+metadata:
+  name: example
+{{bq}})
+{{- else}}
+	m := th.LoadAndRunTransformer({{bq}}
+apiVersion: {{.APIVersion}}
+kind: {{.Kind}}
+metadata:
+  name: example
+value: hello
+{{bq}}, {{bq}}
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: greeting
+data:
+  greeting: {{if .Exec}}$VALUE{{else}}hi{{end}}
+{{bq}})
+
+	th.AssertActualEqualsExpected(m, {{bq}}
+apiVersion: v1
+data:
+  greeting: {{if .Exec}}hello{{else}}hi{{end}}
+kind: ConfigMap
+metadata:
+{{- if not .Exec}}
+  annotations:
+    value: hello
+{{- end}}
+  name: greeting
+{{bq}})
+{{- end}}
+}
+`
+
+const schemaTemplate = `# The OpenAPI schema of the config of the {{.Kind}}
+# plugin, shown by 'kustomize plugin describe'.
+type: object
+properties:
+  apiVersion:
+    type: string
+    enum:
+    - {{.APIVersion}}
+  kind:
+    type: string
+    enum:
+    - {{.Kind}}
+  metadata:
+    type: object
+  value:
+    type: string
+    description: |
+{{- if .Generator}}
+      The value the generated ConfigMap holds.
+{{- else if .Exec}}
+      The value replacing $VALUE in the resources.
+{{- else}}
+      The value the resources are annotated with.
+{{- end}}
+`
+
+const goModTemplate = `module {{.Module}}
+
+go 1.13
+{{- if .APIModuleVersion}}
+
+require {{.APIModule}} {{.APIModuleVersion}}
+{{- end}}
+`
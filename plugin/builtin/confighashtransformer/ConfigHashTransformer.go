@@ -0,0 +1,215 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate pluginator
+package main
+
+import (
+	"sigs.k8s.io/kustomize/api/hasher"
+	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+)
+
+// Annotate each pod template with a hash of the contents
+// of the ConfigMaps and Secrets it refers to, so that
+// changing them rolls out new pods without renaming them.
+// A pod template is found in a reference field's path, as
+// the part ending in a "template" field, e.g. spec/template
+// in spec/template/spec/volumes/configMap/name.  Reference
+// fields outside pod templates are ignored.
+type plugin struct {
+	// Annotation is the key of the annotation.
+	Annotation string `json:"annotation,omitempty" yaml:"annotation,omitempty"`
+
+	// ConfigMapRefs are the fields that may
+	// hold the name of a ConfigMap.
+	ConfigMapRefs types.FsSlice `json:"configMapRefs,omitempty" yaml:"configMapRefs,omitempty"`
+
+	// SecretRefs are the fields that may
+	// hold the name of a Secret.
+	SecretRefs types.FsSlice `json:"secretRefs,omitempty" yaml:"secretRefs,omitempty"`
+
+	hasher ifc.KunstructuredHasher
+}
+
+//noinspection GoUnusedGlobalVariable
+var KustomizePlugin plugin
+
+func (p *plugin) Config(
+	h *resmap.PluginHelpers, c []byte) (err error) {
+	p.Annotation = ""
+	p.ConfigMapRefs = nil
+	p.SecretRefs = nil
+	err = yaml.Unmarshal(c, p)
+	if err != nil {
+		return err
+	}
+	if p.Annotation == "" {
+		p.Annotation = types.DefaultConfigHashAnnotation
+	}
+	p.hasher = h.ResmapFactory().RF().Hasher()
+	return nil
+}
+
+func (p *plugin) Transform(m resmap.ResMap) error {
+	for _, r := range m.Resources() {
+		var templates []*podTemplate
+		templates = p.findReferences(m, r, "ConfigMap", p.ConfigMapRefs, templates)
+		templates = p.findReferences(m, r, "Secret", p.SecretRefs, templates)
+		for _, t := range templates {
+			h, err := p.hash(t.referred)
+			if err != nil {
+				return err
+			}
+			setAnnotation(r.Map(), t.path, p.Annotation, h)
+		}
+	}
+	return nil
+}
+
+// podTemplate is a pod template in a resource,
+// and the resources it refers to.
+type podTemplate struct {
+	path     []string
+	referred []*resource.Resource
+}
+
+// findReferences adds to templates the resources of the given
+// kind that r refers to in the fields of the given field specs,
+// by the pod template holding the field.
+func (p *plugin) findReferences(
+	m resmap.ResMap, r *resource.Resource, kind string,
+	fieldSpecs types.FsSlice, templates []*podTemplate) []*podTemplate {
+	gvk := r.GetGvk()
+	for _, fs := range fieldSpecs {
+		if !gvk.IsSelected(&fs.Gvk) {
+			continue
+		}
+		path := fs.PathSlice()
+		n := templateLength(path)
+		if n == 0 {
+			continue
+		}
+		for _, name := range stringsAt(r.Map(), path) {
+			for _, other := range m.Resources() {
+				if other.GetKind() != kind || other.GetName() != name ||
+					other.GetNamespace() != r.GetNamespace() {
+					continue
+				}
+				var t *podTemplate
+				templates, t = templateAt(templates, path[:n])
+				if !contains(t.referred, other) {
+					t.referred = append(t.referred, other)
+				}
+			}
+		}
+	}
+	return templates
+}
+
+// templateAt returns the template at path,
+// adding it to templates if it isn't there.
+func templateAt(
+	templates []*podTemplate, path []string) ([]*podTemplate, *podTemplate) {
+	for _, t := range templates {
+		if equalPaths(t.path, path) {
+			return templates, t
+		}
+	}
+	t := &podTemplate{path: path}
+	return append(templates, t), t
+}
+
+func equalPaths(x, y []string) bool {
+	if len(x) != len(y) {
+		return false
+	}
+	for i := range x {
+		if x[i] != y[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// hash returns a hash of the contents
+// of the given ConfigMaps and Secrets.
+func (p *plugin) hash(resources []*resource.Resource) (string, error) {
+	var hashes []string
+	for _, r := range resources {
+		h, err := p.hasher.Hash(r)
+		if err != nil {
+			return "", err
+		}
+		hashes = append(hashes, h)
+	}
+	return hasher.SortArrayAndComputeHash(hashes)
+}
+
+// templateLength returns the length of the part of path
+// ending in its last "template" field, or zero if it has
+// no "template" field short of its end.
+func templateLength(path []string) int {
+	for i := len(path) - 2; i >= 0; i-- {
+		if path[i] == "template" {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+func contains(resources []*resource.Resource, r *resource.Resource) bool {
+	for _, x := range resources {
+		if x == r {
+			return true
+		}
+	}
+	return false
+}
+
+// stringsAt returns the strings at path in node,
+// looking into each element of the lists on the way.
+func stringsAt(node interface{}, path []string) []string {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		if len(path) == 0 {
+			return nil
+		}
+		return stringsAt(n[path[0]], path[1:])
+	case []interface{}:
+		var result []string
+		for _, e := range n {
+			result = append(result, stringsAt(e, path)...)
+		}
+		return result
+	case string:
+		if len(path) == 0 {
+			return []string{n}
+		}
+	}
+	return nil
+}
+
+// setAnnotation sets an annotation of the
+// object at path in node, if there is one.
+func setAnnotation(node map[string]interface{}, path []string, key, value string) {
+	for _, f := range path {
+		child, ok := node[f].(map[string]interface{})
+		if !ok {
+			return
+		}
+		node = child
+	}
+	for _, f := range []string{"metadata", "annotations"} {
+		child, ok := node[f].(map[string]interface{})
+		if !ok {
+			child = make(map[string]interface{})
+			node[f] = child
+		}
+		node = child
+	}
+	node[key] = value
+}
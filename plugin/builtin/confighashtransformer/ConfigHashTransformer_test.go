@@ -0,0 +1,112 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main_test
+
+import (
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestConfigHashTransformer(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("ConfigHashTransformer")
+	defer th.Reset()
+
+	rm := th.LoadAndRunTransformer(`
+apiVersion: builtin
+kind: ConfigHashTransformer
+metadata:
+  name: notImportantHere
+annotation: example.com/config-hash
+configMapRefs:
+- kind: CronJob
+  path: spec/jobTemplate/spec/template/spec/volumes/configMap/name
+- kind: Pod
+  path: spec/volumes/configMap/name
+`, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  mode: fast
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: other
+data:
+  mode: slow
+---
+apiVersion: batch/v1beta1
+kind: CronJob
+metadata:
+  name: report
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          volumes:
+          - name: config
+            configMap:
+              name: settings
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: debug
+spec:
+  volumes:
+  - name: config
+    configMap:
+      name: settings
+`)
+
+	th.AssertActualEqualsExpected(rm, `
+apiVersion: v1
+data:
+  mode: fast
+kind: ConfigMap
+metadata:
+  name: settings
+---
+apiVersion: v1
+data:
+  mode: slow
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: other
+---
+apiVersion: batch/v1beta1
+kind: CronJob
+metadata:
+  name: report
+spec:
+  jobTemplate:
+    spec:
+      template:
+        metadata:
+          annotations:
+            example.com/config-hash: c22tkbd775
+        spec:
+          volumes:
+          - configMap:
+              name: settings
+            name: config
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: debug
+spec:
+  volumes:
+  - configMap:
+      name: settings
+    name: config
+`)
+}
@@ -0,0 +1,84 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate pluginator
+package main
+
+import (
+	"log"
+
+	"sigs.k8s.io/kustomize/api/resid"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+)
+
+// Remove the resources matching any of the selectors.
+// The resources left that still refer to a removed
+// resource by name are reported with a warning.
+type plugin struct {
+	// Selectors pick the resources to remove.
+	Selectors []types.Selector `json:"selectors,omitempty" yaml:"selectors,omitempty"`
+
+	// NameReferences are the fields that
+	// may hold the names of other resources.
+	NameReferences []nameReference `json:"nameReferences,omitempty" yaml:"nameReferences,omitempty"`
+}
+
+// nameReference holds the fields that
+// may hold the name of a resource of a Gvk.
+type nameReference struct {
+	resid.Gvk  `json:",inline,omitempty" yaml:",inline,omitempty"`
+	FieldSpecs types.FsSlice `json:"fieldSpecs,omitempty" yaml:"fieldSpecs,omitempty"`
+}
+
+//noinspection GoUnusedGlobalVariable
+var KustomizePlugin plugin
+
+func (p *plugin) Config(
+	_ *resmap.PluginHelpers, c []byte) (err error) {
+	p.Selectors = nil
+	p.NameReferences = nil
+	return yaml.Unmarshal(c, p)
+}
+
+func (p *plugin) Transform(m resmap.ResMap) error {
+	var removed []*resource.Resource
+	for _, sel := range p.Selectors {
+		resources, err := m.Select(sel)
+		if err != nil {
+			return err
+		}
+		for _, r := range resources {
+			err = m.Remove(r.CurId())
+			if err != nil {
+				return err
+			}
+			removed = append(removed, r)
+		}
+	}
+	for _, r := range m.Resources() {
+		for _, other := range removed {
+			if p.refersTo(r, other) {
+				log.Printf(
+					"warning: %s refers to %s, which was excluded\n",
+					r.CurId(), other.CurId())
+			}
+		}
+	}
+	return nil
+}
+
+// refersTo returns true if r holds the name
+// of other in one of the name reference fields.
+func (p *plugin) refersTo(r, other *resource.Resource) bool {
+	otherGvk := other.GetGvk()
+	for _, nr := range p.NameReferences {
+		if otherGvk.IsSelected(&nr.Gvk) &&
+			r.RefersTo(other, nr.FieldSpecs) {
+			return true
+		}
+	}
+	return false
+}
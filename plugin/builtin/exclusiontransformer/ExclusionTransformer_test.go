@@ -0,0 +1,94 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main_test
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+const someResources = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  labels:
+    env: prod
+spec:
+  template:
+    spec:
+      volumes:
+      - name: config
+        configMap:
+          name: settings
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  labels:
+    env: dev
+---
+apiVersion: policy/v1beta1
+kind: PodDisruptionBudget
+metadata:
+  name: app
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+  labels:
+    env: dev
+`
+
+func TestExclusionTransformer(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("ExclusionTransformer")
+	defer th.Reset()
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	th.RunTransformerAndCheckResult(`
+apiVersion: builtin
+kind: ExclusionTransformer
+metadata:
+  name: notImportantHere
+selectors:
+- kind: PodDisruptionBudget
+- labelSelector: env=dev
+nameReferences:
+- kind: ConfigMap
+  version: v1
+  fieldSpecs:
+  - kind: Deployment
+    path: spec/template/spec/volumes/configMap/name
+`, someResources, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    env: prod
+  name: app
+spec:
+  template:
+    spec:
+      volumes:
+      - configMap:
+          name: settings
+        name: config
+`)
+	if !strings.Contains(buf.String(),
+		"warning: apps_v1_Deployment|~X|app refers to "+
+			"~G_v1_ConfigMap|~X|settings, which was excluded") {
+		t.Fatalf("expected a warning, got %q", buf.String())
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate pluginator
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/resid"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+)
+
+// Render a local Helm chart, a directory or a .tgz
+// archive, by running `helm template`.  Helm must be
+// enabled in the plugin config of the build, which
+// names the helm binary; it runs as exec plugins do.
+type plugin struct {
+	h           *resmap.PluginHelpers
+	helmCommand string
+	types.HelmChart
+}
+
+//noinspection GoUnusedGlobalVariable
+var KustomizePlugin plugin
+
+func (p *plugin) Config(
+	h *resmap.PluginHelpers, config []byte) (err error) {
+	p.HelmChart = types.HelmChart{}
+	err = yaml.Unmarshal(config, p)
+	if err != nil {
+		return err
+	}
+	hc := h.PluginConfig().HelmConfig
+	if !hc.Enabled {
+		return fmt.Errorf(
+			"helm charts aren't enabled; can't inflate chart '%s'", p.Chart)
+	}
+	if p.Chart == "" {
+		return fmt.Errorf("must specify a chart")
+	}
+	if p.ReleaseName == "" {
+		p.ReleaseName = types.DefaultHelmReleaseName
+	}
+	p.helmCommand = hc.Command
+	if p.helmCommand == "" {
+		p.helmCommand = "helm"
+	}
+	p.h = h
+	return nil
+}
+
+func (p *plugin) Generate() (resmap.ResMap, error) {
+	ldr := p.h.Loader()
+	chart, err := checkChart(ldr, p.Chart)
+	if err != nil {
+		return nil, err
+	}
+	args := []string{"template", p.ReleaseName, chart}
+	if p.Namespace != "" {
+		args = append(args, "--namespace", p.Namespace)
+	}
+	if p.ValuesFile != "" {
+		values, err := ldr.Load(p.ValuesFile)
+		if err != nil {
+			return nil, errors.Wrapf(
+				err, "loading values of chart '%s'", p.Chart)
+		}
+		f, err := ioutil.TempFile("", "kustomize-helm-values-")
+		if err != nil {
+			return nil, err
+		}
+		defer os.Remove(f.Name())
+		_, err = f.Write(values)
+		if err == nil {
+			err = f.Close()
+		}
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, "--values", f.Name())
+	}
+	gvk := resid.Gvk{
+		Version: konfig.BuiltinPluginApiVersion,
+		Kind:    "HelmChartInflationGenerator",
+	}
+	out, err := p.h.RunCommand(gvk, p.helmCommand, args)
+	if err != nil {
+		return nil, errors.Wrapf(
+			err, "running '%s %s'", p.helmCommand, strings.Join(args, " "))
+	}
+	m, err := p.h.ResmapFactory().NewResMapFromBytes(out)
+	if err != nil {
+		return nil, errors.Wrapf(
+			err, "reading the output of chart '%s'", p.Chart)
+	}
+	return m, nil
+}
+
+// checkChart checks that the loader allows loading the
+// chart at path, and returns the path for helm to read,
+// in the file system of the loader's root.
+func checkChart(ldr ifc.Loader, path string) (string, error) {
+	file := filepath.Join(path, "Chart.yaml")
+	if strings.HasSuffix(path, ".tgz") {
+		file = path
+	}
+	_, err := ldr.Load(file)
+	if err != nil {
+		return "", errors.Wrapf(err, "loading chart '%s'", path)
+	}
+	if filepath.IsAbs(path) {
+		return path, nil
+	}
+	return filepath.Join(ldr.Root(), path), nil
+}
@@ -0,0 +1,142 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// fakeHelm renders a ConfigMap holding the arguments
+// of `helm template` and the values it's given.
+const fakeHelm = `#!/bin/sh
+shift
+name=$1
+chart=$2
+shift 2
+while [ $# -gt 0 ]; do
+  case "$1" in
+  --namespace) namespace=$2 ;;
+  --values) values=$(cat "$2") ;;
+  esac
+  shift 2
+done
+cat <<EOF
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: $name
+data:
+  chart: $chart
+  namespace: "$namespace"
+  values: "$values"
+EOF
+`
+
+func writeFakeHelm(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "kustomize-helm-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	helm := filepath.Join(dir, "helm")
+	err = ioutil.WriteFile(helm, []byte(fakeHelm), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return helm, func() { os.RemoveAll(dir) }
+}
+
+func TestHelmChartInflationGenerator(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("HelmChartInflationGenerator")
+	defer th.Reset()
+	helm, cleanup := writeFakeHelm(t)
+	defer cleanup()
+	th.PluginConfig().HelmConfig = types.HelmConfig{
+		Enabled: true, Command: helm}
+
+	th.WriteF("/minecraft/Chart.yaml", `
+name: minecraft
+`)
+	th.WriteF("/values.yaml", `replicas=3`)
+
+	rm := th.LoadAndRunGenerator(`
+apiVersion: builtin
+kind: HelmChartInflationGenerator
+metadata:
+  name: notImportantHere
+chart: minecraft
+releaseName: games
+namespace: play
+valuesFile: values.yaml
+`)
+
+	th.AssertActualEqualsExpected(rm, `
+apiVersion: v1
+data:
+  chart: /minecraft
+  namespace: play
+  values: replicas=3
+kind: ConfigMap
+metadata:
+  name: games
+`)
+}
+
+func TestHelmChartInflationGeneratorArchive(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("HelmChartInflationGenerator")
+	defer th.Reset()
+	helm, cleanup := writeFakeHelm(t)
+	defer cleanup()
+	th.PluginConfig().HelmConfig = types.HelmConfig{
+		Enabled: true, Command: helm}
+
+	th.WriteF("/charts/minecraft-1.2.0.tgz", `not really an archive`)
+
+	rm := th.LoadAndRunGenerator(`
+apiVersion: builtin
+kind: HelmChartInflationGenerator
+metadata:
+  name: notImportantHere
+chart: charts/minecraft-1.2.0.tgz
+`)
+
+	th.AssertActualEqualsExpected(rm, `
+apiVersion: v1
+data:
+  chart: /charts/minecraft-1.2.0.tgz
+  namespace: ""
+  values: ""
+kind: ConfigMap
+metadata:
+  name: release-name
+`)
+}
+
+func TestHelmChartInflationGeneratorDisabled(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("HelmChartInflationGenerator")
+	defer th.Reset()
+
+	th.WriteF("/minecraft/Chart.yaml", `
+name: minecraft
+`)
+	err := th.ErrorFromLoadAndRunGenerator(`
+apiVersion: builtin
+kind: HelmChartInflationGenerator
+metadata:
+  name: notImportantHere
+chart: minecraft
+`)
+	if err == nil || !strings.Contains(err.Error(), "helm charts aren't enabled") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -5,6 +5,7 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"sigs.k8s.io/kustomize/api/hasher"
@@ -21,6 +22,14 @@ type plugin struct {
 	h                *resmap.PluginHelpers
 	types.ObjectMeta `json:"metadata,omitempty" yaml:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
 	Policy           string `json:"policy,omitempty" yaml:"policy,omitempty"`
+
+	// Type is the type of the inventory object:
+	// ConfigMap (the default), Secret or Custom.
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+
+	// Custom holds the apiVersion and kind
+	// of the object of a Custom inventory.
+	Custom types.TypeMeta `json:"custom,omitempty" yaml:"custom,omitempty"`
 }
 
 //noinspection GoUnusedGlobalVariable
@@ -41,11 +50,26 @@ func (p *plugin) Config(
 		return fmt.Errorf(
 			"unrecognized garbagePolicy '%s'", p.Policy)
 	}
+	switch p.Type {
+	case "":
+		p.Type = types.InventoryTypeConfigMap
+	case types.InventoryTypeConfigMap, types.InventoryTypeSecret:
+	case types.InventoryTypeCustom:
+		if p.Custom.APIVersion == "" || p.Custom.Kind == "" {
+			return fmt.Errorf(
+				"a Custom inventory requires an apiVersion and kind")
+		}
+	default:
+		return fmt.Errorf(
+			"unrecognized inventory type '%s'; expected one of %v",
+			p.Type, []string{types.InventoryTypeConfigMap,
+				types.InventoryTypeSecret, types.InventoryTypeCustom})
+	}
 	return nil
 }
 
 // Transform generates an inventory object from the input ResMap.
-// This ConfigMap supports the pruning command in
+// This object supports the pruning command in
 // the client side tool proposed here:
 // https://github.com/kubernetes/enhancements/pull/810
 //
@@ -55,27 +79,25 @@ func (p *plugin) Config(
 //   1. Keys in a ConfigMap's data field are too
 //      constrained for this purpose.
 //   2. Using annotations allow any object to be used,
-//      not just a ConfigMap, should some other object
-//      (e.g. some App object) become more desirable
-//      for this purpose.
+//      not just a ConfigMap, e.g. a Secret or some
+//      custom App object.
 func (p *plugin) Transform(m resmap.ResMap) error {
 	inv, h, err := makeInventory(m)
 	if err != nil {
 		return err
 	}
-	args := types.ConfigMapArgs{}
-	args.Name = p.Name
-	args.Namespace = p.Namespace
-	args.Options = &types.GeneratorOptions{
-		Annotations: map[string]string{inventory.HashAnnotation: h},
+	annotations := map[string]string{inventory.HashAnnotation: h}
+	err = inv.UpdateAnnotations(annotations)
+	if err != nil {
+		return err
 	}
-	err = inv.UpdateAnnotations(args.Options.Annotations)
+	hashes, err := computeObjectHashes(m)
 	if err != nil {
 		return err
 	}
+	annotations[inventory.ObjectHashesAnnotation] = hashes
 
-	cm, err := p.h.ResmapFactory().RF().MakeConfigMap(
-		kv.NewLoader(p.h.Loader(), p.h.Validator()), &args)
+	obj, err := p.makeInventoryObject(annotations)
 	if err != nil {
 		return err
 	}
@@ -85,7 +107,64 @@ func (p *plugin) Transform(m resmap.ResMap) error {
 			m.Remove(byeBye)
 		}
 	}
-	return m.Append(cm)
+	return m.Append(obj)
+}
+
+// makeInventoryObject makes the object, per the
+// inventory type, holding the given annotations.
+func (p *plugin) makeInventoryObject(
+	annotations map[string]string) (*resource.Resource, error) {
+	rf := p.h.ResmapFactory().RF()
+	kvLdr := kv.NewLoader(p.h.Loader(), p.h.Validator())
+	genArgs := types.GeneratorArgs{
+		Namespace: p.Namespace,
+		Name:      p.Name,
+		Options:   &types.GeneratorOptions{Annotations: annotations},
+	}
+	switch p.Type {
+	case types.InventoryTypeSecret:
+		return rf.MakeSecret(
+			kvLdr, &types.SecretArgs{GeneratorArgs: genArgs, Type: "Opaque"})
+	case types.InventoryTypeCustom:
+		metadata := map[string]interface{}{"name": p.Name}
+		if p.Namespace != "" {
+			metadata["namespace"] = p.Namespace
+		}
+		anns := make(map[string]interface{})
+		for k, v := range annotations {
+			anns[k] = v
+		}
+		metadata["annotations"] = anns
+		return rf.FromMap(map[string]interface{}{
+			"apiVersion": p.Custom.APIVersion,
+			"kind":       p.Custom.Kind,
+			"metadata":   metadata,
+		}), nil
+	default:
+		return rf.MakeConfigMap(
+			kvLdr, &types.ConfigMapArgs{GeneratorArgs: genArgs})
+	}
+}
+
+// computeObjectHashes returns a JSON object holding
+// a hash of the content of each resource, by its id.
+func computeObjectHashes(m resmap.ResMap) (string, error) {
+	hashes := make(map[string]string)
+	for _, r := range m.Resources() {
+		bs, err := r.MarshalJSON()
+		if err != nil {
+			return "", err
+		}
+		h, err := hasher.Encode(hasher.Hash(string(bs)))
+		if err != nil {
+			return "", err
+		}
+		item := resid.NewResIdWithNamespace(
+			r.GetGvk(), r.GetName(), r.GetNamespace())
+		hashes[item.String()] = h
+	}
+	bs, err := json.Marshal(hashes)
+	return string(bs), err
 }
 
 func makeInventory(m resmap.ResMap) (
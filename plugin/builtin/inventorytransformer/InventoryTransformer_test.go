@@ -4,6 +4,7 @@
 package main_test
 
 import (
+	"strings"
 	"testing"
 
 	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
@@ -52,6 +53,7 @@ metadata:
   annotations:
     kustomize.config.k8s.io/Inventory: '{"current":{"apps_v1_Deployment|~X|deploy1":null,"~G_v1_ConfigMap|~X|cm1":null,"~G_v1_Secret|~X|secret1":null}}'
     kustomize.config.k8s.io/InventoryHash: h44788gt7g
+    kustomize.config.k8s.io/InventoryObjectHashes: '{"apps_v1_Deployment|~X|deploy1":"8472k2t7mg","~G_v1_ConfigMap|~X|cm1":"t5fdd8g7kg","~G_v1_Secret|~X|secret1":"77kgtccf8c"}'
   name: pruneCM
   namespace: default
 `
@@ -106,3 +108,83 @@ metadata:
 
 	th.AssertActualEqualsExpected(rm, content+"---"+inv)
 }
+
+func TestInventoryTransformerSecret(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("InventoryTransformer")
+	defer th.Reset()
+
+	rm := th.LoadAndRunTransformer(`
+apiVersion: builtin
+kind: InventoryTransformer
+metadata:
+  name: pruneSecret
+  namespace: default
+policy: GarbageCollect
+type: Secret
+`, content)
+
+	th.AssertActualEqualsExpected(rm, `
+apiVersion: v1
+kind: Secret
+metadata:
+  annotations:
+    kustomize.config.k8s.io/Inventory: '{"current":{"apps_v1_Deployment|~X|deploy1":null,"~G_v1_ConfigMap|~X|cm1":null,"~G_v1_Secret|~X|secret1":null}}'
+    kustomize.config.k8s.io/InventoryHash: h44788gt7g
+    kustomize.config.k8s.io/InventoryObjectHashes: '{"apps_v1_Deployment|~X|deploy1":"8472k2t7mg","~G_v1_ConfigMap|~X|cm1":"t5fdd8g7kg","~G_v1_Secret|~X|secret1":"77kgtccf8c"}'
+  name: pruneSecret
+  namespace: default
+type: Opaque
+`)
+}
+
+func TestInventoryTransformerCustom(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("InventoryTransformer")
+	defer th.Reset()
+
+	rm := th.LoadAndRunTransformer(`
+apiVersion: builtin
+kind: InventoryTransformer
+metadata:
+  name: myApp
+  namespace: apps
+policy: GarbageCollect
+type: Custom
+custom:
+  apiVersion: example.com/v1
+  kind: Inventory
+`, content)
+
+	th.AssertActualEqualsExpected(rm, `
+apiVersion: example.com/v1
+kind: Inventory
+metadata:
+  annotations:
+    kustomize.config.k8s.io/Inventory: '{"current":{"apps_v1_Deployment|~X|deploy1":null,"~G_v1_ConfigMap|~X|cm1":null,"~G_v1_Secret|~X|secret1":null}}'
+    kustomize.config.k8s.io/InventoryHash: h44788gt7g
+    kustomize.config.k8s.io/InventoryObjectHashes: '{"apps_v1_Deployment|~X|deploy1":"8472k2t7mg","~G_v1_ConfigMap|~X|cm1":"t5fdd8g7kg","~G_v1_Secret|~X|secret1":"77kgtccf8c"}'
+  name: myApp
+  namespace: apps
+`)
+}
+
+func TestInventoryTransformerCustomRequiresKind(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("InventoryTransformer")
+	defer th.Reset()
+
+	err := th.ErrorFromLoadAndRunTransformer(`
+apiVersion: builtin
+kind: InventoryTransformer
+metadata:
+  name: myApp
+type: Custom
+`, content)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if !strings.Contains(err.Error(), "requires an apiVersion and kind") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -112,3 +112,73 @@ metadata:
   name: pomegranate
 `)
 }
+
+func TestLegacyOrderTransformerKindOrder(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("LegacyOrderTransformer")
+	defer th.Reset()
+
+	rm := th.LoadAndRunTransformer(`
+apiVersion: builtin
+kind: LegacyOrderTransformer
+metadata:
+  name: notImportantHere
+first:
+- Secret
+- Deployment
+last:
+- Namespace
+`, `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: apple
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: papaya
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: pear
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: apricot
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: quince
+`)
+
+	th.AssertActualEqualsExpected(rm, `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: quince
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: pear
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: apricot
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: papaya
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: apple
+`)
+}
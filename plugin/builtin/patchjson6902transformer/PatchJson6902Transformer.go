@@ -13,6 +13,7 @@ import (
 	"sigs.k8s.io/kustomize/api/ifc"
 	"sigs.k8s.io/kustomize/api/resid"
 	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kyaml/filtersutil"
 	"sigs.k8s.io/yaml"
@@ -21,6 +22,10 @@ import (
 type plugin struct {
 	ldr          ifc.Loader
 	decodedPatch jsonpatch.Patch
+	// The origins of the patch file and of its
+	// operations, for errors; nil for inline patches.
+	origin    *resource.Origin
+	opOrigins []*resource.Origin
 	Target       types.PatchTarget `json:"target,omitempty" yaml:"target,omitempty"`
 	Path         string            `json:"path,omitempty" yaml:"path,omitempty"`
 	JsonOp       string            `json:"jsonOp,omitempty" yaml:"jsonOp,omitempty"`
@@ -52,6 +57,8 @@ func (p *plugin) Config(
 		if err != nil {
 			return err
 		}
+		p.origin = &resource.Origin{Path: p.Path, Root: p.ldr.Root()}
+		p.opOrigins = resource.ListItemOrigins(rawOp, p.Path, p.ldr.Root())
 		p.JsonOp = string(rawOp)
 		if p.JsonOp == "" {
 			return fmt.Errorf("patch file '%s' empty seems to be empty", p.Path)
@@ -89,22 +96,35 @@ func (p *plugin) Transform(m resmap.ResMap) error {
 	)
 	obj, err := m.GetById(id)
 	if err != nil {
-		return err
+		return p.origin.WrapError(err)
 	}
 	if !p.YAMLSupport {
 		rawObj, err := obj.MarshalJSON()
 		if err != nil {
 			return err
 		}
-		modifiedObj, err := p.decodedPatch.Apply(rawObj)
-		if err != nil {
-			return errors.Wrapf(
-				err, "failed to apply json patch '%s'", p.JsonOp)
+		// The operations are applied one by one,
+		// so that a failure has the line of its own.
+		for i, op := range p.decodedPatch {
+			rawObj, err = jsonpatch.Patch{op}.Apply(rawObj)
+			if err != nil {
+				return p.opOrigin(i).WrapError(errors.Wrapf(
+					err, "failed to apply json patch '%s'", p.JsonOp))
+			}
 		}
-		return obj.UnmarshalJSON(modifiedObj)
+		return obj.UnmarshalJSON(rawObj)
 	} else {
-		return filtersutil.ApplyToJSON(patchjson6902.Filter{
+		return p.origin.WrapError(filtersutil.ApplyToJSON(patchjson6902.Filter{
 			Patch: p.JsonOp,
-		}, obj.Kunstructured)
+		}, obj.Kunstructured))
+	}
+}
+
+// opOrigin returns the origin of the
+// operation of the patch at the given index.
+func (p *plugin) opOrigin(i int) *resource.Origin {
+	if i < len(p.opOrigins) {
+		return p.opOrigins[i]
 	}
+	return p.origin
 }
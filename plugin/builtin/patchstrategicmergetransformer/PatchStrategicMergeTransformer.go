@@ -77,12 +77,12 @@ func (p *plugin) Transform(m resmap.ResMap) error {
 	for _, patch := range patches.Resources() {
 		target, err := m.GetById(patch.OrgId())
 		if err != nil {
-			return err
+			return patch.GetOrigin().WrapError(err)
 		}
 		if !p.YAMLSupport {
 			err = target.Patch(patch.Kunstructured)
 			if err != nil {
-				return err
+				return patch.GetOrigin().WrapError(err)
 			}
 			// remove the resource from resmap
 			// when the patch is to $patch: delete that target
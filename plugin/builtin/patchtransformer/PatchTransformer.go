@@ -5,6 +5,7 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -12,6 +13,7 @@ import (
 	"github.com/pkg/errors"
 	"sigs.k8s.io/kustomize/api/filters/patchjson6902"
 	"sigs.k8s.io/kustomize/api/filters/patchstrategicmerge"
+	"sigs.k8s.io/kustomize/api/resid"
 	"sigs.k8s.io/kustomize/api/resmap"
 	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
@@ -22,19 +24,30 @@ import (
 type plugin struct {
 	loadedPatch  *resource.Resource
 	decodedPatch jsonpatch.Patch
+	mergePatch   []byte
+	mergeId      resid.ResId
+	// The origins of the patch file and of the operations
+	// of a JSON patch in it, for errors; nil for inline patches.
+	origin    *resource.Origin
+	opOrigins []*resource.Origin
 	Path         string          `json:"path,omitempty" yaml:"path,omitempty"`
 	Patch        string          `json:"patch,omitempty" yaml:"patch,omitempty"`
 	Target       *types.Selector `json:"target,omitempty" yaml:"target,omitempty"`
+	Type         types.PatchType `json:"type,omitempty" yaml:"type,omitempty"`
 
 	YAMLSupport bool `json:"yamlSupport,omitempty" yaml:"yamlSupport,omitempty"`
+
+	// Strict makes a patch whose target
+	// matches no resources an error.
+	Strict bool `json:"strict,omitempty" yaml:"strict,omitempty"`
 }
 
 //noinspection GoUnusedGlobalVariable
 var KustomizePlugin plugin
 
 func (p *plugin) Config(
-	h *resmap.PluginHelpers, c []byte) error {
-	err := yaml.Unmarshal(c, p)
+	h *resmap.PluginHelpers, c []byte) (err error) {
+	err = yaml.Unmarshal(c, p)
 	if err != nil {
 		return err
 	}
@@ -54,8 +67,49 @@ func (p *plugin) Config(
 			return loadErr
 		}
 		p.Patch = string(loaded)
+		p.origin = &resource.Origin{Path: p.Path, Root: h.Loader().Root()}
 	}
 
+	switch p.Type {
+	case types.UnspecifiedPatchType:
+		err = p.detectPatch(h)
+	case types.StrategicMergePatchType:
+		p.loadedPatch, err = h.ResmapFactory().RF().FromBytes([]byte(p.Patch))
+		if err != nil {
+			return errors.Wrapf(
+				err, "unable to parse SM patch from [%v]", p.Patch)
+		}
+	case types.Json6902PatchType:
+		p.decodedPatch, err = jsonPatchFromBytes([]byte(p.Patch))
+		if err != nil {
+			return errors.Wrapf(
+				err, "unable to parse JSON patch from [%v]", p.Patch)
+		}
+	case types.JsonMergePatchType:
+		err = p.loadMergePatch(h)
+	default:
+		return fmt.Errorf(
+			"unknown patch type '%s'; expected one of %v",
+			p.Type, types.PatchTypes)
+	}
+	if err != nil || p.origin == nil {
+		return err
+	}
+	if p.loadedPatch != nil {
+		resource.SetFileOrigins(
+			[]*resource.Resource{p.loadedPatch},
+			[]byte(p.Patch), p.Path, p.origin.Root)
+	}
+	if p.decodedPatch != nil {
+		p.opOrigins = resource.ListItemOrigins(
+			[]byte(p.Patch), p.Path, p.origin.Root)
+	}
+	return nil
+}
+
+// detectPatch loads the patch as either an SM or a
+// JSON patch, whichever its content qualifies as.
+func (p *plugin) detectPatch(h *resmap.PluginHelpers) error {
 	patchSM, errSM := h.ResmapFactory().RF().FromBytes([]byte(p.Patch))
 	patchJson, errJson := jsonPatchFromBytes([]byte(p.Patch))
 	if (errSM == nil && errJson == nil) ||
@@ -76,7 +130,38 @@ func (p *plugin) Config(
 	return nil
 }
 
+// loadMergePatch loads the patch as a JSON merge patch.
+// Without a target, the patch applies to the resource it
+// names.  The fields naming a resource are dropped from the
+// patch, so that it doesn't rename the resources it's
+// applied to.
+func (p *plugin) loadMergePatch(h *resmap.PluginHelpers) error {
+	var patch map[string]interface{}
+	err := yaml.Unmarshal([]byte(p.Patch), &patch)
+	if err != nil {
+		return errors.Wrapf(
+			err, "unable to parse JSON merge patch from [%v]", p.Patch)
+	}
+	if patch == nil {
+		return fmt.Errorf("empty JSON merge patch [%v]", p.Patch)
+	}
+	if p.Target == nil {
+		p.mergeId = h.ResmapFactory().RF().FromMap(patch).OrgId()
+	}
+	delete(patch, "apiVersion")
+	delete(patch, "kind")
+	if meta, ok := patch["metadata"].(map[string]interface{}); ok {
+		delete(meta, "name")
+		delete(meta, "namespace")
+	}
+	p.mergePatch, err = json.Marshal(patch)
+	return err
+}
+
 func (p *plugin) Transform(m resmap.ResMap) error {
+	if p.mergePatch != nil {
+		return p.transformJsonMerge(m)
+	}
 	if p.loadedPatch != nil {
 		// The patch was a strategic merge patch
 		return p.transformStrategicMerge(m, p.loadedPatch)
@@ -85,6 +170,29 @@ func (p *plugin) Transform(m resmap.ResMap) error {
 	}
 }
 
+// entry names the patch in errors,
+// by its path if it has one.
+func (p *plugin) entry() string {
+	if p.Path != "" {
+		return p.Path
+	}
+	return p.Patch
+}
+
+// selectTargets returns the resources in the ResMap that
+// match the Target, failing in strict mode if there are none.
+func (p *plugin) selectTargets(m resmap.ResMap) ([]*resource.Resource, error) {
+	resources, err := m.Select(*p.Target)
+	if err != nil {
+		return nil, err
+	}
+	if p.Strict && len(resources) == 0 {
+		return nil, p.origin.WrapError(fmt.Errorf(
+			"target of patch '%s' matched no resources", p.entry()))
+	}
+	return resources, nil
+}
+
 // transformStrategicMerge applies the provided strategic merge patch
 // to all the resources in the ResMap that match either the Target or
 // the identifier of the patch.
@@ -92,12 +200,12 @@ func (p *plugin) transformStrategicMerge(m resmap.ResMap, patch *resource.Resour
 	if p.Target == nil {
 		target, err := m.GetById(patch.OrgId())
 		if err != nil {
-			return err
+			return patch.GetOrigin().WrapError(err)
 		}
 		return p.applySMPatch(target, patch)
 	}
 
-	resources, err := m.Select(*p.Target)
+	resources, err := p.selectTargets(m)
 	if err != nil {
 		return err
 	}
@@ -119,15 +227,15 @@ func (p *plugin) transformStrategicMerge(m resmap.ResMap, patch *resource.Resour
 // use the legacy implementation or the kyaml-based solution.
 func (p *plugin) applySMPatch(resource, patch *resource.Resource) error {
 	if !p.YAMLSupport {
-		return resource.Patch(patch.Kunstructured)
+		return patch.GetOrigin().WrapError(resource.Patch(patch.Kunstructured))
 	} else {
 		node, err := filtersutil.GetRNode(patch)
 		if err != nil {
 			return err
 		}
-		return filtersutil.ApplyToJSON(patchstrategicmerge.Filter{
+		return patch.GetOrigin().WrapError(filtersutil.ApplyToJSON(patchstrategicmerge.Filter{
 			Patch: node,
-		}, resource.Kunstructured)
+		}, resource.Kunstructured))
 	}
 }
 
@@ -138,7 +246,7 @@ func (p *plugin) transformJson6902(m resmap.ResMap, patch jsonpatch.Patch) error
 		return fmt.Errorf("must specify a target for patch %s", p.Patch)
 	}
 
-	resources, err := m.Select(*p.Target)
+	resources, err := p.selectTargets(m)
 	if err != nil {
 		return err
 	}
@@ -160,17 +268,68 @@ func (p *plugin) applyJson6902Patch(resource *resource.Resource, patch jsonpatch
 		if err != nil {
 			return err
 		}
-		modifiedObj, err := patch.Apply(rawObj)
-		if err != nil {
-			return errors.Wrapf(
-				err, "failed to apply json patch '%s'", p.Patch)
+		// The operations are applied one by one,
+		// so that a failure has the line of its own.
+		for i, op := range patch {
+			rawObj, err = jsonpatch.Patch{op}.Apply(rawObj)
+			if err != nil {
+				return p.opOrigin(i).WrapError(errors.Wrapf(
+					err, "failed to apply json patch '%s'", p.Patch))
+			}
 		}
-		return resource.UnmarshalJSON(modifiedObj)
+		return resource.UnmarshalJSON(rawObj)
 	} else {
-		return filtersutil.ApplyToJSON(patchjson6902.Filter{
+		return p.origin.WrapError(filtersutil.ApplyToJSON(patchjson6902.Filter{
 			Patch: p.Patch,
-		}, resource.Kunstructured)
+		}, resource.Kunstructured))
+	}
+}
+
+// opOrigin returns the origin of the operation
+// of the JSON patch at the given index.
+func (p *plugin) opOrigin(i int) *resource.Origin {
+	if i < len(p.opOrigins) {
+		return p.opOrigins[i]
+	}
+	return p.origin
+}
+
+// transformJsonMerge applies the JSON merge patch to
+// all the resources in the ResMap that match either
+// the Target or the identifier of the patch.
+func (p *plugin) transformJsonMerge(m resmap.ResMap) error {
+	if p.Target == nil {
+		target, err := m.GetById(p.mergeId)
+		if err != nil {
+			return p.origin.WrapError(err)
+		}
+		return p.applyJsonMergePatch(target)
+	}
+	resources, err := p.selectTargets(m)
+	if err != nil {
+		return err
+	}
+	for _, res := range resources {
+		err = p.applyJsonMergePatch(res)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyJsonMergePatch applies the JSON merge patch to the given resource.
+func (p *plugin) applyJsonMergePatch(resource *resource.Resource) error {
+	rawObj, err := resource.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	modifiedObj, err := jsonpatch.MergePatch(rawObj, p.mergePatch)
+	if err != nil {
+		return p.origin.WrapError(errors.Wrapf(
+			err, "failed to apply json merge patch '%s'", p.Patch))
 	}
+	return resource.UnmarshalJSON(modifiedObj)
 }
 
 // jsonPatchFromBytes loads a Json 6902 patch from
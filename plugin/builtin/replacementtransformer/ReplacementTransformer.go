@@ -0,0 +1,365 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate pluginator
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+)
+
+// Copy a value from one resource field, or a literal
+// value, into fields of the resources matching a selector.
+type plugin struct {
+	Replacements []types.Replacement `json:"replacements,omitempty" yaml:"replacements,omitempty"`
+}
+
+//noinspection GoUnusedGlobalVariable
+var KustomizePlugin plugin
+
+func (p *plugin) Config(
+	_ *resmap.PluginHelpers, c []byte) (err error) {
+	p.Replacements = nil
+	err = yaml.Unmarshal(c, p)
+	if err != nil {
+		return err
+	}
+	for i, r := range p.Replacements {
+		if r.Source == nil {
+			return fmt.Errorf("replacement %d: source must be specified", i)
+		}
+		if r.Source.ObjRef != nil && r.Source.Value != "" {
+			return fmt.Errorf(
+				"replacement %d: only one of source objref and value is allowed", i)
+		}
+		if r.Source.ObjRef == nil && r.Source.Value == "" {
+			return fmt.Errorf(
+				"replacement %d: one of source objref and value must be specified", i)
+		}
+		if r.Target == nil || r.Target.ObjRef == nil {
+			return fmt.Errorf("replacement %d: target objref must be specified", i)
+		}
+		if len(r.Target.FieldRefs) == 0 {
+			return fmt.Errorf("replacement %d: target fieldrefs must be specified", i)
+		}
+	}
+	return nil
+}
+
+func (p *plugin) Transform(m resmap.ResMap) error {
+	for _, r := range p.Replacements {
+		var value interface{} = r.Source.Value
+		if r.Source.ObjRef != nil {
+			var err error
+			value, err = getSourceValue(m, r.Source)
+			if err != nil {
+				return err
+			}
+		}
+		err := setTargetValues(m, r.Target, value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func getSourceValue(m resmap.ResMap, source *types.ReplSource) (interface{}, error) {
+	resources, err := m.Select(types.Selector{
+		Gvk:       source.ObjRef.Gvk,
+		Name:      source.ObjRef.Name,
+		Namespace: source.ObjRef.Namespace,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resources) == 0 {
+		return nil, fmt.Errorf(
+			"replacement source %s matches no resource", describeTarget(source.ObjRef))
+	}
+	if len(resources) > 1 {
+		return nil, fmt.Errorf(
+			"replacement source %s matches more than one resource", describeTarget(source.ObjRef))
+	}
+	fieldRef := source.FieldRef
+	if fieldRef == "" {
+		fieldRef = "metadata.name"
+	}
+	path, err := parseFieldPath(fieldRef)
+	if err != nil {
+		return nil, err
+	}
+	value, found := getValueAt(resources[0].Map(), path)
+	if !found {
+		return nil, fmt.Errorf(
+			"replacement source field '%s' not found in %s",
+			fieldRef, resources[0].CurId())
+	}
+	return value, nil
+}
+
+func setTargetValues(
+	m resmap.ResMap, target *types.ReplTarget, value interface{}) error {
+	resources, err := m.Select(*target.ObjRef)
+	if err != nil {
+		return err
+	}
+	if len(resources) == 0 {
+		return fmt.Errorf(
+			"replacement target %s matches no resource", describeSelector(target.ObjRef))
+	}
+	for _, fieldRef := range target.FieldRefs {
+		path, err := parseFieldPath(fieldRef)
+		if err != nil {
+			return err
+		}
+		for _, res := range resources {
+			err = setTargetValue(res, path, fieldRef, value)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func setTargetValue(
+	res *resource.Resource, path []pathSegment,
+	fieldRef string, value interface{}) error {
+	found, err := setValueAt(res.Map(), path, value)
+	if err != nil {
+		return fmt.Errorf(
+			"replacement target field '%s' in %s: %v",
+			fieldRef, res.CurId(), err)
+	}
+	if !found {
+		return fmt.Errorf(
+			"replacement target field '%s' not found in %s",
+			fieldRef, res.CurId())
+	}
+	return nil
+}
+
+func describeTarget(t *types.Target) string {
+	return describeSelector(&types.Selector{
+		Gvk: t.Gvk, Name: t.Name, Namespace: t.Namespace})
+}
+
+func describeSelector(s *types.Selector) string {
+	var parts []string
+	if s.Group != "" {
+		parts = append(parts, "group="+s.Group)
+	}
+	if s.Version != "" {
+		parts = append(parts, "version="+s.Version)
+	}
+	if s.Kind != "" {
+		parts = append(parts, "kind="+s.Kind)
+	}
+	if s.Namespace != "" {
+		parts = append(parts, "namespace="+s.Namespace)
+	}
+	if s.Name != "" {
+		parts = append(parts, "name="+s.Name)
+	}
+	if s.LabelSelector != "" {
+		parts = append(parts, "labelSelector="+s.LabelSelector)
+	}
+	if s.AnnotationSelector != "" {
+		parts = append(parts, "annotationSelector="+s.AnnotationSelector)
+	}
+	return "{" + strings.Join(parts, " ") + "}"
+}
+
+// pathSegment is one step in a field path such as
+// spec.template.spec.containers[name=app].image
+// A segment names a map field, or a list index, and may
+// select the elements of a list field having a field
+// with a given value, as in containers[name=app].
+type pathSegment struct {
+	field    string
+	hasMatch bool
+	matchKey string
+	matchVal string
+}
+
+func parseFieldPath(p string) ([]pathSegment, error) {
+	var result []pathSegment
+	for _, s := range splitFieldPath(strings.TrimPrefix(p, ".")) {
+		seg := pathSegment{field: s}
+		if i := strings.Index(s, "["); i >= 0 {
+			if !strings.HasSuffix(s, "]") {
+				return nil, fmt.Errorf("bad field path '%s': unterminated '['", p)
+			}
+			kv := strings.SplitN(s[i+1:len(s)-1], "=", 2)
+			if len(kv) != 2 || kv[0] == "" {
+				return nil, fmt.Errorf(
+					"bad field path '%s': expected [key=value] in '%s'", p, s)
+			}
+			seg = pathSegment{
+				field: s[:i], hasMatch: true, matchKey: kv[0], matchVal: kv[1]}
+		}
+		if seg.field == "" {
+			return nil, fmt.Errorf("bad field path '%s': empty field name", p)
+		}
+		result = append(result, seg)
+	}
+	return result, nil
+}
+
+// splitFieldPath splits a path on the dots
+// that aren't between square brackets.
+func splitFieldPath(p string) []string {
+	var result []string
+	depth, start := 0, 0
+	for i, c := range p {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case '.':
+			if depth == 0 {
+				result = append(result, p[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(result, p[start:])
+}
+
+// childAt returns the value of the segment's field in node,
+// which may be a map, or, if the field is an index, a list.
+func childAt(node interface{}, seg pathSegment) (interface{}, bool) {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		v, ok := n[seg.field]
+		return v, ok
+	case []interface{}:
+		i, err := strconv.Atoi(seg.field)
+		if err != nil || i < 0 || i >= len(n) {
+			return nil, false
+		}
+		return n[i], true
+	}
+	return nil, false
+}
+
+// selectedElements returns the indices of the elements of
+// the given list that the segment selects.
+func selectedElements(list []interface{}, seg pathSegment) []int {
+	var result []int
+	for i, e := range list {
+		m, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if v, ok := m[seg.matchKey]; ok && fmt.Sprint(v) == seg.matchVal {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+// getValueAt returns the value at the given path in node.
+// Where a segment selects several list elements,
+// the first is used.
+func getValueAt(node interface{}, path []pathSegment) (interface{}, bool) {
+	if len(path) == 0 {
+		return node, true
+	}
+	v, ok := childAt(node, path[0])
+	if !ok {
+		return nil, false
+	}
+	if path[0].hasMatch {
+		list, ok := v.([]interface{})
+		if !ok {
+			return nil, false
+		}
+		indices := selectedElements(list, path[0])
+		if len(indices) == 0 {
+			return nil, false
+		}
+		v = list[indices[0]]
+	}
+	return getValueAt(v, path[1:])
+}
+
+// setValueAt sets the value at the given path in node, for
+// every list element selected along the way.  It returns
+// false if the path leads nowhere.
+func setValueAt(
+	node interface{}, path []pathSegment, value interface{}) (bool, error) {
+	seg := path[0]
+	v, ok := childAt(node, seg)
+	if !ok {
+		return false, nil
+	}
+	if !seg.hasMatch {
+		if len(path) == 1 {
+			setChildAt(node, seg, copyJsonValue(value))
+			return true, nil
+		}
+		return setValueAt(v, path[1:], value)
+	}
+	list, ok := v.([]interface{})
+	if !ok {
+		return false, fmt.Errorf(
+			"'%s' is not a list, so can't select [%s=%s]",
+			seg.field, seg.matchKey, seg.matchVal)
+	}
+	indices := selectedElements(list, seg)
+	if len(indices) == 0 {
+		return false, nil
+	}
+	for _, i := range indices {
+		if len(path) == 1 {
+			list[i] = copyJsonValue(value)
+			continue
+		}
+		found, err := setValueAt(list[i], path[1:], value)
+		if err != nil || !found {
+			return found, err
+		}
+	}
+	return true, nil
+}
+
+func setChildAt(node interface{}, seg pathSegment, value interface{}) {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		n[seg.field] = value
+	case []interface{}:
+		i, _ := strconv.Atoi(seg.field)
+		n[i] = value
+	}
+}
+
+// copyJsonValue copies maps and lists, so that a value
+// placed in several fields isn't shared by them.
+func copyJsonValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, e := range t {
+			m[k] = copyJsonValue(e)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(t))
+		for i, e := range t {
+			l[i] = copyJsonValue(e)
+		}
+		return l
+	default:
+		return v
+	}
+}
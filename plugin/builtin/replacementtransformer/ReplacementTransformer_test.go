@@ -0,0 +1,323 @@
+// Copyright 2019 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main_test
+
+import (
+	"strings"
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+const replacementResources = `
+apiVersion: v1
+kind: Service
+metadata:
+  name: backend
+  labels:
+    tier: backend
+spec:
+  ports:
+  - name: http
+    port: 8080
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: frontend
+  labels:
+    tier: frontend
+  annotations:
+    config.kubernetes.io/replace: "yes"
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app:1
+        env:
+        - name: BACKEND_HOST
+          value: placeholder
+        - name: BACKEND_PORT
+          value: placeholder
+      - name: sidecar
+        image: sidecar:1
+`
+
+func TestReplacementTransformerFromField(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("ReplacementTransformer")
+	defer th.Reset()
+
+	th.RunTransformerAndCheckResult(`
+apiVersion: builtin
+kind: ReplacementTransformer
+metadata:
+  name: notImportantHere
+replacements:
+- source:
+    objref:
+      kind: Service
+      name: backend
+  target:
+    objref:
+      kind: Deployment
+      labelSelector: tier=frontend
+    fieldrefs:
+    - spec.template.spec.containers[name=app].env[name=BACKEND_HOST].value
+- source:
+    objref:
+      kind: Service
+      name: backend
+    fieldref: spec.ports[name=http].port
+  target:
+    objref:
+      annotationSelector: config.kubernetes.io/replace=yes
+    fieldrefs:
+    - spec.template.spec.containers.0.env.1.value
+`, replacementResources, `
+apiVersion: v1
+kind: Service
+metadata:
+  labels:
+    tier: backend
+  name: backend
+spec:
+  ports:
+  - name: http
+    port: 8080
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  annotations:
+    config.kubernetes.io/replace: "yes"
+  labels:
+    tier: frontend
+  name: frontend
+spec:
+  template:
+    spec:
+      containers:
+      - env:
+        - name: BACKEND_HOST
+          value: backend
+        - name: BACKEND_PORT
+          value: 8080
+        image: app:1
+        name: app
+      - image: sidecar:1
+        name: sidecar
+`)
+}
+
+func TestReplacementTransformerFromValue(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("ReplacementTransformer")
+	defer th.Reset()
+
+	th.RunTransformerAndCheckResult(`
+apiVersion: builtin
+kind: ReplacementTransformer
+metadata:
+  name: notImportantHere
+replacements:
+- source:
+    value: registry.example.com/app:2
+  target:
+    objref:
+      group: apps
+      kind: Deployment
+    fieldrefs:
+    - spec.template.spec.containers[name=app].image
+`, replacementResources, `
+apiVersion: v1
+kind: Service
+metadata:
+  labels:
+    tier: backend
+  name: backend
+spec:
+  ports:
+  - name: http
+    port: 8080
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  annotations:
+    config.kubernetes.io/replace: "yes"
+  labels:
+    tier: frontend
+  name: frontend
+spec:
+  template:
+    spec:
+      containers:
+      - env:
+        - name: BACKEND_HOST
+          value: placeholder
+        - name: BACKEND_PORT
+          value: placeholder
+        image: registry.example.com/app:2
+        name: app
+      - image: sidecar:1
+        name: sidecar
+`)
+}
+
+func TestReplacementTransformerErrors(t *testing.T) {
+	testCases := map[string]struct {
+		config   string
+		expected string
+	}{
+		"missingSourceField": {
+			config: `
+replacements:
+- source:
+    objref:
+      kind: Service
+      name: backend
+    fieldref: spec.clusterIP
+  target:
+    objref:
+      kind: Deployment
+    fieldrefs:
+    - spec.template.spec.containers[name=app].image
+`,
+			expected: "replacement source field 'spec.clusterIP' not found in " +
+				"~G_v1_Service|~X|backend",
+		},
+		"missingTargetField": {
+			config: `
+replacements:
+- source:
+    value: x
+  target:
+    objref:
+      kind: Deployment
+    fieldrefs:
+    - spec.template.spec.containers[name=app].command
+`,
+			expected: "replacement target field " +
+				"'spec.template.spec.containers[name=app].command' " +
+				"not found in apps_v1_Deployment|~X|frontend",
+		},
+		"noSuchListElement": {
+			config: `
+replacements:
+- source:
+    value: x
+  target:
+    objref:
+      kind: Deployment
+    fieldrefs:
+    - spec.template.spec.containers[name=db].image
+`,
+			expected: "replacement target field " +
+				"'spec.template.spec.containers[name=db].image' " +
+				"not found in apps_v1_Deployment|~X|frontend",
+		},
+		"noSourceResource": {
+			config: `
+replacements:
+- source:
+    objref:
+      kind: Service
+      name: database
+  target:
+    objref:
+      kind: Deployment
+    fieldrefs:
+    - metadata.name
+`,
+			expected: "replacement source {kind=Service name=database} " +
+				"matches no resource",
+		},
+		"noTargetResource": {
+			config: `
+replacements:
+- source:
+    value: x
+  target:
+    objref:
+      kind: Deployment
+      labelSelector: tier=database
+    fieldrefs:
+    - metadata.name
+`,
+			expected: "replacement target {kind=Deployment " +
+				"labelSelector=tier=database} matches no resource",
+		},
+		"notAList": {
+			config: `
+replacements:
+- source:
+    value: x
+  target:
+    objref:
+      kind: Deployment
+    fieldrefs:
+    - metadata[name=frontend].name
+`,
+			expected: "replacement target field 'metadata[name=frontend].name' " +
+				"in apps_v1_Deployment|~X|frontend: " +
+				"'metadata' is not a list, so can't select [name=frontend]",
+		},
+		"badPath": {
+			config: `
+replacements:
+- source:
+    value: x
+  target:
+    objref:
+      kind: Deployment
+    fieldrefs:
+    - spec.template.spec.containers[name].image
+`,
+			expected: "bad field path 'spec.template.spec.containers[name].image': " +
+				"expected [key=value] in 'containers[name]'",
+		},
+		"valueAndObjRef": {
+			config: `
+replacements:
+- source:
+    value: x
+    objref:
+      kind: Service
+  target:
+    objref:
+      kind: Deployment
+    fieldrefs:
+    - metadata.name
+`,
+			expected: "replacement 0: only one of source objref and value is allowed",
+		},
+		"noTarget": {
+			config: `
+replacements:
+- source:
+    value: x
+`,
+			expected: "replacement 0: target objref must be specified",
+		},
+	}
+	for n, tc := range testCases {
+		th := kusttest_test.MakeEnhancedHarness(t).
+			PrepBuiltin("ReplacementTransformer")
+		err := th.ErrorFromLoadAndRunTransformer(`
+apiVersion: builtin
+kind: ReplacementTransformer
+metadata:
+  name: notImportantHere
+`+tc.config, replacementResources)
+		th.Reset()
+		if err == nil {
+			t.Fatalf("%s: expected an error", n)
+		}
+		if !strings.Contains(err.Error(), tc.expected) {
+			t.Fatalf("%s: unexpected error: %v", n, err)
+		}
+	}
+}
@@ -20,6 +20,12 @@ import (
 type plugin struct {
 	Replica    types.Replica     `json:"replica,omitempty" yaml:"replica,omitempty"`
 	FieldSpecs []types.FieldSpec `json:"fieldSpecs,omitempty" yaml:"fieldSpecs,omitempty"`
+
+	// Strict makes a replica that
+	// sets no field an error.
+	Strict bool `json:"strict,omitempty" yaml:"strict,omitempty"`
+
+	setField bool
 }
 
 //noinspection GoUnusedGlobalVariable
@@ -29,11 +35,13 @@ func (p *plugin) Config(
 	_ *resmap.PluginHelpers, c []byte) (err error) {
 	p.Replica = types.Replica{}
 	p.FieldSpecs = nil
+	p.Strict = false
 	return yaml.Unmarshal(c, p)
 }
 
 func (p *plugin) Transform(m resmap.ResMap) error {
 	found := false
+	p.setField = false
 	for i, replicaSpec := range p.FieldSpecs {
 		matcher := p.createMatcher(i)
 		matchOriginal := m.GetMatchingResourcesByOriginalId(matcher)
@@ -58,6 +66,10 @@ func (p *plugin) Transform(m resmap.ResMap) error {
 		return fmt.Errorf("resource with name %s does not match a config with the following GVK %v",
 			p.Replica.Name, gvks)
 	}
+	if p.Strict && !p.setField {
+		return fmt.Errorf(
+			"replicas of resource with name %s matched no fields", p.Replica.Name)
+	}
 
 	return nil
 }
@@ -84,5 +96,6 @@ func (p *plugin) addReplicas(in interface{}) (interface{}, error) {
 	default:
 		return nil, fmt.Errorf("%#v is expected to be %T", in, m)
 	}
+	p.setField = true
 	return p.Replica.Count, nil
 }
@@ -4,6 +4,7 @@
 package main_test
 
 import (
+	"fmt"
 	"testing"
 
 	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
@@ -222,3 +223,43 @@ spec:
 		t.Fatalf("Unexpected error: %v", err)
 	}
 }
+
+func TestStrictNoFields(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("ReplicaCountTransformer")
+	defer th.Reset()
+
+	config := `
+apiVersion: builtin
+kind: ReplicaCountTransformer
+metadata:
+  name: notImportantHere
+replica:
+  name: dep
+  count: 3
+fieldSpecs:
+- path: spec/replicas
+  kind: Deployment
+strict: %v
+`
+	resources := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: dep
+`
+	err := th.ErrorFromLoadAndRunTransformer(
+		fmt.Sprintf(config, false), resources)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = th.ErrorFromLoadAndRunTransformer(
+		fmt.Sprintf(config, true), resources)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if err.Error() !=
+		"replicas of resource with name dep matched no fields" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}